@@ -75,7 +75,7 @@ func selectAWSProfile(uiConfig *UIConfig) (string, error) {
 	}
 
 	// Display profile selection title
-	printStyled("🔐 AWS Profile Selection", "12", true)
+	printStyled("🔐 AWS Profile Selection", uiConfig.Colors.HeaderColor, true)
 	fmt.Println()
 
 	var chosen string
@@ -245,7 +245,7 @@ func selectAWSRegion(uiConfig *UIConfig) (string, error) {
 	}
 
 	// Display region selection title
-	printStyled("🌍 AWS Region Selection", "12", true)
+	printStyled("🌍 AWS Region Selection", uiConfig.Colors.HeaderColor, true)
 	fmt.Println()
 
 	var chosen string
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*
+Columns Mode
+
+Entries with fields (JSON, logfmt, access logs...) can be shown as aligned
+single-line columns of chosen field paths instead of their formatted message:
+
+	           level  msg                 http.status
+	[12:00:00] info   request served      200
+
+Paths walk nested objects with dots ("http.status"); a key that itself
+contains dots is matched whole first. Entries without any of the columns keep
+their normal line. C toggles the mode; V picks the columns from the fields of
+the cursor line. The choice is saved per log group in the state file (see
+state.go); the "columns" setting gives a default.
+*/
+
+// maxColumnWidth caps the width of one column
+const maxColumnWidth = 40
+
+// columnGap separates columns
+const columnGap = "  "
+
+// fieldPath looks up a dotted path in a field map
+func fieldPath(fields map[string]interface{}, path string) (interface{}, bool) {
+	if value, ok := fields[path]; ok {
+		return value, true
+	}
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		return nil, false
+	}
+	child, ok := fields[head].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return fieldPath(child, rest)
+}
+
+// formatFieldValue renders a field value on one line
+func formatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// fieldPaths lists the dotted paths of every leaf value, sorted
+func fieldPaths(fields map[string]interface{}) []string {
+	var paths []string
+	var walk func(prefix string, fields map[string]interface{})
+	walk = func(prefix string, fields map[string]interface{}) {
+		for key, value := range fields {
+			if child, ok := value.(map[string]interface{}); ok && len(child) > 0 {
+				walk(prefix+key+".", child)
+				continue
+			}
+			paths = append(paths, prefix+key)
+		}
+	}
+	walk("", fields)
+	sort.Strings(paths)
+	return paths
+}
+
+// columnValues returns the column values of an entry, or nil when it has none of them
+func columnValues(entry *logEntry, columns []string) []string {
+	if len(entry.Fields) == 0 {
+		return nil
+	}
+	values := make([]string, len(columns))
+	found := false
+	for i, path := range columns {
+		if value, ok := fieldPath(entry.Fields, path); ok {
+			values[i] = strings.ReplaceAll(formatFieldValue(value), "\n", " ")
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return values
+}
+
+// columnTable is the column layout of the rows on screen
+type columnTable struct {
+	values map[int][]string // Column values by store index
+	widths []int
+}
+
+// layoutColumns measures the columns of the given rows
+func layoutColumns(logs []logEntry, rows []int, columns []string) columnTable {
+	table := columnTable{values: make(map[int][]string), widths: make([]int, len(columns))}
+	for i, column := range columns {
+		table.widths[i] = lipgloss.Width(column)
+	}
+	for _, index := range rows {
+		values := columnValues(&logs[index], columns)
+		if values == nil {
+			continue
+		}
+		table.values[index] = values
+		for i, value := range values {
+			table.widths[i] = min(maxColumnWidth, max(table.widths[i], lipgloss.Width(value)))
+		}
+	}
+	return table
+}
+
+// padColumns joins cells padded to the column widths, truncating long ones
+func (t columnTable) padColumns(cells []string) string {
+	var b strings.Builder
+	for i, cell := range cells {
+		if i > 0 {
+			b.WriteString(columnGap)
+		}
+		runes := []rune(cell)
+		if len(runes) > t.widths[i] {
+			cell = string(runes[:t.widths[i]-1]) + "…"
+		}
+		if i < len(cells)-1 {
+			cell += strings.Repeat(" ", max(0, t.widths[i]-lipgloss.Width(cell)))
+		}
+		b.WriteString(cell)
+	}
+	return b.String()
+}
+
+// row renders an entry as columns after its timestamp, or returns false when it has none of them
+func (t columnTable) row(entry *logEntry, index int, config *UIConfig) (string, bool) {
+	values, ok := t.values[index]
+	if !ok {
+		return "", false
+	}
+	return timestampPrefix(entry, config, time.Now()) + " " + t.padColumns(values), true
+}
+
+// header renders the column names aligned with rows whose timestamp prefix is stampWidth wide
+func (t columnTable) header(columns []string, stampWidth int) string {
+	return strings.Repeat(" ", stampWidth+1) + t.padColumns(columns)
+}
+
+// columnPicker is the overlay that chooses columns from the cursor line's fields
+type columnPicker struct {
+	paths  []string
+	cursor int
+}
+
+// openColumnPicker lists the field paths of the cursor line, chosen columns first
+func (m *logModel) openColumnPicker() {
+	logs := m.safeLogs()
+	if m.cursor < 0 || m.cursor >= len(logs) || len(logs[m.cursor].Fields) == 0 {
+		m.formatStatusMsg = "No fields on this line to use as columns"
+		return
+	}
+
+	paths := append([]string(nil), m.columns...)
+	for _, path := range fieldPaths(logs[m.cursor].Fields) {
+		if !containsString(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	m.picker = &columnPicker{paths: paths}
+	m.followMode = false
+}
+
+// handlePickerKey moves through the picker, toggles a column or closes it
+func (m *logModel) handlePickerKey(action string) tea.Cmd {
+	p := m.picker
+	switch action {
+	case actionUp:
+		p.cursor = max(0, p.cursor-1)
+	case actionDown:
+		p.cursor = min(len(p.paths)-1, p.cursor+1)
+	case actionDetail:
+		m.toggleColumn(p.paths[p.cursor])
+	case actionPickColumns, actionClearSearch, actionBack:
+		m.picker = nil
+		return m.saveColumns()
+	}
+	return nil
+}
+
+// toggleColumn adds or removes a column; adding one turns columns mode on
+func (m *logModel) toggleColumn(path string) {
+	for i, column := range m.columns {
+		if column == path {
+			m.columns = append(m.columns[:i:i], m.columns[i+1:]...)
+			return
+		}
+	}
+	m.columns = append(m.columns, path)
+	m.columnsMode = true
+}
+
+// toggleColumnsMode switches between formatted lines and columns
+func (m *logModel) toggleColumnsMode() tea.Cmd {
+	if len(m.columns) == 0 {
+		m.formatStatusMsg = fmt.Sprintf("No columns chosen (press %s on a line with fields)",
+			m.config.Keys.hint(keyContextViewer, actionPickColumns))
+		return clearFormatStatusCmd()
+	}
+	m.columnsMode = !m.columnsMode
+	if m.columnsMode {
+		m.formatStatusMsg = "Columns: " + strings.Join(m.columns, ", ")
+	} else {
+		m.formatStatusMsg = "Columns off"
+	}
+	return clearFormatStatusCmd()
+}
+
+// saveColumns persists the columns of the log group in the background
+func (m *logModel) saveColumns() tea.Cmd {
+	path, group := m.config.StatePath, m.logGroup
+	columns := append([]string(nil), m.columns...)
+	return func() tea.Msg {
+		err := updateViewState(path, func(state *viewState) {
+			if state.Columns == nil {
+				state.Columns = make(map[string][]string)
+			}
+			if len(columns) == 0 {
+				delete(state.Columns, group)
+			} else {
+				state.Columns[group] = columns
+			}
+		})
+		if err != nil {
+			return statusMsg(fmt.Sprintf("Failed to save columns: %v", err))
+		}
+		return nil
+	}
+}
+
+// initialColumns returns the saved columns of a log group, or the configured default
+func initialColumns(config *UIConfig, logGroup string) []string {
+	state, _ := loadViewState(config.StatePath) // A broken state file is ignored
+	if columns := state.Columns[logGroup]; len(columns) > 0 {
+		return columns
+	}
+	return config.Columns
+}
+
+// renderPicker renders the column picker overlay
+func (m *logModel) renderPicker() string {
+	p := m.picker
+	height := max(1, m.height-uiReservedHeight-4)
+	start := max(0, min(p.cursor-height/2, len(p.paths)-height))
+	end := min(len(p.paths), start+height)
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		mark := "[ ]"
+		if n := indexOfString(m.columns, p.paths[i]); n >= 0 {
+			mark = fmt.Sprintf("[%d]", n+1)
+		}
+		line := mark + " " + p.paths[i]
+		if i == p.cursor {
+			line = m.config.CursorStyle().Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	keys := m.config.Keys
+	footer := fmt.Sprintf("%s toggle column · %s or %s done",
+		keys.hint(keyContextViewer, actionDetail), keys.hint(keyContextViewer, actionPickColumns),
+		keys.hint(keyContextViewer, actionClearSearch))
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(m.config.Colors.BorderColor)).
+		Padding(0, 1).
+		Render(m.config.HeaderStyle().Render("Columns") + "\n" + b.String() + m.config.MutedStyle().Render(footer))
+}
+
+// indexOfString returns the position of s in list, or -1
+func indexOfString(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	return indexOfString(list, s) >= 0
+}
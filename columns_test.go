@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestColumns(t *testing.T) {
+	t.Run("FieldPath", func(t *testing.T) {
+		fields := map[string]interface{}{
+			"level":    "info",
+			"http":     map[string]interface{}{"status": 200.0, "headers": map[string]interface{}{"host": "a"}},
+			"req.id":   "r-1",
+			"elements": []interface{}{1.0, "b"},
+		}
+		tests := map[string]string{
+			"level":             "info",
+			"http.status":       "200",
+			"http.headers.host": "a",
+			"req.id":            "r-1",
+			"elements":          `[1,"b"]`,
+		}
+		for path, want := range tests {
+			value, ok := fieldPath(fields, path)
+			assertBoolEqual(t, ok, true, path)
+			assertStringEqual(t, formatFieldValue(value), want)
+		}
+		_, ok := fieldPath(fields, "http.missing")
+		assertBoolEqual(t, ok, false, "missing path")
+
+		assertStringEqual(t, strings.Join(fieldPaths(fields), " "), "elements http.headers.host http.status level req.id")
+	})
+
+	t.Run("AlignedRows", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+		logs := []logEntry{
+			makeLogEntry(testTimestamp, `{"level":"info","msg":"served","http":{"status":200}}`, config),
+			makeLogEntry(testTimestamp, `{"level":"error","msg":"failed badly","http":{"status":503}}`, config),
+			makeLogEntry(testTimestamp, "plain text", config),
+		}
+		columns := []string{"level", "msg", "http.status"}
+
+		// Act
+		table := layoutColumns(logs, []int{0, 1, 2}, columns)
+
+		// Assert
+		first, _ := table.row(&logs[0], 0, config)
+		second, _ := table.row(&logs[1], 1, config)
+		assertStringEqual(t, first, "[14:30:00] info   served        200")
+		assertStringEqual(t, second, "[14:30:00] error  failed badly  503")
+		assertStringEqual(t, table.header(columns, len("[14:30:00]")), "           level  msg           http.status")
+		_, ok := table.row(&logs[2], 2, config)
+		assertBoolEqual(t, ok, false, "entry without fields")
+	})
+
+	t.Run("StateRoundTrip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cwlogs", "state.json")
+		config := createTestConfig()
+		config.StatePath = path
+		config.Columns = []string{"msg"}
+
+		assertStringEqual(t, strings.Join(initialColumns(config, "/app"), ","), "msg")
+
+		err := updateViewState(path, func(s *viewState) { s.Columns = map[string][]string{"/app": {"level", "msg"}} })
+		assertNoError(t, err)
+		assertStringEqual(t, strings.Join(initialColumns(config, "/app"), ","), "level,msg")
+		assertStringEqual(t, strings.Join(initialColumns(config, "/other"), ","), "msg")
+	})
+
+	t.Run("ConcurrentStateUpdates", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "state.json")
+		var wg sync.WaitGroup
+
+		// Act - column and rule saves running at the same time
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				assertNoError(t, updateViewState(path, func(s *viewState) {
+					if s.Columns == nil {
+						s.Columns = make(map[string][]string)
+					}
+					s.Columns[fmt.Sprintf("/group/%d", i)] = []string{"msg"}
+				}))
+			}()
+			go func() {
+				defer wg.Done()
+				assertNoError(t, updateViewState(path, func(s *viewState) {
+					s.Highlights = append(s.Highlights, HighlightRule{Pattern: fmt.Sprintf("rule%d", i)})
+				}))
+			}()
+		}
+		wg.Wait()
+
+		// Assert - no update is lost
+		state, err := loadViewState(path)
+		assertNoError(t, err)
+		assertIntEqual(t, len(state.Columns), 20, "saved column sets")
+		assertIntEqual(t, len(state.Highlights), 20, "saved rules")
+	})
+}
+
+func TestColumnPickerInViewer(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	model.config.StatePath = filepath.Join(t.TempDir(), "state.json")
+	model.store.Append(makeLogEntry(testTimestamp, `{"level":"info","msg":"served"}`, model.config))
+	model.followMode = false
+	simulateWindowResize(model, 160, 40)
+
+	// Act - pick "level" (first path) and "msg" (second), then close
+	simulateKeyPress(model, "V")
+	simulateKeyPress(model, "enter")
+	simulateKeyPress(model, "j")
+	simulateKeyPress(model, "enter")
+	_, cmd := simulateKeyPress(model, "V")
+
+	// Assert
+	assertStringEqual(t, strings.Join(model.columns, ","), "level,msg")
+	assertBoolEqual(t, model.columnsMode, true, "columns mode on")
+	if model.picker != nil {
+		t.Fatal("picker should be closed")
+	}
+	assertStringContains(t, model.View(), "[14:30:00] info   served")
+
+	if cmd == nil {
+		t.Fatal("closing the picker should save the columns")
+	}
+	cmd()
+	state, err := loadViewState(model.config.StatePath)
+	assertNoError(t, err)
+	assertStringEqual(t, strings.Join(state.Columns["/app"], ","), "level,msg")
+
+	simulateKeyPress(model, "C")
+	assertBoolEqual(t, model.columnsMode, false, "columns mode off")
+}
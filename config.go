@@ -1,6 +1,11 @@
 package main
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 /*
 CloudWatch Logs Viewer Configuration
@@ -18,7 +23,7 @@ DISPLAY PREFERENCES:
 - Disable PrettyPrintJSON if you prefer raw JSON (faster rendering)
 - Disable ParseAccessLogs if you don't have web server logs
 - Adjust ProfilePageSize based on how many AWS accounts you have
-- Modify Colors section for different terminal themes
+- Pick a theme (dark, light, high-contrast, monochrome) or define one in the config file (see theme.go)
 
 TERMINAL COLOR CODES:
 0=black, 1=red, 2=green, 3=yellow, 4=blue, 5=magenta, 6=cyan, 7=white
@@ -46,32 +51,103 @@ type UIConfig struct {
 	APITimeout      int   // AWS API call timeout (seconds) - increase for slow connections
 
 	// ========== LOG FORMATTING SETTINGS ==========
-	PrettyPrintJSON bool   // Auto-detect and pretty-print JSON in log messages
-	JSONIndent      string // Indentation for JSON formatting (e.g., "  " for 2 spaces, "\t" for tabs)
-	ParseAccessLogs bool   // Auto-detect and colorize Apache/Nginx access logs
-	ColorizeFields  bool   // Apply color coding to parsed log fields (status codes, methods, etc.)
+	PrettyPrintJSON bool            // Auto-detect and pretty-print JSON in log messages
+	JSONIndent      string          // Indentation for JSON formatting (e.g., "  " for 2 spaces, "\t" for tabs)
+	ParseAccessLogs bool            // Auto-detect and colorize Apache/Nginx access logs
+	ColorizeFields  bool            // Apply color coding to parsed log fields (status codes, methods, etc.)
+	MinLevel        logLevel        // Initial minimum level shown (levelNone shows all; L cycles it)
+	Columns         []string        // Default field paths of columns mode (see columns.go)
+	Highlights      []HighlightRule // Highlight rules from the config file (see highlightrules.go)
+
+	// ========== TIMESTAMPS ==========
+	TimeFormat    string         // "local", "utc", "zone", "datetime", "rfc3339" or "relative" (see timestamps.go)
+	TimeZone      *time.Location // Zone of the "zone" format; nil when none is configured
+	ShowIngestion bool           // Show the ingestion time and delay after the event time
+
+	// ========== PARSERS ==========
+	ParserSettings ParserSettings // Parser order and enable flags (see parser_registry.go)
+	parsers        []logParser    // Chain built from ParserSettings; nil means the built-in order
+
+	// ========== REDACTION ==========
+	Redaction     RedactionSettings // Masked keys, patterns and detectors (see redaction.go)
+	RevealSecrets bool              // Show the original values this session (U)
+	redactor      *redactor         // Built from Redaction; nil means the defaults
 
 	// ========== COLOR SCHEME ==========
-	Colors ColorScheme
+	Theme   string                 // Name of the active theme (see theme.go)
+	Colors  ColorScheme            // Colors of the active theme
+	Themes  map[string]ColorScheme // Custom themes from the config file, by name
+	NoColor bool                   // NO_COLOR or a dumb terminal: stay monochrome
+
+	// ========== KEY BINDINGS ==========
+	Keys *keyMap // Active key bindings (see keymap.go)
+
+	// ========== VIEW STATE ==========
+	StatePath string // File where viewer choices are saved (see state.go); "" disables saving
+
+	// ========== PER-LOG-GROUP PROFILES ==========
+	Profiles      []SettingsProfile // Overrides from the config file, matched by log group name
+	ActiveProfile string            // Names of the profiles merged by ForLogGroup (shown in header)
 }
 
+// ColorScheme is a theme: every color the UI renders is looked up here.
+// An empty color means "terminal default", which the monochrome theme relies on.
 type ColorScheme struct {
 	// ========== HEADER & UI COLORS ==========
-	HeaderColor string // Color for the main header showing log group name
+	HeaderColor      string `json:"headerColor"`      // Color for the main header showing log group name
+	MutedColor       string `json:"mutedColor"`       // Color for help text, controls and scroll hints
+	BorderColor      string `json:"borderColor"`      // Color for the log viewport border
+	InfoColor        string `json:"infoColor"`        // Color for confirmations (format toggled, connected)
+	WarningColor     string `json:"warningColor"`     // Color for status and loading messages
+	ErrorColor       string `json:"errorColor"`       // Color for error messages
+	SelectionBgColor string `json:"selectionBgColor"` // Background for the selected item in menus
+	SelectionFgColor string `json:"selectionFgColor"` // Text color for the selected item in menus
 
 	// ========== SEARCH INTERFACE COLORS ==========
-	SearchColor string // Color for search input text
-	MatchColor  string // Color for match counter display
+	SearchColor string `json:"searchColor"` // Color for search input text
+	MatchColor  string `json:"matchColor"`  // Color for match counter display
 
 	// ========== LOG LINE DISPLAY COLORS ==========
-	EvenRowColor  string // Text color for even-numbered log lines (zebra striping)
-	OddRowColor   string // Text color for odd-numbered log lines (zebra striping)
-	CursorBgColor string // Background color for currently selected log line
-	CursorFgColor string // Text color for currently selected log line
+	EvenRowColor  string `json:"evenRowColor"`  // Text color for even-numbered log lines (zebra striping)
+	OddRowColor   string `json:"oddRowColor"`   // Text color for odd-numbered log lines (zebra striping)
+	CursorBgColor string `json:"cursorBgColor"` // Background color for currently selected log line
+	CursorFgColor string `json:"cursorFgColor"` // Text color for currently selected log line
 
 	// ========== SEARCH MATCH HIGHLIGHTING ==========
-	MatchBgColor string // Background color for search matches within log text
-	MatchFgColor string // Text color for search matches within log text
+	MatchBgColor        string `json:"matchBgColor"`        // Background color for search matches within log text
+	MatchFgColor        string `json:"matchFgColor"`        // Text color for search matches within log text
+	CurrentMatchBgColor string `json:"currentMatchBgColor"` // Background color for the match under the cursor
+	CurrentMatchFgColor string `json:"currentMatchFgColor"` // Text color for the match under the cursor
+
+	// ========== ACCESS LOG FIELD COLORS ==========
+	Status2xxColor    string `json:"status2xxColor"`    // Successful responses
+	Status3xxColor    string `json:"status3xxColor"`    // Redirects
+	Status4xxColor    string `json:"status4xxColor"`    // Client errors
+	Status5xxColor    string `json:"status5xxColor"`    // Server errors
+	MethodGetColor    string `json:"methodGetColor"`    // GET requests
+	MethodPostColor   string `json:"methodPostColor"`   // POST requests
+	MethodPutColor    string `json:"methodPutColor"`    // PUT requests
+	MethodDeleteColor string `json:"methodDeleteColor"` // DELETE requests
+	FieldColor        string `json:"fieldColor"`        // Paths, other methods and unknown statuses
+	IPColor           string `json:"ipColor"`           // Client addresses
+	SizeColor         string `json:"sizeColor"`         // Response sizes and other secondary values
+
+	// ========== LOG LEVEL COLORS ==========
+	// INFO and undetected levels keep the zebra row colors
+	LevelTraceColor string `json:"levelTraceColor"` // TRACE lines
+	LevelDebugColor string `json:"levelDebugColor"` // DEBUG lines
+	LevelWarnColor  string `json:"levelWarnColor"`  // WARN lines
+	LevelErrorColor string `json:"levelErrorColor"` // ERROR lines
+	LevelFatalColor string `json:"levelFatalColor"` // FATAL/CRITICAL lines (also bold)
+
+	// ========== JSON COLORS ==========
+	JSONKeyColor    string `json:"jsonKeyColor"`    // Object keys
+	JSONStringColor string `json:"jsonStringColor"` // String values
+	JSONNumberColor string `json:"jsonNumberColor"` // Numbers
+	JSONBoolColor   string `json:"jsonBoolColor"`   // true and false
+	JSONNullColor   string `json:"jsonNullColor"`   // null
+
+	JSONPunctuationColor string `json:"jsonPunctuationColor"` // Braces, brackets, colons and commas
 }
 
 // NewUIConfig creates the default configuration with optimized settings for most use cases
@@ -98,26 +174,31 @@ func NewUIConfig() *UIConfig {
 		ParseAccessLogs: true, // Enable access log parsing by default
 		ColorizeFields:  true, // Enable field colorization for better readability
 
+		// ========== TIMESTAMPS ==========
+		TimeFormat: timeFormatLocal, // Clock time in the local zone
+
 		// ========== COLOR SCHEME ==========
-		Colors: ColorScheme{
-			// Header & UI colors
-			HeaderColor: "12", // Blue - stands out for log group identification
-
-			// Search interface colors
-			SearchColor: "11", // Yellow - bright and attention-grabbing for search mode
-			MatchColor:  "10", // Green - positive color for successful matches
-
-			// Log line display colors (zebra striping for readability)
-			EvenRowColor:  "245", // Light gray - subtle for alternating rows
-			OddRowColor:   "15",  // White - high contrast with even rows
-			CursorBgColor: "8",   // Dark gray - clear selection indicator
-			CursorFgColor: "15",  // White - high contrast text on cursor
-
-			// Search match highlighting
-			MatchBgColor: "10", // Green background - makes matches pop out
-			MatchFgColor: "0",  // Black text - ensures readability on green background
-		},
+		Theme:  defaultThemeName,
+		Colors: darkColorScheme(),
+
+		// ========== KEY BINDINGS ==========
+		Keys: defaultKeyMap(),
+	}
+}
+
+// ForLogGroup returns a copy of the config with every matching profile merged in
+func (c *UIConfig) ForLogGroup(logGroup string) *UIConfig {
+	merged := *c
+	var names []string
+	for i := range c.Profiles {
+		profile := &c.Profiles[i]
+		if profile.matches(logGroup) {
+			profile.Settings.apply(&merged)
+			names = append(names, profile.Name)
+		}
 	}
+	merged.ActiveProfile = strings.Join(names, "+")
+	return &merged
 }
 
 // Style helpers
@@ -133,6 +214,26 @@ func (c *UIConfig) MatchStyle() lipgloss.Style {
 	return lipgloss.NewStyle().Foreground(lipgloss.Color(c.Colors.MatchColor))
 }
 
+func (c *UIConfig) MutedStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c.Colors.MutedColor))
+}
+
+func (c *UIConfig) InfoStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c.Colors.InfoColor))
+}
+
+func (c *UIConfig) WarningStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c.Colors.WarningColor))
+}
+
+func (c *UIConfig) ErrorStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c.Colors.ErrorColor))
+}
+
+func (c *UIConfig) FieldStyle(color string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+}
+
 func (c *UIConfig) EvenRowStyle() lipgloss.Style {
 	return lipgloss.NewStyle().Foreground(lipgloss.Color(c.Colors.EvenRowColor))
 }
@@ -142,13 +243,26 @@ func (c *UIConfig) OddRowStyle() lipgloss.Style {
 }
 
 func (c *UIConfig) CursorStyle() lipgloss.Style {
-	return lipgloss.NewStyle().Background(lipgloss.Color(c.Colors.CursorBgColor)).Foreground(lipgloss.Color(c.Colors.CursorFgColor))
+	return inverseStyle(c.Colors.CursorBgColor, c.Colors.CursorFgColor)
+}
+
+func (c *UIConfig) SelectionStyle() lipgloss.Style {
+	return inverseStyle(c.Colors.SelectionBgColor, c.Colors.SelectionFgColor)
 }
 
 func (c *UIConfig) HighlightStyle() lipgloss.Style {
-	return lipgloss.NewStyle().Background(lipgloss.Color(c.Colors.MatchBgColor)).Foreground(lipgloss.Color(c.Colors.MatchFgColor))
+	return inverseStyle(c.Colors.MatchBgColor, c.Colors.MatchFgColor)
 }
 
 func (c *UIConfig) CurrentMatchStyle() lipgloss.Style {
-	return lipgloss.NewStyle().Background(lipgloss.Color("196")).Foreground(lipgloss.Color("15")).Bold(true)
+	return inverseStyle(c.Colors.CurrentMatchBgColor, c.Colors.CurrentMatchFgColor).Bold(true).Underline(c.Colors.CurrentMatchBgColor == "")
+}
+
+// inverseStyle builds a background/foreground style, falling back to reverse video
+// when the theme leaves both colors empty (monochrome)
+func inverseStyle(bg, fg string) lipgloss.Style {
+	if bg == "" && fg == "" {
+		return lipgloss.NewStyle().Reverse(true)
+	}
+	return lipgloss.NewStyle().Background(lipgloss.Color(bg)).Foreground(lipgloss.Color(fg))
 }
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+Configuration File
+
+Settings can be overridden from a JSON file, by default
+~/.config/cwlogs/config.json (or $CWLOGS_CONFIG, or --config).
+A missing file is not an error; the built-in defaults are used.
+
+Example:
+
+	{
+	  "settings": { "refreshInterval": 3 },
+	  "profiles": [
+	    {
+	      "name": "access",
+	      "match": "*access*",
+	      "settings": { "mode": "formatted", "refreshInterval": 2 }
+	    },
+	    {
+	      "name": "batch",
+	      "regex": "^/batch/.*-jobs$",
+	      "settings": { "mode": "raw", "logTimeRange": 24, "maxLogBuffer": 20000 }
+	    }
+	  ]
+	}
+
+Every profile whose "match" glob or "regex" matches the log group name is
+merged onto the base settings in file order, so later profiles win.
+Themes and key bindings are configured here too; see theme.go and keymap.go.
+*/
+
+// configFileEnv names the environment variable that overrides the config path
+const configFileEnv = "CWLOGS_CONFIG"
+
+// configFile mirrors the on-disk JSON configuration
+type configFile struct {
+	Settings SettingsOverride           `json:"settings"`
+	Profiles []SettingsProfile          `json:"profiles"`
+	Theme    string                     `json:"theme,omitempty"`
+	Themes   map[string]themeDefinition `json:"themes,omitempty"`
+	Keys     keyMapOverrides            `json:"keys,omitempty"`
+
+	themes map[string]ColorScheme // Compiled from Themes by parseConfigFile
+	keys   *keyMap                // Default key map with Keys applied
+}
+
+// SettingsOverride holds optional UIConfig values; nil fields are left untouched
+type SettingsOverride struct {
+	Mode             *string   `json:"mode,omitempty"` // "raw" or "formatted"
+	Theme            *string   `json:"theme,omitempty"`
+	ProfilePageSize  *int      `json:"profilePageSize,omitempty"`
+	LogGroupPageSize *int      `json:"logGroupPageSize,omitempty"`
+	RefreshInterval  *int      `json:"refreshInterval,omitempty"`
+	MaxLogBuffer     *int      `json:"maxLogBuffer,omitempty"`
+	LogsPerFetch     *int32    `json:"logsPerFetch,omitempty"`
+	LogTimeRange     *int      `json:"logTimeRange,omitempty"`
+	APITimeout       *int      `json:"apiTimeout,omitempty"`
+	PrettyPrintJSON  *bool     `json:"prettyPrintJSON,omitempty"`
+	JSONIndent       *string   `json:"jsonIndent,omitempty"`
+	ParseAccessLogs  *bool     `json:"parseAccessLogs,omitempty"`
+	ColorizeFields   *bool     `json:"colorizeFields,omitempty"`
+	MinLevel         *string   `json:"minLevel,omitempty"`   // "all", "debug", "info", "warn" or "error"
+	Columns          *[]string `json:"columns,omitempty"`    // Field paths shown in columns mode
+	Timestamps       *string   `json:"timestamps,omitempty"` // Timestamp format, see timestamps.go
+	TimeZone         *string   `json:"timeZone,omitempty"`   // IANA zone of the "zone" format
+	ShowIngestion    *bool     `json:"showIngestion,omitempty"`
+
+	Highlights *[]HighlightRule `json:"highlights,omitempty"` // Named highlight rules, replacing earlier ones
+
+	Parsers   *ParserSettings    `json:"parsers,omitempty"`   // Order and enable flags, merged onto earlier settings
+	Redaction *RedactionSettings `json:"redaction,omitempty"` // Masked keys and patterns, merged onto earlier settings
+}
+
+// SettingsProfile is a named set of overrides applied to matching log groups
+type SettingsProfile struct {
+	Name     string           `json:"name"`
+	Match    string           `json:"match,omitempty"` // Glob, "*" also matches "/"
+	Regex    string           `json:"regex,omitempty"` // Go regular expression
+	Settings SettingsOverride `json:"settings"`
+
+	re *regexp.Regexp // Compiled from Match or Regex by compile()
+}
+
+// defaultConfigPath returns the config file location honoring $CWLOGS_CONFIG
+func defaultConfigPath() string {
+	if path := os.Getenv(configFileEnv); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cwlogs", "config.json")
+}
+
+// loadConfigFile reads the config file at path. A missing file yields (nil, nil).
+func loadConfigFile(path string) (*configFile, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	file, err := parseConfigFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// parseConfigFile decodes and validates config file contents
+func parseConfigFile(data []byte) (*configFile, error) {
+	var file configFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	themes, err := compileThemes(file.Themes)
+	if err != nil {
+		return nil, err
+	}
+	file.themes = themes
+
+	keys, err := defaultKeyMap().withOverrides(file.Keys)
+	if err != nil {
+		return nil, err
+	}
+	file.keys = keys
+
+	if err := file.Settings.validate(); err != nil {
+		return nil, fmt.Errorf("settings: %w", err)
+	}
+
+	for i := range file.Profiles {
+		if err := file.Profiles[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Theme names can only be checked once custom themes are known
+	probe := NewUIConfig()
+	probe.Themes = themes
+	check := func(where, name string) error {
+		if err := probe.SetTheme(name); err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		return nil
+	}
+	if file.Theme != "" {
+		if err := check("theme", file.Theme); err != nil {
+			return nil, err
+		}
+	}
+	if file.Settings.Theme != nil {
+		if err := check("settings", *file.Settings.Theme); err != nil {
+			return nil, err
+		}
+	}
+	for _, profile := range file.Profiles {
+		if profile.Settings.Theme != nil {
+			if err := check(fmt.Sprintf("profile %q", profile.Name), *profile.Settings.Theme); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &file, nil
+}
+
+// apply merges the config file onto cfg
+func (f *configFile) apply(cfg *UIConfig) {
+	if f == nil {
+		return
+	}
+	cfg.Themes = f.themes
+	cfg.Keys = f.keys
+	if f.Theme != "" && !cfg.NoColor {
+		_ = cfg.SetTheme(f.Theme) // Validated by parseConfigFile
+	}
+	f.Settings.apply(cfg)
+	cfg.Profiles = f.Profiles
+}
+
+// compile validates the profile and prepares its matcher
+func (p *SettingsProfile) compile() error {
+	if p.Name == "" {
+		return fmt.Errorf("profile is missing a name")
+	}
+	if (p.Match == "") == (p.Regex == "") {
+		return fmt.Errorf("profile %q must set exactly one of match or regex", p.Name)
+	}
+
+	pattern := p.Regex
+	if p.Match != "" {
+		pattern = globToRegex(p.Match)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("profile %q: invalid pattern: %w", p.Name, err)
+	}
+	p.re = re
+
+	if err := p.Settings.validate(); err != nil {
+		return fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	return nil
+}
+
+// matches reports whether the profile applies to the log group
+func (p *SettingsProfile) matches(logGroup string) bool {
+	if p.re == nil {
+		if p.compile() != nil {
+			return false
+		}
+	}
+	return p.re.MatchString(logGroup)
+}
+
+// globToRegex converts a glob into an anchored regex; "*" matches any run of characters
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// validate rejects values that would break the viewer
+func (o *SettingsOverride) validate() error {
+	if o.Mode != nil && *o.Mode != "raw" && *o.Mode != "formatted" {
+		return fmt.Errorf("mode must be \"raw\" or \"formatted\", got %q", *o.Mode)
+	}
+	positive := map[string]*int{
+		"refreshInterval":  o.RefreshInterval,
+		"maxLogBuffer":     o.MaxLogBuffer,
+		"logTimeRange":     o.LogTimeRange,
+		"apiTimeout":       o.APITimeout,
+		"profilePageSize":  o.ProfilePageSize,
+		"logGroupPageSize": o.LogGroupPageSize,
+	}
+	for name, value := range positive {
+		if value != nil && *value <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", name, *value)
+		}
+	}
+	if o.LogsPerFetch != nil && (*o.LogsPerFetch <= 0 || *o.LogsPerFetch > 10000) {
+		return fmt.Errorf("logsPerFetch must be between 1 and 10000, got %d", *o.LogsPerFetch)
+	}
+	if o.MinLevel != nil {
+		if _, err := parseLevelSetting(*o.MinLevel); err != nil {
+			return fmt.Errorf("minLevel: %w", err)
+		}
+	}
+	if o.Parsers != nil {
+		if err := o.Parsers.validate(); err != nil {
+			return err
+		}
+	}
+	if o.Redaction != nil {
+		if err := o.Redaction.validate(); err != nil {
+			return err
+		}
+	}
+	if o.Highlights != nil {
+		if err := validateHighlightRules(*o.Highlights); err != nil {
+			return err
+		}
+	}
+	if o.Columns != nil {
+		for _, column := range *o.Columns {
+			if strings.TrimSpace(column) == "" {
+				return fmt.Errorf("columns: empty field path")
+			}
+		}
+	}
+	if o.Timestamps != nil {
+		if _, err := parseTimeFormat(*o.Timestamps); err != nil {
+			return fmt.Errorf("timestamps: %w", err)
+		}
+	}
+	if o.TimeZone != nil {
+		if _, err := time.LoadLocation(*o.TimeZone); err != nil {
+			return fmt.Errorf("timeZone: %w", err)
+		}
+	}
+	return nil
+}
+
+// apply copies every set field onto cfg
+func (o *SettingsOverride) apply(cfg *UIConfig) {
+	if o.Mode != nil {
+		formatted := *o.Mode == "formatted"
+		cfg.ParseAccessLogs = formatted
+		cfg.PrettyPrintJSON = formatted
+		cfg.ColorizeFields = formatted
+	}
+	if o.Theme != nil && !cfg.NoColor {
+		_ = cfg.SetTheme(*o.Theme) // Validated by parseConfigFile
+	}
+	if o.ProfilePageSize != nil {
+		cfg.ProfilePageSize = *o.ProfilePageSize
+	}
+	if o.LogGroupPageSize != nil {
+		cfg.LogGroupPageSize = *o.LogGroupPageSize
+	}
+	if o.RefreshInterval != nil {
+		cfg.RefreshInterval = *o.RefreshInterval
+	}
+	if o.MaxLogBuffer != nil {
+		cfg.MaxLogBuffer = *o.MaxLogBuffer
+	}
+	if o.LogsPerFetch != nil {
+		cfg.LogsPerFetch = *o.LogsPerFetch
+	}
+	if o.LogTimeRange != nil {
+		cfg.LogTimeRange = *o.LogTimeRange
+	}
+	if o.APITimeout != nil {
+		cfg.APITimeout = *o.APITimeout
+	}
+	if o.PrettyPrintJSON != nil {
+		cfg.PrettyPrintJSON = *o.PrettyPrintJSON
+	}
+	if o.JSONIndent != nil {
+		cfg.JSONIndent = *o.JSONIndent
+	}
+	if o.ParseAccessLogs != nil {
+		cfg.ParseAccessLogs = *o.ParseAccessLogs
+	}
+	if o.ColorizeFields != nil {
+		cfg.ColorizeFields = *o.ColorizeFields
+	}
+	if o.MinLevel != nil {
+		cfg.MinLevel, _ = parseLevelSetting(*o.MinLevel) // Validated by validate
+	}
+	if o.Parsers != nil {
+		cfg.setParserSettings(cfg.ParserSettings.merge(*o.Parsers))
+	}
+	if o.Redaction != nil {
+		cfg.setRedaction(cfg.Redaction.merge(*o.Redaction))
+	}
+	if o.Highlights != nil {
+		cfg.Highlights = *o.Highlights
+	}
+	if o.Columns != nil {
+		cfg.Columns = *o.Columns
+	}
+	if o.Timestamps != nil {
+		cfg.TimeFormat, _ = parseTimeFormat(*o.Timestamps) // Validated by validate
+	}
+	if o.TimeZone != nil {
+		cfg.TimeZone, _ = time.LoadLocation(*o.TimeZone)
+	}
+	if o.ShowIngestion != nil {
+		cfg.ShowIngestion = *o.ShowIngestion
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigFile(t *testing.T) {
+	t.Run("MissingFileUsesDefaults", func(t *testing.T) {
+		// Act
+		file, err := loadConfigFile(filepath.Join(t.TempDir(), "missing.json"))
+
+		// Assert
+		assertNoError(t, err)
+		if file != nil {
+			t.Errorf("expected nil config for missing file, got %+v", file)
+		}
+	})
+
+	t.Run("LoadFromDisk", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "config.json")
+		data := `{"settings":{"refreshInterval":3},"profiles":[{"name":"access","match":"/aws/*/access-*","settings":{"refreshInterval":1}}]}`
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		file, err := loadConfigFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := NewUIConfig()
+		file.apply(cfg)
+
+		// Assert
+		assertIntEqual(t, cfg.RefreshInterval, 3, "base refresh interval")
+		assertIntEqual(t, len(cfg.Profiles), 1, "profile count")
+	})
+
+	t.Run("InvalidFiles", func(t *testing.T) {
+		tests := []struct {
+			name string
+			data string
+			want string
+		}{
+			{"bad json", `{`, "unexpected end"},
+			{"missing name", `{"profiles":[{"match":"*"}]}`, "missing a name"},
+			{"no matcher", `{"profiles":[{"name":"x"}]}`, "exactly one of match or regex"},
+			{"both matchers", `{"profiles":[{"name":"x","match":"*","regex":".*"}]}`, "exactly one of match or regex"},
+			{"bad regex", `{"profiles":[{"name":"x","regex":"("}]}`, "invalid pattern"},
+			{"bad mode", `{"settings":{"mode":"fancy"}}`, "mode must be"},
+			{"bad timestamps", `{"settings":{"timestamps":"epoch"}}`, "unknown timestamp format"},
+			{"bad time zone", `{"settings":{"timeZone":"Mars/Olympus"}}`, "timeZone"},
+			{"bad redaction pattern", `{"settings":{"redaction":{"patterns":["("]}}}`, "redaction: invalid pattern"},
+			{"unknown detector", `{"settings":{"redaction":{"detectors":{"ssn":false}}}}`, "unknown detector"},
+			{"bad highlight color", `{"settings":{"highlights":[{"name":"t","pattern":"t","color":"plaid"}]}}`, "unknown color"},
+			{"repeated highlight", `{"settings":{"highlights":[{"name":"t","pattern":"a"},{"name":"t","pattern":"b"}]}}`, "defined twice"},
+			{"negative buffer", `{"profiles":[{"name":"x","match":"*","settings":{"maxLogBuffer":-1}}]}`, "maxLogBuffer must be positive"},
+			{"zero profile page size", `{"settings":{"profilePageSize":0}}`, "profilePageSize must be positive"},
+			{"negative log group page size", `{"settings":{"logGroupPageSize":-5}}`, "logGroupPageSize must be positive"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Act
+				_, err := parseConfigFile([]byte(tt.data))
+
+				// Assert
+				assertError(t, err, tt.want)
+			})
+		}
+	})
+
+	t.Run("GlobMatching", func(t *testing.T) {
+		tests := []struct {
+			glob     string
+			logGroup string
+			want     bool
+		}{
+			{"/aws/lambda/*", "/aws/lambda/api", true},
+			{"/aws/lambda/*", "/aws/ecs/api", false},
+			{"*access*", "/aws/elb/prod/access-logs", true},
+			{"/batch/job-?", "/batch/job-1", true},
+			{"/batch/job-?", "/batch/job-12", false},
+			{"/a.b", "/aXb", false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.glob+" "+tt.logGroup, func(t *testing.T) {
+				// Arrange
+				profile := SettingsProfile{Name: "p", Match: tt.glob}
+				assertNoError(t, profile.compile())
+
+				// Act & Assert
+				assertBoolEqual(t, profile.matches(tt.logGroup), tt.want, "glob match")
+			})
+		}
+	})
+
+	t.Run("ForLogGroupMergesMatchingProfiles", func(t *testing.T) {
+		// Arrange
+		file, err := parseConfigFile([]byte(`{"profiles":[
+			{"name":"access","match":"*access*","settings":{"refreshInterval":2}},
+			{"name":"batch","regex":"^/batch/","settings":{"mode":"raw","logTimeRange":24,"maxLogBuffer":20000}},
+			{"name":"nightly","match":"/batch/nightly","settings":{"logTimeRange":48}}
+		]}`))
+		assertNoError(t, err)
+		base := NewUIConfig()
+		file.apply(base)
+
+		// Act
+		access := base.ForLogGroup("/prod/access-log")
+		batch := base.ForLogGroup("/batch/nightly")
+		other := base.ForLogGroup("/aws/lambda/api")
+
+		// Assert
+		assertIntEqual(t, access.RefreshInterval, 2, "access refresh interval")
+		assertStringEqual(t, access.ActiveProfile, "access")
+		assertBoolEqual(t, access.ParseAccessLogs, true, "access formatted mode")
+
+		assertStringEqual(t, batch.ActiveProfile, "batch+nightly")
+		assertBoolEqual(t, batch.ParseAccessLogs, false, "batch raw mode")
+		assertBoolEqual(t, batch.PrettyPrintJSON, false, "batch raw JSON")
+		assertIntEqual(t, batch.LogTimeRange, 48, "later profile wins")
+		assertIntEqual(t, batch.MaxLogBuffer, 20000, "batch buffer")
+
+		assertStringEqual(t, other.ActiveProfile, "")
+		assertIntEqual(t, other.RefreshInterval, base.RefreshInterval, "unmatched refresh interval")
+
+		// Base config must not be modified by merging
+		assertIntEqual(t, base.MaxLogBuffer, 5000, "base buffer untouched")
+	})
+}
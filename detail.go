@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*
+Detail View
+
+Enter opens the entry under the cursor in an overlay. JSON messages (including
+CloudTrail events of any size) are shown as a collapsible tree in their original
+key order (see jsontree.go), as is JSON after a text prefix and JSON inside
+string values such as a container's log line: the movement keys move the cursor, right/left
+expand and collapse nodes, tab jumps between keys of the same object, y copies
+the value at the cursor and Y its path. Other messages are shown whole,
+followed by the fields their parser extracted, and scroll with the movement
+keys. Esc or q closes the overlay.
+*/
+
+// detailView is the state of the detail overlay
+type detailView struct {
+	title   string
+	message string
+	tree    *jsonTree // JSON messages; nil shows lines instead
+	lines   []string  // Message and fields of other messages
+	offset  int       // First visible line
+}
+
+// newDetailView builds the detail overlay of an entry
+func newDetailView(entry *logEntry, config *UIConfig) *detailView {
+	title := entry.Timestamp.Format("2006-01-02 15:04:05.000")
+	if entry.Stream != "" {
+		title += "  " + entry.Stream
+	}
+	if entry.Format != "" {
+		title += "  (" + entry.Format + ")"
+	}
+	if !entry.IngestionTime.IsZero() {
+		title += fmt.Sprintf("  ingested %s (%s)", entry.IngestionTime.Format("15:04:05.000"),
+			formatDelay(entry.IngestionTime.Sub(entry.Timestamp)))
+	}
+
+	message := strings.TrimSpace(config.redact(entry.OriginalMessage))
+	d := &detailView{title: title, message: message}
+	if root := detailJSON(message, entry); root != nil {
+		d.tree = newJSONTree(root)
+		return d
+	}
+
+	d.lines = strings.Split(message, "\n")
+	if len(entry.Fields) > 0 {
+		names := make([]string, 0, len(entry.Fields))
+		for name := range entry.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		d.lines = append(d.lines, "")
+		for _, name := range names {
+			d.lines = append(d.lines, fmt.Sprintf("%s: %v", name, entry.Fields[name]))
+		}
+	}
+	return d
+}
+
+// detailJSON finds the JSON of an entry: the message itself, the object after
+// a text prefix (Lambda START lines, timestamps), or the structured fields a
+// parser extracted. JSON held in strings, such as a container's inner line,
+// is decoded in place. Returns nil for entries without JSON.
+func detailJSON(message string, entry *logEntry) *jsonNode {
+	root, err := parseJSONTree(message)
+	if err != nil {
+		root = nil
+		if spans := findEmbeddedJSON(message); len(spans) == 1 {
+			root, _ = parseJSONTree(message[spans[0][0]:spans[0][1]])
+		}
+	}
+	if root == nil && structuredFields(entry.Fields) {
+		if data, err := json.Marshal(entry.Fields); err == nil {
+			root, _ = parseJSONTree(string(data))
+		}
+	}
+	if root != nil {
+		root.decodeNestedStrings(0)
+	}
+	return root
+}
+
+// structuredFields reports whether a parser extracted objects or arrays
+func structuredFields(fields map[string]interface{}) bool {
+	for _, value := range fields {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return true
+		}
+	}
+	return false
+}
+
+// lineCount returns the number of lines the overlay can show
+func (d *detailView) lineCount() int {
+	if d.tree != nil {
+		return len(d.tree.visible)
+	}
+	return len(d.lines)
+}
+
+// scroll moves the overlay by delta lines within a page of the given height
+func (d *detailView) scroll(delta, height int) {
+	d.offset = max(0, min(d.offset+delta, d.lineCount()-height))
+}
+
+// move moves the tree cursor, or scrolls text, by delta lines
+func (d *detailView) move(delta, height int) {
+	if d.tree == nil {
+		d.scroll(delta, height)
+		return
+	}
+	d.tree.move(delta)
+	d.follow(height)
+}
+
+// follow scrolls so the tree cursor stays on the page
+func (d *detailView) follow(height int) {
+	if d.tree == nil {
+		return
+	}
+	cursor := d.tree.cursor
+	if cursor < d.offset {
+		d.offset = cursor
+	} else if cursor >= d.offset+height {
+		d.offset = cursor - height + 1
+	}
+	d.scroll(0, height)
+}
+
+// openDetail shows the detail overlay for the entry under the cursor
+func (m *logModel) openDetail() {
+	logs := m.safeLogs()
+	if m.cursor < 0 || m.cursor >= len(logs) {
+		return
+	}
+	m.detail = newDetailView(&logs[m.cursor], m.config)
+	m.followMode = false
+}
+
+// handleDetailKey navigates, copies from or closes the detail overlay
+func (m *logModel) handleDetailKey(action string) tea.Cmd {
+	d := m.detail
+	height := m.detailHeight()
+	switch action {
+	case actionQuit:
+		return tea.Quit
+	case actionClose:
+		m.detail = nil
+	case actionUp:
+		d.move(-1, height)
+	case actionDown:
+		d.move(1, height)
+	case actionPageUp:
+		d.move(-height, height)
+	case actionPageDown:
+		d.move(height, height)
+	case actionTop:
+		d.move(-d.lineCount(), height)
+	case actionBottom:
+		d.move(d.lineCount(), height)
+	case actionCopyValue:
+		if d.tree != nil {
+			return copyTextCmd(d.tree.selected().copyText(), "Value")
+		}
+		return copyTextCmd(d.message, "Log event")
+	case actionCopyPath:
+		if d.tree == nil {
+			return func() tea.Msg { return statusMsg("Not a JSON event") }
+		}
+		return copyTextCmd(d.tree.selected().path(), "Path")
+	}
+
+	if d.tree == nil {
+		if action == actionToggleNode {
+			m.detail = nil // Enter closes text details as it opened them
+		}
+		return nil
+	}
+	switch action {
+	case actionExpand:
+		d.tree.expand()
+	case actionCollapse:
+		d.tree.collapse()
+	case actionToggleNode:
+		d.tree.toggle()
+	case actionNextKey:
+		d.tree.sibling(1)
+	case actionPrevKey:
+		d.tree.sibling(-1)
+	}
+	d.follow(height)
+	return nil
+}
+
+// copyTextCmd copies text to the clipboard and reports what was copied
+func copyTextCmd(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		if err := copyToClipboard(text); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to copy: %v", err))
+		}
+		return statusMsg(what + " copied to clipboard")
+	}
+}
+
+// detailHeight is the number of message lines the overlay shows
+func (m *logModel) detailHeight() int {
+	return max(1, m.height-uiReservedHeight-4) // Border, title and footer
+}
+
+// renderDetail renders the detail overlay. Error lines of JSON events are highlighted.
+func (m *logModel) renderDetail() string {
+	d := m.detail
+	height := m.detailHeight()
+	width := max(20, m.width-4)
+
+	end := min(d.lineCount(), d.offset+height)
+	var b strings.Builder
+	for i := d.offset; i < end; i++ {
+		b.WriteString(m.renderDetailLine(i, width) + "\n")
+	}
+
+	keys := m.config.Keys
+	footer := fmt.Sprintf("Lines %d-%d of %d · %s to close",
+		min(d.offset+1, end), end, d.lineCount(), keys.hint(keyContextDetail, actionClose))
+	if d.tree != nil {
+		footer = fmt.Sprintf("%s · %s · %s/%s expand/collapse · %s copy value · %s copy path",
+			d.tree.selected().path(), footer,
+			keys.hint(keyContextDetail, actionExpand), keys.hint(keyContextDetail, actionCollapse),
+			keys.hint(keyContextDetail, actionCopyValue), keys.hint(keyContextDetail, actionCopyPath))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(m.config.Colors.BorderColor)).
+		Padding(0, 1).
+		Render(m.config.HeaderStyle().Render(d.title) + "\n" + b.String() +
+			m.config.MutedStyle().MaxWidth(width).Render(footer))
+}
+
+// renderDetailLine renders line i of the overlay
+func (m *logModel) renderDetailLine(i, width int) string {
+	d := m.detail
+	if d.tree == nil {
+		line := d.lines[i]
+		if len([]rune(line)) > width {
+			line = string([]rune(line)[:width-1]) + "…"
+		}
+		return line
+	}
+
+	node := d.tree.visible[i]
+	if i == d.tree.cursor {
+		return m.config.CursorStyle().Render(renderJSONNode(node, m.config, width, true))
+	}
+	if node.key == "errorCode" || node.key == "errorMessage" {
+		return m.config.ErrorStyle().Render(renderJSONNode(node, m.config, width, true))
+	}
+	return renderJSONNode(node, m.config, width, false)
+}
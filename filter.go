@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+/*
+Filter View
+
+Search highlights matches among all lines; a filter hides every line that does
+not match. & opens the filter prompt, which takes space-separated terms:
+
+	error payment        lines containing "error" and "payment"
+	error !healthcheck   lines containing "error" but not "healthcheck"
+	"connection reset"   quotes keep spaces inside a term
+
+Terms are matched like search queries, so the search modes (ctrl+r regex,
+ctrl+t case-sensitive, ctrl+e whole word) apply to them too; the filter prompt
+has its own bindings, but the modes it toggles are shared with search. A filter that
+starts with ":" is a field query instead (see query.go). + and - show more
+or fewer context lines around each match, like grep -C; a "--" line separates
+groups that are not adjacent. The header shows "showing X of Y" while a filter
+is active. Submitting an empty filter removes it.
+
+The filter is applied when rows are laid out, so entries arriving in follow
+mode are filtered as they come; the store keeps every entry. Each filter keeps
+its result per entry, so only new and reformatted entries are matched again.
+Entries still waiting to be reformatted are matched on their current text and
+again once reformatted. A filter keeps the modes it was applied with: changing
+them in the search prompt does not touch it, and applying the filter again
+rebuilds it with the current ones.
+*/
+
+// maxFilterContext bounds the context lines shown around each match
+const maxFilterContext = 20
+
+// logFilter is a compiled filter expression
+type logFilter struct {
+	query   string
+	include []*regexp.Regexp // Every one must match
+	exclude []*regexp.Regexp // None may match
+	fields  *fieldQuery      // Replaces the terms when the filter starts with ":" (see query.go)
+	cache   filterCache
+}
+
+// filterCache holds the result of a filter for each store entry
+type filterCache struct {
+	store   *logStore
+	dropped int      // store.Dropped() when the results were last synced
+	edits   int      // store.Edits() at that time
+	matched []bool   // Result per entry, aligned with the store's Slice
+	texts   []string // Text each result was computed from; an edit that keeps it keeps the result
+}
+
+// parseFilter compiles the terms of a filter expression with the search modes
+func parseFilter(query string, options searchOptions) (*logFilter, error) {
+	f := &logFilter{query: strings.TrimSpace(query)}
+	if isFieldQuery(query) {
+		q, err := parseFieldQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		f.fields = q
+		return f, nil
+	}
+	for _, term := range splitFilterTerms(query) {
+		target := &f.include
+		if len(term) > 1 && term[0] == '!' {
+			target, term = &f.exclude, term[1:]
+		}
+		re, err := options.compile(term)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", term, searchErrorText(err))
+		}
+		*target = append(*target, re)
+	}
+	return f, nil
+}
+
+// splitFilterTerms splits at spaces outside double quotes and drops the quotes
+func splitFilterTerms(query string) []string {
+	var terms []string
+	var term strings.Builder
+	quoted, started := false, false
+	flush := func() {
+		if started {
+			terms = append(terms, term.String())
+		}
+		term.Reset()
+		started = false
+	}
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case !quoted && (r == ' ' || r == '\t'):
+			flush()
+		default:
+			term.WriteRune(r)
+			started = true
+		}
+	}
+	flush()
+	return terms
+}
+
+// matches reports whether an entry passes the filter
+func (f *logFilter) matches(entry *logEntry) bool {
+	if f.fields != nil {
+		return f.fields.matches(entry)
+	}
+	text := entry.plainText()
+	for _, re := range f.include {
+		if !re.MatchString(text) {
+			return false
+		}
+	}
+	for _, re := range f.exclude {
+		if re.MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterMatches returns the filter's result for each entry of logs, matching
+// only the entries that arrived or changed since the last call
+func (m *logModel) filterMatches(logs []logEntry) []bool {
+	c := &m.filter.cache
+	if c.store != m.store || m.store.Dropped() < c.dropped {
+		*c = filterCache{store: m.store, dropped: m.store.Dropped(), edits: m.store.Edits()}
+	}
+
+	// Entries the ring buffer overwrote drop out at the front
+	if shift := min(m.store.Dropped()-c.dropped, len(c.matched)); shift > 0 {
+		c.matched, c.texts = c.matched[shift:], c.texts[shift:]
+	}
+	c.dropped = m.store.Dropped()
+	if len(c.matched) > len(logs) {
+		c.matched, c.texts = nil, nil
+	}
+
+	// Reformatted entries are matched again when their text changed
+	if c.edits != m.store.Edits() {
+		for i := range c.matched {
+			if text := logs[i].plainText(); text != c.texts[i] {
+				c.matched[i], c.texts[i] = m.filter.matches(&logs[i]), text
+			}
+		}
+		c.edits = m.store.Edits()
+	}
+
+	for i := len(c.matched); i < len(logs); i++ {
+		c.matched = append(c.matched, m.filter.matches(&logs[i]))
+		c.texts = append(c.texts, logs[i].plainText())
+	}
+	return c.matched
+}
+
+// filterRows keeps the rows that match the filter and their context rows.
+// breaks marks rows that start a group not adjacent to the previous one.
+func (m *logModel) filterRows(logs []logEntry, rows []int) (shown []int, breaks map[int]bool) {
+	if m.filter == nil {
+		return rows, nil
+	}
+
+	matched := m.filterMatches(logs)
+	keep := make([]bool, len(rows))
+	for pos, index := range rows {
+		if !matched[index] {
+			continue
+		}
+		for i := max(0, pos-m.filterContext); i <= min(len(rows)-1, pos+m.filterContext); i++ {
+			keep[i] = true
+		}
+	}
+
+	shown = make([]int, 0, len(rows))
+	last := -1
+	for pos, index := range rows {
+		if !keep[pos] {
+			continue
+		}
+		if m.filterContext > 0 && last >= 0 && pos > last+1 {
+			if breaks == nil {
+				breaks = make(map[int]bool)
+			}
+			breaks[index] = true
+		}
+		shown = append(shown, index)
+		last = pos
+	}
+	return shown, breaks
+}
+
+// handleFilterPromptKey edits the filter prompt
+func (m *logModel) handleFilterPromptKey(key string) tea.Cmd {
+	action, pending := m.config.Keys.resolve(keyContextFilter, m.pendingKeys, key)
+	m.pendingKeys = pending
+
+	switch action {
+	case actionSubmit:
+		if m.filterError != "" {
+			return nil // Keep the prompt open until every term compiles
+		}
+		m.filterMode = false
+		return m.applyFilter()
+	case actionCancel:
+		m.filterMode = false
+		m.filterError = ""
+		return nil
+	}
+	cmd := m.editPrompt(&m.filterQuery, action, key, pending)
+	m.checkFilterQuery()
+	return cmd
+}
+
+// openFilterPrompt starts editing the active filter
+func (m *logModel) openFilterPrompt() {
+	m.filterMode = true
+	m.filterQuery = ""
+	if m.filter != nil {
+		m.filterQuery = m.filter.query
+	}
+	m.checkFilterQuery()
+}
+
+// checkFilterQuery compiles the filter being typed so errors show before it is applied
+func (m *logModel) checkFilterQuery() {
+	m.filterError = ""
+	if _, err := parseFilter(m.filterQuery, m.searchOptions); err != nil {
+		m.filterError = err.Error()
+	}
+}
+
+// applyFilter activates the typed filter, or removes it when empty
+func (m *logModel) applyFilter() tea.Cmd {
+	m.filter = nil
+	if strings.TrimSpace(m.filterQuery) != "" {
+		m.filter, _ = parseFilter(m.filterQuery, m.searchOptions) // Checked while typing
+	}
+	search := m.refreshAfterFilter()
+
+	if m.filter == nil {
+		m.formatStatusMsg = "Filter removed"
+	} else {
+		m.formatStatusMsg = "Filter: " + m.filter.query
+	}
+	return tea.Batch(search, clearFormatStatusCmd())
+}
+
+// changeFilterContext shows more or fewer context lines around filter matches
+func (m *logModel) changeFilterContext(delta int) tea.Cmd {
+	m.filterContext = max(0, min(maxFilterContext, m.filterContext+delta))
+	search := m.refreshAfterFilter()
+	m.formatStatusMsg = fmt.Sprintf("Filter context: %d lines", m.filterContext)
+	if m.filter == nil {
+		m.formatStatusMsg += " (no filter; press " + m.config.Keys.hint(keyContextViewer, actionFilter) + ")"
+	}
+	return tea.Batch(search, clearFormatStatusCmd())
+}
+
+// refreshAfterFilter keeps the cursor and search matches on shown rows
+func (m *logModel) refreshAfterFilter() tea.Cmd {
+	m.fixCursor()
+	return m.research()
+}
+
+// filterIndicator is shown in the header while a filter is active
+func (m *logModel) filterIndicator(shown, total int) string {
+	if m.filter == nil {
+		return ""
+	}
+	text := fmt.Sprintf(" [filter: %s · showing %d of %d]", m.filter.query, shown, total)
+	return m.config.SearchStyle().Render(text)
+}
+
+// filterPromptStatus renders the prompt line of the status bar
+func (m *logModel) filterPromptStatus() string {
+	prompt := m.config.SearchStyle().Render("Filter [" + m.searchModeLabel(m.filterQuery) + "]: " + m.filterQuery + "_")
+	if m.filterError != "" {
+		return prompt + "  " + m.config.ErrorStyle().Render("✗ "+m.filterError)
+	}
+	keys := m.config.Keys
+	hints := "!term excludes · empty clears · " +
+		keys.hint(keyContextFilter, actionSearchRegex) + " regex · " +
+		keys.hint(keyContextFilter, actionSearchCase) + " case · " +
+		keys.hint(keyContextFilter, actionSearchWord) + " word"
+	return prompt + "  " + m.config.MutedStyle().Render(hints)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSplitFilterTerms(t *testing.T) {
+	got := splitFilterTerms(`error  !health "connection reset" !"dry run"`)
+	want := []string{"error", "!health", "connection reset", "!dry run"}
+	assertIntEqual(t, len(got), len(want), "term count")
+	for i := range want {
+		assertStringEqual(t, got[i], want[i])
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	// Arrange
+	f, err := parseFilter(`error !health`, searchOptions{})
+	assertNoError(t, err)
+
+	entry := func(message string) *logEntry {
+		e := makeLogEntry(testTimestamp, message, createTestConfig())
+		return &e
+	}
+
+	// Act & Assert
+	assertBoolEqual(t, f.matches(entry("ERROR payment failed")), true, "include")
+	assertBoolEqual(t, f.matches(entry("error in healthcheck")), false, "exclude")
+	assertBoolEqual(t, f.matches(entry("all good")), false, "missing include")
+
+	_, err = parseFilter(`ok !(`, searchOptions{regex: true})
+	assertError(t, err, "missing closing )")
+}
+
+func TestFilterView(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	messages := []string{"start", "error one", "after one", "quiet", "quiet", "before two", "error two", "end"}
+	for _, message := range messages {
+		model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+	}
+	simulateWindowResize(model, 120, 30)
+	typeFilter := func(query string) {
+		simulateKeyPress(model, "&")
+		for _, r := range query {
+			simulateKeyPress(model, string(r))
+		}
+		simulateKeyPress(model, "enter")
+	}
+
+	// Act
+	typeFilter("error")
+
+	// Assert - only matches are shown, the store keeps everything
+	rows := model.visibleRows(model.safeLogs())
+	assertIntEqual(t, len(rows), 2, "filtered rows")
+	assertIntEqual(t, model.store.Len(), len(messages), "store size")
+	view := stripANSI(model.View())
+	assertStringContains(t, view, "[filter: error · showing 2 of 8]")
+	assertBoolEqual(t, strings.Contains(view, "quiet"), false, "non-matching line shown")
+
+	// Act - one context line around each match
+	simulateKeyPress(model, "+")
+
+	// Assert
+	layout := model.layoutRows(model.safeLogs())
+	assertIntEqual(t, len(layout.rows), 6, "rows with context")
+	assertBoolEqual(t, layout.breaks[5], true, "gap before the second group")
+	assertStringContains(t, stripANSI(model.View()), "--")
+
+	// Act - new entries in follow mode are filtered too
+	model.Update(logsWithTokenMsg{logs: []logEntry{
+		makeLogEntry(testTimestamp, "noise", model.config),
+		makeLogEntry(testTimestamp, "error three", model.config),
+	}})
+	simulateKeyPress(model, "-")
+
+	// Assert
+	assertIntEqual(t, len(model.visibleRows(model.safeLogs())), 3, "rows after new entries")
+
+	// Act - exclusion, then clearing
+	typeFilter(" !two")
+	assertIntEqual(t, len(model.visibleRows(model.safeLogs())), 2, "rows with exclusion")
+	simulateKeyPress(model, "&")
+	for range "error !two" {
+		model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	simulateKeyPress(model, "enter")
+
+	// Assert
+	if model.filter != nil {
+		t.Fatal("an empty filter should remove the filter")
+	}
+	assertIntEqual(t, len(model.visibleRows(model.safeLogs())), 10, "rows without filter")
+}
+
+func TestFilterMatchCache(t *testing.T) {
+	// Arrange - a small ring buffer that wraps
+	model := createTestLogModel("/app")
+	model.store = newLogStore(4)
+	for _, message := range []string{"error a", "ok", "error b", "ok"} {
+		model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+	}
+	model.filter, _ = parseFilter("error", searchOptions{})
+
+	// Act
+	matched := model.filterMatches(model.safeLogs())
+
+	// Assert
+	assertIntEqual(t, len(matched), 4, "results")
+	assertBoolEqual(t, matched[2], true, "error b")
+
+	// Act - two new entries overwrite the two oldest
+	model.store.Append(makeLogEntry(testTimestamp, "error c", model.config))
+	model.store.Append(makeLogEntry(testTimestamp, "ok", model.config))
+	matched = model.filterMatches(model.safeLogs())
+
+	// Assert - results follow their entries
+	want := []bool{true, false, true, false}
+	for i := range want {
+		assertBoolEqual(t, matched[i], want[i], fmt.Sprintf("result %d after wrap", i))
+	}
+
+	// Act - a reformatted entry is matched again
+	model.store.UpdateEntry(1, makeLogEntry(testTimestamp, "error d", model.config))
+	matched = model.filterMatches(model.safeLogs())
+
+	// Assert
+	assertBoolEqual(t, matched[1], true, "reformatted entry")
+	assertIntEqual(t, len(model.filter.cache.texts), 4, "cached texts")
+}
+
+func TestFilterReformatsLazily(t *testing.T) {
+	// Arrange - a large buffer whose entries wait to be reformatted
+	model := createTestLogModel("/app")
+	model.store = newLogStore(1000)
+	for i := 0; i < 500; i++ {
+		model.store.Append(makeLogEntry(testTimestamp, fmt.Sprintf(`{"level":"info","request":%d}`, i), model.config))
+	}
+	simulateWindowResize(model, 120, 30)
+	model.needsLazyReprocess = true
+
+	// Act
+	simulateKeyPress(model, "&")
+	for _, r := range "request" {
+		simulateKeyPress(model, string(r))
+	}
+	simulateKeyPress(model, "enter")
+
+	// Assert - matched on the cached lines, not a reformat of the whole buffer
+	assertIntEqual(t, len(model.visibleRows(model.safeLogs())), 500, "filtered rows")
+	assertBoolEqual(t, model.needsLazyReprocess, true, "lazy reprocess pending")
+	if model.store.Edits() >= model.store.Len() {
+		t.Errorf("applying a filter reformatted %d of %d entries", model.store.Edits(), model.store.Len())
+	}
+}
+
+func TestFilterPromptModes(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	for _, message := range []string{"Error one", "error two", "errors three"} {
+		model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+	}
+	simulateWindowResize(model, 160, 30)
+
+	// Act - whole-word terms from the filter prompt
+	simulateKeyPress(model, "&")
+	for _, r := range "error" {
+		simulateKeyPress(model, string(r))
+	}
+	view := stripANSI(model.View())
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	simulateKeyPress(model, "enter")
+
+	// Assert - the prompt names its own keys and the filter uses the mode
+	assertStringContains(t, view, "Ctrl+E word")
+	assertIntEqual(t, len(model.visibleRows(model.safeLogs())), 2, "whole-word rows")
+	help := model.config.Keys.helpText(keyContextFilter)
+	assertStringContains(t, help, "Apply filter")
+	assertBoolEqual(t, strings.Contains(help, "Run search"), false, "search labels in filter help")
+
+	// Act - changing the modes in the search prompt
+	simulateKeyPress(model, "/")
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	simulateKeyPress(model, "esc")
+
+	// Assert - the active filter keeps the modes it was applied with
+	assertBoolEqual(t, model.searchOptions.wholeWord, false, "whole word after toggling back")
+	assertIntEqual(t, len(model.visibleRows(model.safeLogs())), 2, "rows after toggling in search")
+}
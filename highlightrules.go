@@ -0,0 +1,470 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*
+Highlight Rules
+
+Named highlight rules color what to watch for on every line, on top of the
+search highlight, while new lines keep arriving:
+
+	* opens the rule prompt:   req-42 @yellow
+	                           timeout @red
+	# lists the rules; enter turns one on or off, d deletes it
+
+The prompt takes a query matched with the search modes (ctrl+r regex, ctrl+t
+case-sensitive, ctrl+e whole word) and an optional trailing @color: a color
+name (see highlightColors), an ANSI number or #rrggbb. Without one the next
+color of the palette is used. Rules added at the prompt and the on/off state of
+every rule are saved in the state file (see state.go), so they come back in
+the next session. Rules can also be declared in the config file:
+
+	{
+	  "settings": {
+	    "highlights": [
+	      { "name": "timeouts", "pattern": "(?i)timed? ?out", "color": "red" },
+	      { "name": "customer", "pattern": "cus_[A-Za-z0-9]+", "color": "cyan" }
+	    ]
+	  }
+	}
+
+Where rules overlap, later rules win and search matches win over every rule.
+*/
+
+// highlightColors names the colors rules can use, in palette order
+var highlightColors = []struct{ name, color string }{
+	{"yellow", "11"},
+	{"red", "9"},
+	{"cyan", "14"},
+	{"green", "10"},
+	{"magenta", "13"},
+	{"blue", "12"},
+	{"orange", "208"},
+	{"pink", "213"},
+}
+
+// hexColorPattern matches #rrggbb colors
+var hexColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// HighlightRule colors every match of a pattern
+type HighlightRule struct {
+	Name     string `json:"name"`
+	Pattern  string `json:"pattern"`            // Go regular expression
+	Color    string `json:"color,omitempty"`    // Color name, ANSI number or #rrggbb; the palette picks one when empty
+	Disabled bool   `json:"disabled,omitempty"` // Start switched off
+
+	re         *regexp.Regexp // Compiled from Pattern
+	fromConfig bool           // Declared in the config file: can be toggled, not deleted
+}
+
+// highlightColor resolves a color name, ANSI number or #rrggbb to a lipgloss color
+func highlightColor(name string) (string, error) {
+	for _, c := range highlightColors {
+		if strings.EqualFold(name, c.name) {
+			return c.color, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 0 && n <= 255 {
+		return name, nil
+	}
+	if hexColorPattern.MatchString(name) {
+		return name, nil
+	}
+	names := make([]string, len(highlightColors))
+	for i, c := range highlightColors {
+		names[i] = c.name
+	}
+	return "", fmt.Errorf("unknown color %q (use %s, an ANSI number or #rrggbb)", name, strings.Join(names, ", "))
+}
+
+// compile checks the rule and prepares its pattern
+func (r *HighlightRule) compile() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("highlights: rule without a name")
+	}
+	if r.Pattern == "" {
+		return fmt.Errorf("highlights: rule %q: empty pattern", r.Name)
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("highlights: rule %q: invalid pattern: %w", r.Name, err)
+	}
+	if r.Color != "" {
+		if _, err := highlightColor(r.Color); err != nil {
+			return fmt.Errorf("highlights: rule %q: %w", r.Name, err)
+		}
+	}
+	r.re = re
+	return nil
+}
+
+// validateHighlightRules rejects broken rules and repeated names
+func validateHighlightRules(rules []HighlightRule) error {
+	seen := make(map[string]bool)
+	for i := range rules {
+		rule := rules[i] // compile would store the pattern in the config's copy
+		if err := rule.compile(); err != nil {
+			return err
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf("highlights: rule %q defined twice", rule.Name)
+		}
+		seen[rule.Name] = true
+	}
+	return nil
+}
+
+// style renders matches of the rule; position picks the palette color when none is set
+func (r *HighlightRule) style(position int, config *UIConfig) lipgloss.Style {
+	if config.NoColor {
+		return lipgloss.NewStyle().Underline(true)
+	}
+	color := highlightColors[position%len(highlightColors)].color
+	if r.Color != "" {
+		color, _ = highlightColor(r.Color) // Checked by compile
+	}
+	return inverseStyle(color, "0")
+}
+
+// colorLabel names the color shown for the rule
+func (r *HighlightRule) colorLabel(position int) string {
+	if r.Color != "" {
+		return r.Color
+	}
+	return highlightColors[position%len(highlightColors)].name
+}
+
+// initialHighlightRules returns the config file rules followed by the saved
+// ones, with the on/off state saved last session
+func initialHighlightRules(config *UIConfig) []HighlightRule {
+	state, _ := loadViewState(config.StatePath) // A broken state file is ignored
+
+	var rules []HighlightRule
+	add := func(rule HighlightRule, fromConfig bool) {
+		if rule.compile() != nil {
+			return // Saved rules are dropped when they no longer compile
+		}
+		for _, existing := range rules {
+			if existing.Name == rule.Name {
+				return
+			}
+		}
+		rule.fromConfig = fromConfig
+		if enabled, ok := state.HighlightToggles[rule.Name]; ok {
+			rule.Disabled = !enabled
+		}
+		rules = append(rules, rule)
+	}
+	for _, rule := range config.Highlights {
+		add(rule, true)
+	}
+	for _, rule := range state.Highlights {
+		add(rule, false)
+	}
+	return rules
+}
+
+// highlightLayer paints every match of a pattern with one style
+type highlightLayer struct {
+	re    *regexp.Regexp
+	style lipgloss.Style
+}
+
+// ruleLayers returns the enabled rules in the order they are painted
+func (m *logModel) ruleLayers() []highlightLayer {
+	var layers []highlightLayer
+	for i := range m.highlightRules {
+		rule := &m.highlightRules[i]
+		if !rule.Disabled {
+			layers = append(layers, highlightLayer{re: rule.re, style: rule.style(i, m.config)})
+		}
+	}
+	return layers
+}
+
+// paintMatches styles the matches of every layer in text, later layers over
+// earlier ones. It reports whether anything matched.
+func paintMatches(text string, layers []highlightLayer) (string, bool) {
+	var owner []int // Layer index per byte, -1 for unstyled text
+	for li, layer := range layers {
+		for _, span := range layer.re.FindAllStringIndex(text, -1) {
+			if span[0] == span[1] {
+				continue
+			}
+			if owner == nil {
+				owner = make([]int, len(text))
+				for i := range owner {
+					owner[i] = -1
+				}
+			}
+			for i := span[0]; i < span[1]; i++ {
+				owner[i] = li
+			}
+		}
+	}
+	if owner == nil {
+		return text, false
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(text); {
+		end := start + 1
+		for end < len(text) && owner[end] == owner[start] {
+			end++
+		}
+		run := text[start:end]
+		if owner[start] < 0 {
+			b.WriteString(run)
+		} else {
+			// Style each line on its own so a match never spans a line break
+			lines := strings.Split(run, "\n")
+			for i, line := range lines {
+				if line != "" {
+					line = layers[owner[start]].style.Render(line)
+				}
+				lines[i] = line
+			}
+			b.WriteString(strings.Join(lines, "\n"))
+		}
+		start = end
+	}
+	return b.String(), true
+}
+
+// rowHighlight returns the highlighted display text of a row: the search
+// highlight when the row matches the search, else the rule matches
+func (m *logModel) rowHighlight(index int, entry *logEntry) (string, bool) {
+	if hl, ok := m.highlighted[index]; ok {
+		return hl, true
+	}
+	layers := m.ruleLayers()
+	if len(layers) == 0 {
+		return "", false
+	}
+	return paintMatches(entry.plainText(), layers)
+}
+
+// parseRuleQuery splits "query @color" and compiles the query with the search modes
+func parseRuleQuery(text string, options searchOptions) (HighlightRule, error) {
+	query, color := strings.TrimSpace(text), ""
+	if at := strings.LastIndex(query, " @"); at >= 0 {
+		query, color = strings.TrimSpace(query[:at]), strings.TrimSpace(query[at+2:])
+	} else if strings.HasPrefix(query, "@") {
+		return HighlightRule{}, fmt.Errorf("missing the text to highlight")
+	}
+	if query == "" {
+		return HighlightRule{}, fmt.Errorf("missing the text to highlight")
+	}
+	if isFieldQuery(query) {
+		return HighlightRule{}, fmt.Errorf("field queries cannot be highlight rules")
+	}
+	if color != "" {
+		if _, err := highlightColor(color); err != nil {
+			return HighlightRule{}, err
+		}
+	}
+	re, err := options.compile(query)
+	if err != nil {
+		return HighlightRule{}, fmt.Errorf("%s", searchErrorText(err))
+	}
+	return HighlightRule{Name: query, Pattern: re.String(), Color: color, re: re}, nil
+}
+
+// handleRulePromptKey edits the highlight rule prompt
+func (m *logModel) handleRulePromptKey(key string) tea.Cmd {
+	action, pending := m.config.Keys.resolve(keyContextPrompt, m.pendingKeys, key)
+	m.pendingKeys = pending
+
+	switch action {
+	case actionSubmit:
+		if m.ruleError != "" {
+			return nil // Keep the prompt open until the rule compiles
+		}
+		m.ruleMode = false
+		if strings.TrimSpace(m.ruleQuery) == "" {
+			return nil
+		}
+		return m.addHighlightRule()
+	case actionCancel:
+		m.ruleMode = false
+		m.ruleError = ""
+		return nil
+	}
+	cmd := m.editPrompt(&m.ruleQuery, action, key, pending)
+	m.checkRuleQuery()
+	return cmd
+}
+
+// openRulePrompt starts typing a new highlight rule
+func (m *logModel) openRulePrompt() {
+	m.ruleMode = true
+	m.ruleQuery = ""
+	m.ruleError = ""
+}
+
+// checkRuleQuery compiles the rule being typed so errors show before it is added
+func (m *logModel) checkRuleQuery() {
+	m.ruleError = ""
+	if strings.TrimSpace(m.ruleQuery) == "" {
+		return
+	}
+	if _, err := parseRuleQuery(m.ruleQuery, m.searchOptions); err != nil {
+		m.ruleError = err.Error()
+	}
+}
+
+// addHighlightRule adds the typed rule, or replaces the saved rule of the same name
+func (m *logModel) addHighlightRule() tea.Cmd {
+	rule, _ := parseRuleQuery(m.ruleQuery, m.searchOptions) // Checked while typing
+	position := len(m.highlightRules)
+	replaced := false
+	for i := range m.highlightRules {
+		if m.highlightRules[i].Name != rule.Name {
+			continue
+		}
+		if m.highlightRules[i].fromConfig {
+			m.formatStatusMsg = fmt.Sprintf("Highlight rule %q comes from the config file", rule.Name)
+			return clearFormatStatusCmd()
+		}
+		m.highlightRules[i], position, replaced = rule, i, true
+	}
+	if !replaced {
+		m.highlightRules = append(m.highlightRules, rule)
+	}
+	m.applyHighlights()
+
+	m.formatStatusMsg = fmt.Sprintf("Highlighting %s in %s", rule.Name, rule.colorLabel(position))
+	return tea.Batch(clearFormatStatusCmd(), m.saveHighlightRules())
+}
+
+// saveHighlightRules persists the prompt rules and every rule's on/off state in the background
+func (m *logModel) saveHighlightRules() tea.Cmd {
+	path := m.config.StatePath
+	var saved []HighlightRule
+	toggles := make(map[string]bool)
+	for _, rule := range m.highlightRules {
+		if rule.fromConfig {
+			toggles[rule.Name] = !rule.Disabled
+			continue
+		}
+		saved = append(saved, HighlightRule{Name: rule.Name, Pattern: rule.Pattern, Color: rule.Color, Disabled: rule.Disabled})
+	}
+	return func() tea.Msg {
+		err := updateViewState(path, func(state *viewState) {
+			state.Highlights = saved
+			state.HighlightToggles = toggles
+			if len(toggles) == 0 {
+				state.HighlightToggles = nil
+			}
+		})
+		if err != nil {
+			return statusMsg(fmt.Sprintf("Failed to save highlight rules: %v", err))
+		}
+		return nil
+	}
+}
+
+// rulePicker is the overlay that lists the highlight rules
+type rulePicker struct {
+	cursor int
+}
+
+// openRulePicker lists the highlight rules
+func (m *logModel) openRulePicker() {
+	if len(m.highlightRules) == 0 {
+		m.formatStatusMsg = fmt.Sprintf("No highlight rules (press %s to add one)",
+			m.config.Keys.hint(keyContextViewer, actionHighlight))
+		return
+	}
+	m.rulePicker = &rulePicker{}
+}
+
+// handleRulePickerKey moves through the rules, toggles or deletes one, or closes the list
+func (m *logModel) handleRulePickerKey(action string) tea.Cmd {
+	p := m.rulePicker
+	switch action {
+	case actionUp:
+		p.cursor = max(0, p.cursor-1)
+	case actionDown:
+		p.cursor = min(len(m.highlightRules)-1, p.cursor+1)
+	case actionDetail:
+		rule := &m.highlightRules[p.cursor]
+		rule.Disabled = !rule.Disabled
+		m.applyHighlights()
+	case actionDeleteRule:
+		if m.highlightRules[p.cursor].fromConfig {
+			m.formatStatusMsg = "Rules from the config file can only be turned off"
+			return clearFormatStatusCmd()
+		}
+		m.highlightRules = append(m.highlightRules[:p.cursor:p.cursor], m.highlightRules[p.cursor+1:]...)
+		m.applyHighlights()
+		if len(m.highlightRules) == 0 {
+			m.rulePicker = nil
+			return m.saveHighlightRules()
+		}
+		p.cursor = min(p.cursor, len(m.highlightRules)-1)
+	case actionRuleList, actionClearSearch, actionBack:
+		m.rulePicker = nil
+		return m.saveHighlightRules()
+	}
+	return nil
+}
+
+// renderRulePicker renders the highlight rule list overlay
+func (m *logModel) renderRulePicker() string {
+	p := m.rulePicker
+	height := max(1, m.height-uiReservedHeight-4)
+	start := max(0, min(p.cursor-height/2, len(m.highlightRules)-height))
+	end := min(len(m.highlightRules), start+height)
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		rule := &m.highlightRules[i]
+		mark := "[x]"
+		if rule.Disabled {
+			mark = "[ ]"
+		}
+		sample := rule.style(i, m.config).Render(" " + rule.colorLabel(i) + " ")
+		line := fmt.Sprintf("%s %s %s", mark, sample, rule.Name)
+		if rule.fromConfig {
+			line += m.config.MutedStyle().Render(" (config)")
+		}
+		if i == p.cursor {
+			line = "▌ " + line
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+
+	keys := m.config.Keys
+	footer := fmt.Sprintf("%s on/off · %s delete · %s or %s done",
+		keys.hint(keyContextViewer, actionDetail), keys.hint(keyContextViewer, actionDeleteRule),
+		keys.hint(keyContextViewer, actionRuleList), keys.hint(keyContextViewer, actionClearSearch))
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(m.config.Colors.BorderColor)).
+		Padding(0, 1).
+		Render(m.config.HeaderStyle().Render("Highlight rules") + "\n" + b.String() + m.config.MutedStyle().Render(footer))
+}
+
+// rulePromptStatus renders the prompt line of the status bar
+func (m *logModel) rulePromptStatus() string {
+	prompt := m.config.SearchStyle().Render("Highlight [" + m.searchOptions.label() + "]: " + m.ruleQuery + "_")
+	if m.ruleError != "" {
+		return prompt + "  " + m.config.ErrorStyle().Render("✗ "+m.ruleError)
+	}
+	names := make([]string, len(highlightColors))
+	for i, c := range highlightColors {
+		names[i] = c.name
+	}
+	return prompt + "  " + m.config.MutedStyle().Render("@color picks a color ("+strings.Join(names, ", ")+")")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestParseRuleQuery(t *testing.T) {
+	// Act
+	rule, err := parseRuleQuery("Timeout @red", searchOptions{})
+
+	// Assert
+	assertNoError(t, err)
+	assertStringEqual(t, rule.Name, "Timeout")
+	assertStringEqual(t, rule.Color, "red")
+	assertBoolEqual(t, rule.re.MatchString("read TIMEOUT"), true, "case-insensitive literal")
+	assertNoError(t, rule.compile())
+
+	tests := []struct {
+		query   string
+		options searchOptions
+		want    string
+	}{
+		{"@red", searchOptions{}, "missing the text"},
+		{"req-42 @plaid", searchOptions{}, `unknown color "plaid"`},
+		{"(", searchOptions{regex: true}, "missing closing )"},
+		{":status>=500", searchOptions{}, "field queries"},
+	}
+	for _, tt := range tests {
+		_, err := parseRuleQuery(tt.query, tt.options)
+		assertError(t, err, tt.want)
+	}
+}
+
+func TestPaintMatches(t *testing.T) {
+	// Arrange - bracket styles make the painted spans visible without colors
+	layer := func(pattern, mark string) highlightLayer {
+		return highlightLayer{re: regexp.MustCompile(pattern), style: lipgloss.NewStyle().SetString(mark)}
+	}
+	layers := []highlightLayer{layer("timeout after", "A"), layer("after 5s", "B")}
+
+	// Act
+	painted, ok := paintMatches("request timeout after 5s", layers)
+	_, none := paintMatches("all good", layers)
+
+	// Assert - the later layer wins where they overlap
+	assertBoolEqual(t, ok, true, "matched")
+	assertBoolEqual(t, none, false, "nothing matched")
+	assertStringEqual(t, painted, "request A timeout B after 5s")
+
+	multiline, _ := paintMatches("a\nb", []highlightLayer{layer("a\nb", "X")})
+	assertStringEqual(t, multiline, "X a\nX b")
+}
+
+func TestHighlightRulesInViewer(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	model.config.StatePath = filepath.Join(t.TempDir(), "state.json")
+	model.config.Highlights = []HighlightRule{{Name: "customer", Pattern: `cus_\w+`, Color: "cyan"}}
+	model.highlightRules = initialHighlightRules(model.config)
+	model.store.Append(makeLogEntry(testTimestamp, "GET /pay cus_123 timeout", model.config))
+	model.followMode = false
+	simulateWindowResize(model, 120, 30)
+
+	// Act - add a rule at the prompt
+	simulateKeyPress(model, "*")
+	for _, r := range "timeout @red" {
+		simulateKeyPress(model, string(r))
+	}
+	simulateKeyPress(model, "enter")
+
+	// Assert
+	assertIntEqual(t, len(model.highlightRules), 2, "rules")
+	assertStringContains(t, model.formatStatusMsg, "Highlighting timeout in red")
+	logs := model.safeLogs()
+	if _, ok := model.rowHighlight(0, &logs[0]); !ok {
+		t.Fatal("the line should be highlighted by the rules")
+	}
+	model.saveHighlightRules()()
+
+	// Act - lines arriving later are highlighted too
+	model.store.Append(makeLogEntry(testTimestamp, "second timeout", model.config))
+	logs = model.safeLogs()
+	_, ok := model.rowHighlight(1, &logs[1])
+	assertBoolEqual(t, ok, true, "new line highlighted")
+
+	// Act - turn the config rule off in the list and close it
+	simulateKeyPress(model, "#")
+	view := stripANSI(model.View())
+	assertStringContains(t, view, "Highlight rules")
+	assertStringContains(t, view, "customer (config)")
+	simulateKeyPress(model, "enter")
+	simulateKeyPress(model, "j")
+	simulateKeyPress(model, "d") // Removes the prompt rule
+	_, cmd := simulateKeyPress(model, "#")
+	if cmd == nil {
+		t.Fatal("closing the list should save the rules")
+	}
+	cmd()
+
+	// Assert - the state comes back in the next session
+	if model.rulePicker != nil {
+		t.Fatal("rule list should be closed")
+	}
+	rules := initialHighlightRules(model.config)
+	assertIntEqual(t, len(rules), 1, "saved rules")
+	assertBoolEqual(t, rules[0].Disabled, true, "config rule stays off")
+	_, ok = model.rowHighlight(1, &logs[1])
+	assertBoolEqual(t, ok, false, "no enabled rule left")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*
+JSON Formatting
+
+Formatted mode indents JSON from the decoded token tree (see jsontree.go)
+rather than from a Go map, so keys stay in the order the event wrote them.
+Keys, strings, numbers, booleans, null and punctuation are styled with the
+JSON colors of the theme:
+
+	{
+	  "level": "info",
+	  "latency": 12.5,
+	  "cached": false,
+	  "user": null
+	}
+
+String values that hold JSON themselves are written as structure, up to
+maxNestedJSONDepth levels. Only escape sequences are added around the plain
+text, so search matches the same characters it would without colors.
+*/
+
+// jsonFormatter writes an indented JSON document
+type jsonFormatter struct {
+	b      strings.Builder
+	indent string
+	styles map[jsonKind]lipgloss.Style // nil writes plain text
+	key    lipgloss.Style
+	punct  lipgloss.Style
+}
+
+// indentJSON renders a decoded document as indented JSON, colored when the config colorizes fields
+func indentJSON(root *jsonNode, indent string, config *UIConfig) string {
+	f := &jsonFormatter{indent: indent}
+	if config != nil && config.ColorizeFields {
+		colors := config.Colors
+		f.styles = map[jsonKind]lipgloss.Style{
+			jsonString: config.FieldStyle(colors.JSONStringColor),
+			jsonNumber: config.FieldStyle(colors.JSONNumberColor),
+			jsonBool:   config.FieldStyle(colors.JSONBoolColor),
+			jsonNull:   config.FieldStyle(colors.JSONNullColor),
+		}
+		f.key = config.FieldStyle(colors.JSONKeyColor)
+		f.punct = config.FieldStyle(colors.JSONPunctuationColor)
+	}
+	f.write(root, 0, 0)
+	return f.b.String()
+}
+
+// styled renders text with style unless the formatter writes plain text
+func (f *jsonFormatter) styled(style lipgloss.Style, text string) string {
+	if f.styles == nil {
+		return text
+	}
+	return style.Render(text)
+}
+
+// write appends a value at the given indentation level; nested counts the
+// JSON-in-string levels already decoded
+func (f *jsonFormatter) write(n *jsonNode, level, nested int) {
+	switch n.kind {
+	case jsonObject, jsonArray:
+		open, close := "{", "}"
+		if n.kind == jsonArray {
+			open, close = "[", "]"
+		}
+		if len(n.children) == 0 {
+			f.b.WriteString(f.styled(f.punct, open+close))
+			return
+		}
+		f.b.WriteString(f.styled(f.punct, open) + "\n")
+		for i, child := range n.children {
+			f.b.WriteString(strings.Repeat(f.indent, level+1))
+			if n.kind == jsonObject {
+				f.b.WriteString(f.styled(f.key, quoteJSON(child.key)) + f.styled(f.punct, ":") + " ")
+			}
+			f.write(child, level+1, nested)
+			if i < len(n.children)-1 {
+				f.b.WriteString(f.styled(f.punct, ","))
+			}
+			f.b.WriteString("\n")
+		}
+		f.b.WriteString(strings.Repeat(f.indent, level) + f.styled(f.punct, close))
+	case jsonString:
+		if nested < maxNestedJSONDepth {
+			if inner := nestedJSONTree(n.value); inner != nil {
+				f.write(inner, level, nested+1)
+				return
+			}
+		}
+		f.b.WriteString(f.styled(f.styles[jsonString], quoteJSON(n.value)))
+	default:
+		f.b.WriteString(f.styled(f.styles[n.kind], n.value))
+	}
+}
+
+// nestedJSONTree decodes a string value that holds a JSON object or array, or returns nil
+func nestedJSONTree(s string) *jsonNode {
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) < 2 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil
+	}
+	root, err := parseJSONTree(trimmed)
+	if err != nil {
+		return nil
+	}
+	return root
+}
+
+// quoteJSON encodes a string as JSON, leaving <, > and & readable
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// interfaceValue converts the node to the values encoding/json decodes into
+func (n *jsonNode) interfaceValue() interface{} {
+	switch n.kind {
+	case jsonObject:
+		object := make(map[string]interface{}, len(n.children))
+		for _, child := range n.children {
+			object[child.key] = child.interfaceValue()
+		}
+		return object
+	case jsonArray:
+		array := make([]interface{}, len(n.children))
+		for i, child := range n.children {
+			array[i] = child.interfaceValue()
+		}
+		return array
+	case jsonString:
+		return n.value
+	case jsonNumber:
+		f, _ := strconv.ParseFloat(n.value, 64)
+		return f
+	case jsonBool:
+		return n.value == "true"
+	}
+	return nil
+}
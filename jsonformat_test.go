@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndentJSON(t *testing.T) {
+	t.Run("KeepsKeyOrder", func(t *testing.T) {
+		// Arrange
+		input := `{"zeta":1,"alpha":{"b":true,"a":null},"list":[],"obj":{},"html":"<a&b>"}`
+
+		// Act
+		got := indentTestJSON(t, input)
+
+		// Assert
+		want := "{\n" +
+			"  \"zeta\": 1,\n" +
+			"  \"alpha\": {\n" +
+			"    \"b\": true,\n" +
+			"    \"a\": null\n" +
+			"  },\n" +
+			"  \"list\": [],\n" +
+			"  \"obj\": {},\n" +
+			"  \"html\": \"<a&b>\"\n" +
+			"}"
+		assertStringEqual(t, got, want)
+	})
+
+	t.Run("DecodesNestedJSON", func(t *testing.T) {
+		got := indentTestJSON(t, `{"msg":"{\"z\":1,\"a\":[2]}"}`)
+		assertStringEqual(t, got, "{\n  \"msg\": {\n    \"z\": 1,\n    \"a\": [\n      2\n    ]\n  }\n}")
+	})
+
+	t.Run("ColorsEveryToken", func(t *testing.T) {
+		// Arrange
+		cfg := createTestConfig()
+		root, err := parseJSONTree(`{"s":"x","n":1.5,"b":false,"z":null}`)
+		assertNoError(t, err)
+
+		// Act
+		got := indentJSON(root, "  ", cfg)
+
+		// Assert - styled tokens, same text underneath
+		assertStringContains(t, got, cfg.FieldStyle(cfg.Colors.JSONKeyColor).Render(`"s"`))
+		assertStringContains(t, got, cfg.FieldStyle(cfg.Colors.JSONStringColor).Render(`"x"`))
+		assertStringContains(t, got, cfg.FieldStyle(cfg.Colors.JSONNumberColor).Render("1.5"))
+		assertStringContains(t, got, cfg.FieldStyle(cfg.Colors.JSONBoolColor).Render("false"))
+		assertStringContains(t, got, cfg.FieldStyle(cfg.Colors.JSONNullColor).Render("null"))
+		assertStringContains(t, got, cfg.FieldStyle(cfg.Colors.JSONPunctuationColor).Render("{"))
+		assertStringEqual(t, stripANSI(got), indentJSON(root, "  ", nil))
+	})
+}
+
+// indentTestJSON decodes a document and indents it without colors
+func indentTestJSON(t *testing.T, input string) string {
+	t.Helper()
+	root, err := parseJSONTree(input)
+	assertNoError(t, err)
+	if err != nil {
+		return input
+	}
+	return indentJSON(root, "  ", nil)
+}
+
+func TestJSONSearchMatchesPlainText(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	model.store.Append(makeLogEntry(testTimestamp, `{"user":"bob","status":"ok"}`, model.config))
+	simulateWindowResize(model, 120, 30)
+
+	// Act - the query spans a key, punctuation and a value
+	simulateKeyPress(model, "/")
+	for _, r := range `"user": "bob"` {
+		simulateKeyPress(model, string(r))
+	}
+	simulateKeyPress(model, "enter")
+
+	// Assert
+	assertIntEqual(t, len(model.matches), 1, "matches")
+	assertBoolEqual(t, strings.Contains(stripANSI(model.safeLogs()[0].Raw), `"user": "bob",`), true, "plain text")
+}
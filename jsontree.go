@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+JSON Tree
+
+The detail view shows JSON events as a tree instead of indented text:
+
+	▾ {} 5 keys
+	  eventName: "GetObject"
+	  ▸ userIdentity: {} 3 keys
+	  ▾ resources: [] 1 item
+	    ▸ [0]: {} 2 keys
+
+Nodes keep the key order of the event. The tree is decoded with a streaming
+decoder, so it works for events of any size; only containers near the root
+start expanded. Values are colored by type, and the jq-style path of the node
+under the cursor (".resources[0].ARN") or its value can be copied.
+*/
+
+// jsonKind is the type of a JSON value
+type jsonKind int
+
+const (
+	jsonObject jsonKind = iota
+	jsonArray
+	jsonString
+	jsonNumber
+	jsonBool
+	jsonNull
+)
+
+// jsonTreeExpandDepth and jsonTreeExpandLimit bound the containers expanded initially
+const (
+	jsonTreeExpandDepth = 2
+	jsonTreeExpandLimit = 100
+)
+
+// jsonNode is one value of a JSON document
+type jsonNode struct {
+	key      string // Object key; "" for array items and the root
+	index    int    // Array index; -1 otherwise
+	kind     jsonKind
+	value    string // Scalars: the string contents or the literal
+	children []*jsonNode
+	parent   *jsonNode
+	depth    int
+	expanded bool
+}
+
+// parseJSONTree decodes a JSON document into a tree, keeping key order
+func parseJSONTree(data string) (*jsonNode, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	root, err := decodeJSONNode(decoder, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return root, nil
+}
+
+// decodeJSONNode reads one value from the decoder
+func decodeJSONNode(decoder *json.Decoder, parent *jsonNode, depth int) (*jsonNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &jsonNode{index: -1, parent: parent, depth: depth}
+	switch t := token.(type) {
+	case json.Delim:
+		node.kind = jsonObject
+		if t == '[' {
+			node.kind = jsonArray
+		}
+		for decoder.More() {
+			key := ""
+			if node.kind == jsonObject {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = keyToken.(string)
+			}
+			child, err := decodeJSONNode(decoder, node, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			child.key = key
+			if node.kind == jsonArray {
+				child.index = len(node.children)
+			}
+			node.children = append(node.children, child)
+		}
+		if _, err := decoder.Token(); err != nil { // Closing delimiter
+			return nil, err
+		}
+		node.expanded = depth < jsonTreeExpandDepth && len(node.children) <= jsonTreeExpandLimit
+	case string:
+		node.kind, node.value = jsonString, t
+	case json.Number:
+		node.kind, node.value = jsonNumber, t.String()
+	case bool:
+		node.kind, node.value = jsonBool, strconv.FormatBool(t)
+	case nil:
+		node.kind, node.value = jsonNull, "null"
+	}
+	return node, nil
+}
+
+// decodeNestedStrings replaces string values that hold JSON with their tree,
+// as formatted mode does; nested counts the levels already decoded
+func (n *jsonNode) decodeNestedStrings(nested int) {
+	for i, child := range n.children {
+		if child.kind == jsonString && nested < maxNestedJSONDepth {
+			if inner := nestedJSONTree(child.value); inner != nil {
+				inner.key, inner.index = child.key, child.index
+				inner.reparent(n)
+				n.children[i] = inner
+				inner.decodeNestedStrings(nested + 1)
+				continue
+			}
+		}
+		child.decodeNestedStrings(nested)
+	}
+}
+
+// reparent attaches a decoded subtree below parent, fixing its depths
+func (n *jsonNode) reparent(parent *jsonNode) {
+	n.parent, n.depth = parent, parent.depth+1
+	if n.container() {
+		n.expanded = n.depth < jsonTreeExpandDepth && len(n.children) <= jsonTreeExpandLimit
+	}
+	for _, child := range n.children {
+		child.reparent(n)
+	}
+}
+
+// container reports whether the node is an object or array
+func (n *jsonNode) container() bool {
+	return n.kind == jsonObject || n.kind == jsonArray
+}
+
+// identifierRegex matches keys that need no quoting in a path
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// path returns the jq-style path of the node, "." for the root
+func (n *jsonNode) path() string {
+	var parts []string
+	for node := n; node.parent != nil; node = node.parent {
+		switch {
+		case node.parent.kind == jsonArray:
+			parts = append(parts, fmt.Sprintf("[%d]", node.index))
+		case identifierRegex.MatchString(node.key):
+			parts = append(parts, "."+node.key)
+		default:
+			parts = append(parts, "["+strconv.Quote(node.key)+"]")
+		}
+	}
+	if len(parts) == 0 {
+		return "."
+	}
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		b.WriteString(parts[i])
+	}
+	path := b.String()
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return path
+}
+
+// json re-encodes the node compactly, keeping key order
+func (n *jsonNode) json() string {
+	var b bytes.Buffer
+	n.writeJSON(&b)
+	return b.String()
+}
+
+func (n *jsonNode) writeJSON(b *bytes.Buffer) {
+	switch n.kind {
+	case jsonObject, jsonArray:
+		open, close := byte('{'), byte('}')
+		if n.kind == jsonArray {
+			open, close = '[', ']'
+		}
+		b.WriteByte(open)
+		for i, child := range n.children {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if n.kind == jsonObject {
+				key, _ := json.Marshal(child.key)
+				b.Write(key)
+				b.WriteByte(':')
+			}
+			child.writeJSON(b)
+		}
+		b.WriteByte(close)
+	case jsonString:
+		value, _ := json.Marshal(n.value)
+		b.Write(value)
+	default:
+		b.WriteString(n.value)
+	}
+}
+
+// copyText is what copying the value of the node yields: the raw string, or JSON
+func (n *jsonNode) copyText() string {
+	if n.kind == jsonString {
+		return n.value
+	}
+	return n.json()
+}
+
+// jsonTree is the navigable state of a tree in the detail view
+type jsonTree struct {
+	root    *jsonNode
+	visible []*jsonNode // Nodes shown, in order; rebuilt when folding changes
+	cursor  int
+}
+
+// newJSONTree wraps a decoded document
+func newJSONTree(root *jsonNode) *jsonTree {
+	t := &jsonTree{root: root}
+	t.refresh()
+	return t
+}
+
+// refresh recomputes the visible nodes, keeping the cursor on the same node
+func (t *jsonTree) refresh() {
+	var current *jsonNode
+	if t.cursor < len(t.visible) {
+		current = t.visible[t.cursor]
+	}
+
+	t.visible = t.visible[:0]
+	var walk func(n *jsonNode)
+	walk = func(n *jsonNode) {
+		t.visible = append(t.visible, n)
+		if n.expanded {
+			for _, child := range n.children {
+				walk(child)
+			}
+		}
+	}
+	walk(t.root)
+
+	t.cursor = 0
+	for i, n := range t.visible {
+		if n == current {
+			t.cursor = i
+		}
+	}
+}
+
+// selected returns the node under the cursor
+func (t *jsonTree) selected() *jsonNode {
+	return t.visible[t.cursor]
+}
+
+// move moves the cursor by delta visible nodes
+func (t *jsonTree) move(delta int) {
+	t.cursor = max(0, min(len(t.visible)-1, t.cursor+delta))
+}
+
+// expand opens the container under the cursor, or steps into an open one
+func (t *jsonTree) expand() {
+	n := t.selected()
+	if !n.container() {
+		return
+	}
+	if n.expanded {
+		t.move(1)
+		return
+	}
+	n.expanded = true
+	t.refresh()
+}
+
+// collapse closes the container under the cursor, or moves to its parent
+func (t *jsonTree) collapse() {
+	n := t.selected()
+	if n.container() && n.expanded {
+		n.expanded = false
+		t.refresh()
+		return
+	}
+	t.jumpTo(n.parent)
+}
+
+// toggle opens or closes the container under the cursor
+func (t *jsonTree) toggle() {
+	if n := t.selected(); n.container() {
+		n.expanded = !n.expanded
+		t.refresh()
+	}
+}
+
+// sibling moves to the next (delta 1) or previous (-1) key of the same container
+func (t *jsonTree) sibling(delta int) {
+	n := t.selected()
+	if n.parent == nil {
+		return
+	}
+	siblings := n.parent.children
+	for i, s := range siblings {
+		if s == n && i+delta >= 0 && i+delta < len(siblings) {
+			t.jumpTo(siblings[i+delta])
+			return
+		}
+	}
+}
+
+// jumpTo moves the cursor to a visible node
+func (t *jsonTree) jumpTo(target *jsonNode) {
+	for i, n := range t.visible {
+		if n == target {
+			t.cursor = i
+			return
+		}
+	}
+}
+
+// renderJSONNode renders one tree line of at most width columns. Plain lines
+// (the cursor line) are left uncolored so the cursor style shows.
+func renderJSONNode(n *jsonNode, config *UIConfig, width int, plain bool) string {
+	colors := config.Colors
+	style := func(color, text string) string {
+		if plain || !config.ColorizeFields {
+			return text
+		}
+		return config.FieldStyle(color).Render(text)
+	}
+
+	prefix := strings.Repeat("  ", n.depth)
+	if n.container() {
+		if n.expanded {
+			prefix += "▾ "
+		} else {
+			prefix += "▸ "
+		}
+	}
+	label, labelColor := "", colors.JSONKeyColor
+	switch {
+	case n.parent != nil && n.parent.kind == jsonArray:
+		label, labelColor = fmt.Sprintf("[%d]", n.index), colors.SizeColor
+	case n.parent != nil:
+		label = n.key
+	}
+
+	var value, valueColor string
+	switch n.kind {
+	case jsonObject:
+		value, valueColor = "{} "+pluralize(len(n.children), "key"), colors.SizeColor
+	case jsonArray:
+		value, valueColor = "[] "+pluralize(len(n.children), "item"), colors.SizeColor
+	case jsonString:
+		value, valueColor = strconv.Quote(n.value), colors.JSONStringColor
+	case jsonNumber:
+		value, valueColor = n.value, colors.JSONNumberColor
+	case jsonBool:
+		value, valueColor = n.value, colors.JSONBoolColor
+	case jsonNull:
+		value, valueColor = n.value, colors.JSONNullColor
+	}
+
+	// Long values are cut before styling so no escape sequence is split
+	if label != "" {
+		label += ": "
+	}
+	room := max(1, width-len([]rune(prefix))-len([]rune(label)))
+	if runes := []rune(value); len(runes) > room {
+		value = string(runes[:room-1]) + "…"
+	}
+
+	line := prefix
+	if label != "" {
+		line += style(labelColor, strings.TrimSuffix(label, ": ")) + ": "
+	}
+	return line + style(valueColor, value)
+}
+
+// pluralize renders "1 key", "3 keys"
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestJSONTree(t *testing.T) {
+	t.Run("PathsAndValues", func(t *testing.T) {
+		// Arrange
+		root, err := parseJSONTree(`{"z":1,"http":{"status":200,"ok":true},"items":[{"id":"a"}],"a b":null}`)
+		assertNoError(t, err)
+
+		// Act
+		status := root.children[1].children[0]
+		item := root.children[2].children[0]
+		spaced := root.children[3]
+
+		// Assert - key order is kept
+		assertStringEqual(t, root.json(), `{"z":1,"http":{"status":200,"ok":true},"items":[{"id":"a"}],"a b":null}`)
+		assertStringEqual(t, status.path(), ".http.status")
+		assertStringEqual(t, item.children[0].path(), ".items[0].id")
+		assertStringEqual(t, spaced.path(), `.["a b"]`)
+		assertStringEqual(t, root.path(), ".")
+		assertStringEqual(t, item.copyText(), `{"id":"a"}`)
+		assertStringEqual(t, item.children[0].copyText(), "a")
+	})
+
+	t.Run("Navigation", func(t *testing.T) {
+		// Arrange
+		root, err := parseJSONTree(`{"a":{"b":{"c":1}},"d":[1,2],"e":"x"}`)
+		assertNoError(t, err)
+		tree := newJSONTree(root)
+
+		// Assert - containers below the second level start collapsed
+		assertIntEqual(t, len(tree.visible), 7, "root, a, b, d, 1, 2, e")
+
+		// Act - next key skips the children of "a"
+		tree.move(1)
+		tree.sibling(1)
+		assertStringEqual(t, tree.selected().path(), ".d")
+
+		tree.collapse()
+		assertIntEqual(t, len(tree.visible), 5, "d collapsed")
+		tree.sibling(-1)
+		tree.expand() // Into the open "a"
+		tree.expand() // Opens "b"
+		assertStringEqual(t, tree.selected().path(), ".a.b")
+		assertIntEqual(t, len(tree.visible), 6, "b expanded")
+
+		tree.move(1)
+		tree.collapse()
+		assertStringEqual(t, tree.selected().path(), ".a.b")
+	})
+
+	t.Run("LargeEvent", func(t *testing.T) {
+		// Arrange - far larger than the formatter's embedded JSON limit
+		var items []string
+		for i := range 2000 {
+			items = append(items, fmt.Sprintf(`{"id":%d,"name":"item-%d"}`, i, i))
+		}
+		message := `{"items":[` + strings.Join(items, ",") + `]}`
+
+		// Act
+		root, err := parseJSONTree(message)
+
+		// Assert - the big array starts collapsed
+		assertNoError(t, err)
+		tree := newJSONTree(root)
+		assertIntEqual(t, len(tree.visible), 2, "root and items")
+		assertStringEqual(t, root.json(), message)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := parseJSONTree(`{"a":1} trailing`)
+		if err == nil {
+			t.Error("trailing data should be rejected")
+		}
+	})
+}
+
+func TestJSONDetailView(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	model.store.Append(makeLogEntry(testTimestamp, `{"level":"error","http":{"status":503}}`, model.config))
+	model.followMode = false
+	simulateWindowResize(model, 160, 40)
+
+	// Act
+	simulateKeyPress(model, "enter")
+	simulateKeyPress(model, "tab") // From the root: no siblings
+	simulateKeyPress(model, "j")
+	simulateKeyPress(model, "tab")
+	simulateKeyPress(model, "enter")
+
+	// Assert
+	if model.detail == nil || model.detail.tree == nil {
+		t.Fatal("JSON event should open as a tree")
+	}
+	assertStringEqual(t, model.detail.tree.selected().path(), ".http")
+	view := model.View()
+	assertStringContains(t, view, "▸ http: {} 1 key")
+	assertStringContains(t, view, ".http · Lines 1-3 of 3")
+	if _, cmd := simulateKeyPress(model, "Y"); cmd == nil {
+		t.Error("Y should copy the path")
+	}
+
+	simulateKeyPress(model, "q")
+	if model.detail != nil {
+		t.Error("q should close the detail view")
+	}
+}
+
+func TestDetailViewExtractedJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		wantView string
+	}{
+		{
+			name:     "timestamp prefix",
+			message:  `2026-01-02T03:04:05Z INFO {"level":"info","http":{"status":200}}`,
+			wantView: "http: {} 1 key",
+		},
+		{
+			name:     "container log line",
+			message:  `{"log":"{\"level\":\"warn\",\"user\":{\"id\":7}}","stream":"stdout","container_name":"api"}`,
+			wantView: "log: {} 2 keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config := createTestConfig()
+			entry := makeLogEntry(testTimestamp, tt.message, config)
+
+			// Act
+			d := newDetailView(&entry, config)
+
+			// Assert
+			if d.tree == nil {
+				t.Fatal("extracted JSON should open as a tree")
+			}
+			lines := make([]string, len(d.tree.visible))
+			for i, node := range d.tree.visible {
+				lines[i] = renderJSONNode(node, config, 80, true)
+			}
+			assertStringContains(t, strings.Join(lines, "\n"), tt.wantView)
+		})
+	}
+
+	// Arrange - text without JSON
+	config := createTestConfig()
+	entry := makeLogEntry(testTimestamp, `plain text without JSON`, config)
+
+	// Act & Assert
+	if d := newDetailView(&entry, config); d.tree != nil {
+		t.Error("text without JSON should be shown as lines")
+	}
+}
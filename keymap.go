@@ -0,0 +1,484 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+Key Bindings
+
+Every key the TUIs react to is looked up in a keyMap, grouped by context:
+
+	viewer   - the log viewer
+	prompt   - the search prompt inside the viewer
+	filter   - the filter prompt inside the viewer
+	detail   - the detail view of one log event
+	selector - the log group selection screen
+
+Bindings can be replaced from the config file, per context and action. A binding
+is a list of alternatives; each alternative is one key or a space-separated
+sequence of keys (e.g. "g g"):
+
+	{
+	  "keys": {
+	    "viewer":   { "top": ["g g", "home"], "quit": ["ctrl+q"] },
+	    "selector": { "region": ["ctrl+r"] }
+	  }
+	}
+
+Key names follow Bubble Tea (KeyMsg.String()): "a", "G", "ctrl+f", "pgup", "esc", ...
+The help overlay (?), the controls bar and the startup hints are generated
+from the active key map, so they always show the real bindings.
+*/
+
+// Key map contexts
+const (
+	keyContextViewer   = "viewer"
+	keyContextPrompt   = "prompt"
+	keyContextFilter   = "filter"
+	keyContextDetail   = "detail"
+	keyContextSelector = "selector"
+)
+
+// Viewer actions
+const (
+	actionQuit        = "quit"
+	actionHelp        = "help"
+	actionUp          = "up"
+	actionDown        = "down"
+	actionPageUp      = "pageUp"
+	actionPageDown    = "pageDown"
+	actionTop         = "top"
+	actionBottom      = "bottom"
+	actionSearch      = "search"
+	actionClearSearch = "clearSearch"
+	actionNextMatch   = "nextMatch"
+	actionPrevMatch   = "prevMatch"
+	actionFormat      = "format"
+	actionFollow      = "follow"
+	actionTheme       = "theme"
+	actionLevel       = "level"
+	actionInvocations = "invocations"
+	actionRejects     = "rejects"
+	actionDetail      = "detail"
+	actionFold        = "fold"
+	actionFoldAll     = "foldAll"
+	actionColumns     = "columns"
+	actionPickColumns = "pickColumns"
+	actionMetrics     = "metrics"
+	actionTimestamps  = "timestamps"
+	actionIngestion   = "ingestion"
+	actionReveal      = "reveal"
+	actionFilter      = "filter"
+	actionMoreContext = "moreContext"
+	actionLessContext = "lessContext"
+	actionHighlight   = "highlight"
+	actionRuleList    = "highlightRules"
+	actionDeleteRule  = "deleteRule"
+	actionHistory     = "history"
+	actionCopy        = "copy"
+	actionBack        = "back"
+)
+
+// Prompt actions
+const (
+	actionSubmit      = "submit"
+	actionCancel      = "cancel"
+	actionDeleteChar  = "deleteChar"
+	actionDeleteWord  = "deleteWord"
+	actionSearchRegex = "regex"
+	actionSearchCase  = "caseSensitive"
+	actionSearchWord  = "wholeWord"
+)
+
+// Detail view actions
+const (
+	actionExpand     = "expand"
+	actionCollapse   = "collapse"
+	actionToggleNode = "toggleNode"
+	actionNextKey    = "nextKey"
+	actionPrevKey    = "prevKey"
+	actionCopyValue  = "copyValue"
+	actionCopyPath   = "copyPath"
+	actionClose      = "close"
+)
+
+// Selector actions
+const (
+	actionSelect      = "select"
+	actionRegion      = "region"
+	actionClearFilter = "clearFilter"
+)
+
+// keyBinding binds an action to one or more key sequences
+type keyBinding struct {
+	Action string
+	Keys   [][]string // Alternatives, each a sequence of one or more keys
+	Help   string     // Description for the help overlay
+}
+
+// keyMap holds the bindings of every context. It is immutable after construction.
+type keyMap struct {
+	contexts map[string][]keyBinding
+}
+
+// keyMapOverrides is the "keys" section of the config file: context -> action -> keys
+type keyMapOverrides map[string]map[string][]string
+
+// keyContextTitles names each context in the help overlay, in display order
+var keyContextTitles = []struct{ Context, Title string }{
+	{keyContextViewer, "Log viewer"},
+	{keyContextPrompt, "Search prompt"},
+	{keyContextFilter, "Filter prompt"},
+	{keyContextDetail, "Detail view"},
+	{keyContextSelector, "Log group selection"},
+}
+
+// defaultKeyMap returns the built-in bindings
+func defaultKeyMap() *keyMap {
+	bind := func(action, help string, keys ...string) keyBinding {
+		return keyBinding{Action: action, Keys: parseKeySequences(keys), Help: help}
+	}
+
+	return &keyMap{contexts: map[string][]keyBinding{
+		keyContextViewer: {
+			bind(actionUp, "Scroll up one line", "up", "k"),
+			bind(actionDown, "Scroll down one line", "down", "j"),
+			bind(actionPageUp, "Scroll up one page", "pgup", "ctrl+b"),
+			bind(actionPageDown, "Scroll down one page", "pgdown", "ctrl+f"),
+			bind(actionTop, "Go to oldest log", "g"),
+			bind(actionBottom, "Go to latest log and follow", "G", "end"),
+			bind(actionSearch, "Search", "/"),
+			bind(actionClearSearch, "Clear search", "esc"),
+			bind(actionFilter, "Filter lines (!term excludes)", "&"),
+			bind(actionMoreContext, "More context lines around filter matches", "+", "="),
+			bind(actionLessContext, "Fewer context lines around filter matches", "-"),
+			bind(actionHighlight, "Add a highlight rule (text @color)", "*"),
+			bind(actionRuleList, "List highlight rules", "#"),
+			bind(actionDeleteRule, "Delete the highlight rule at the cursor (rule list)", "d", "delete"),
+			bind(actionNextMatch, "Next match (older)", "n"),
+			bind(actionPrevMatch, "Previous match (newer)", "N"),
+			bind(actionFormat, "Toggle raw/formatted mode", "J"),
+			bind(actionFollow, "Toggle follow mode", "F"),
+			bind(actionTheme, "Cycle color theme", "T"),
+			bind(actionLevel, "Cycle minimum log level", "L"),
+			bind(actionInvocations, "Group Lambda lines by invocation", "I"),
+			bind(actionRejects, "Show only rejected VPC flows", "X"),
+			bind(actionDetail, "Show the full log event", "enter"),
+			bind(actionFold, "Expand or collapse the stack trace at the cursor", "z"),
+			bind(actionFoldAll, "Expand or collapse all stack traces", "Z"),
+			bind(actionColumns, "Toggle field columns", "C"),
+			bind(actionPickColumns, "Choose columns from the cursor line", "V"),
+			bind(actionMetrics, "Show EMF metric statistics", "M"),
+			bind(actionTimestamps, "Cycle timestamp format", "t"),
+			bind(actionIngestion, "Show ingestion time and delay", "i"),
+			bind(actionReveal, "Reveal or redact secrets", "U"),
+			bind(actionHistory, "Load older logs", "H"),
+			bind(actionCopy, "Copy log line to clipboard", "c"),
+			bind(actionBack, "Back to log group selection", "b", "backspace"),
+			bind(actionHelp, "Toggle this help", "?"),
+			bind(actionQuit, "Quit", "q", "ctrl+c"),
+		},
+		keyContextPrompt: {
+			bind(actionSubmit, "Run search", "enter"),
+			bind(actionCancel, "Cancel search", "esc"),
+			bind(actionDeleteChar, "Delete last character", "backspace"),
+			bind(actionDeleteWord, "Delete last word", "ctrl+w"),
+			bind(actionSearchRegex, "Toggle regular expression search", "ctrl+r"),
+			bind(actionSearchCase, "Toggle case-sensitive search", "ctrl+t"),
+			bind(actionSearchWord, "Toggle whole-word search", "ctrl+e"),
+		},
+		keyContextFilter: {
+			bind(actionSubmit, "Apply filter, or remove it when empty", "enter"),
+			bind(actionCancel, "Cancel editing the filter", "esc"),
+			bind(actionDeleteChar, "Delete last character", "backspace"),
+			bind(actionDeleteWord, "Delete last word", "ctrl+w"),
+			bind(actionSearchRegex, "Toggle regular expression terms (search too)", "ctrl+r"),
+			bind(actionSearchCase, "Toggle case-sensitive terms (search too)", "ctrl+t"),
+			bind(actionSearchWord, "Toggle whole-word terms (search too)", "ctrl+e"),
+		},
+		keyContextDetail: {
+			bind(actionUp, "Move up", "up", "k"),
+			bind(actionDown, "Move down", "down", "j"),
+			bind(actionPageUp, "Scroll up one page", "pgup", "ctrl+b"),
+			bind(actionPageDown, "Scroll down one page", "pgdown", "ctrl+f"),
+			bind(actionTop, "Go to the first line", "g"),
+			bind(actionBottom, "Go to the last line", "G", "end"),
+			bind(actionExpand, "Expand the JSON node", "right", "l"),
+			bind(actionCollapse, "Collapse the JSON node, or go to its parent", "left", "h"),
+			bind(actionToggleNode, "Expand or collapse the JSON node", "enter", " "),
+			bind(actionNextKey, "Next key at the same level", "tab", "]"),
+			bind(actionPrevKey, "Previous key at the same level", "shift+tab", "["),
+			bind(actionCopyValue, "Copy the value at the cursor", "y"),
+			bind(actionCopyPath, "Copy the JSON path at the cursor", "Y"),
+			bind(actionClose, "Close the detail view", "esc", "q", "backspace"),
+			bind(actionQuit, "Quit", "ctrl+c"),
+		},
+		keyContextSelector: {
+			bind(actionUp, "Move up", "up", "k"),
+			bind(actionDown, "Move down", "down", "j"),
+			bind(actionSelect, "Open log group", "enter"),
+			bind(actionRegion, "Change AWS region", "R"),
+			bind(actionClearFilter, "Clear filter, or quit when empty", "esc"),
+			bind(actionDeleteChar, "Delete last filter character", "backspace"),
+			bind(actionQuit, "Quit", "q", "ctrl+c"),
+		},
+	}}
+}
+
+// keyAliases maps alternative spellings in the config file to Bubble Tea key names
+var keyAliases = map[string]string{
+	"space":    " ",
+	"pageup":   "pgup",
+	"pagedown": "pgdown",
+	"escape":   "esc",
+	"return":   "enter",
+}
+
+// parseKeySequences splits "g g" style alternatives into key sequences
+func parseKeySequences(alternatives []string) [][]string {
+	sequences := make([][]string, 0, len(alternatives))
+	for _, alt := range alternatives {
+		keys := strings.Fields(alt)
+		for i, key := range keys {
+			if alias, ok := keyAliases[strings.ToLower(key)]; ok {
+				keys[i] = alias
+			}
+		}
+		if len(keys) > 0 {
+			sequences = append(sequences, keys)
+		}
+	}
+	return sequences
+}
+
+// withOverrides returns a copy of the key map with the config file bindings applied
+func (k *keyMap) withOverrides(overrides keyMapOverrides) (*keyMap, error) {
+	result := &keyMap{contexts: make(map[string][]keyBinding, len(k.contexts))}
+	for context, bindings := range k.contexts {
+		result.contexts[context] = append([]keyBinding(nil), bindings...)
+	}
+
+	// Apply in sorted order so error messages are deterministic
+	contexts := make([]string, 0, len(overrides))
+	for context := range overrides {
+		contexts = append(contexts, context)
+	}
+	sort.Strings(contexts)
+
+	for _, context := range contexts {
+		bindings, ok := result.contexts[context]
+		if !ok {
+			return nil, fmt.Errorf("keys: unknown context %q", context)
+		}
+		for action, keys := range overrides[context] {
+			index := -1
+			for i := range bindings {
+				if bindings[i].Action == action {
+					index = i
+					break
+				}
+			}
+			if index < 0 {
+				return nil, fmt.Errorf("keys: unknown %s action %q", context, action)
+			}
+			sequences := parseKeySequences(keys)
+			if len(sequences) == 0 {
+				return nil, fmt.Errorf("keys: %s.%s needs at least one key", context, action)
+			}
+			bindings[index].Keys = sequences
+		}
+		if err := validateBindings(context, bindings); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// validateBindings rejects keys that are bound twice or that shadow a longer sequence
+func validateBindings(context string, bindings []keyBinding) error {
+	type bound struct {
+		action string
+		keys   []string
+	}
+	var all []bound
+	for _, b := range bindings {
+		for _, seq := range b.Keys {
+			all = append(all, bound{b.Action, seq})
+		}
+	}
+
+	for i := range all {
+		for j := range all {
+			if i == j || len(all[i].keys) > len(all[j].keys) {
+				continue
+			}
+			if !hasKeyPrefix(all[j].keys, all[i].keys) {
+				continue
+			}
+			a, b := strings.Join(all[i].keys, " "), strings.Join(all[j].keys, " ")
+			if len(all[i].keys) == len(all[j].keys) {
+				if i < j && all[i].action != all[j].action {
+					return fmt.Errorf("keys: %s %q is bound to both %s and %s", context, a, all[i].action, all[j].action)
+				}
+				continue
+			}
+			return fmt.Errorf("keys: %s %q (%s) hides sequence %q (%s)", context, a, all[i].action, b, all[j].action)
+		}
+	}
+	return nil
+}
+
+// hasKeyPrefix reports whether seq starts with prefix
+func hasKeyPrefix(seq, prefix []string) bool {
+	if len(prefix) > len(seq) {
+		return false
+	}
+	for i := range prefix {
+		if seq[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve feeds one key press into the pending sequence of a context.
+// It returns the completed action (or ""), and the new pending sequence.
+// A key that neither completes nor extends a sequence returns "" and nil.
+func (k *keyMap) resolve(context string, pending []string, key string) (string, []string) {
+	seq := append(append([]string(nil), pending...), key)
+
+	action, prefix := k.lookup(context, seq)
+	switch {
+	case action != "":
+		return action, nil
+	case prefix:
+		return "", seq
+	case len(pending) > 0:
+		// Broken sequence: retry the key on its own
+		return k.resolve(context, nil, key)
+	}
+	return "", nil
+}
+
+// lookup finds an exact binding for seq, or reports whether seq starts a longer one
+func (k *keyMap) lookup(context string, seq []string) (action string, prefix bool) {
+	for _, b := range k.contexts[context] {
+		for _, keys := range b.Keys {
+			if !hasKeyPrefix(keys, seq) {
+				continue
+			}
+			if len(keys) == len(seq) {
+				return b.Action, false
+			}
+			prefix = true
+		}
+	}
+	return "", prefix
+}
+
+// bindings returns the bindings of a context in help order
+func (k *keyMap) bindings(context string) []keyBinding {
+	return k.contexts[context]
+}
+
+// keys returns the display labels of every key bound to an action
+func (k *keyMap) keys(context, action string) []string {
+	for _, b := range k.contexts[context] {
+		if b.Action == action {
+			labels := make([]string, 0, len(b.Keys))
+			for _, seq := range b.Keys {
+				labels = append(labels, keySequenceLabel(seq))
+			}
+			return labels
+		}
+	}
+	return nil
+}
+
+// hint returns the primary key label for an action, as shown in compact help
+func (k *keyMap) hint(context, action string) string {
+	if labels := k.keys(context, action); len(labels) > 0 {
+		return labels[0]
+	}
+	return "?"
+}
+
+// allKeys returns every key label of an action joined with "/"
+func (k *keyMap) allKeys(context, action string) string {
+	return strings.Join(k.keys(context, action), "/")
+}
+
+// keyLabels maps Bubble Tea key names to friendlier display labels
+var keyLabels = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"pgup":      "PgUp",
+	"pgdown":    "PgDn",
+	"home":      "Home",
+	"end":       "End",
+	"esc":       "Esc",
+	"enter":     "Enter",
+	"backspace": "Backspace",
+	"tab":       "Tab",
+	"shift+tab": "Shift+Tab",
+	" ":         "Space",
+}
+
+// keySequenceLabel renders a key sequence for display, e.g. ["g","g"] -> "gg"
+func keySequenceLabel(seq []string) string {
+	parts := make([]string, len(seq))
+	allRunes := true
+	for i, key := range seq {
+		if label, ok := keyLabels[key]; ok {
+			parts[i] = label
+		} else if strings.HasPrefix(key, "ctrl+") || strings.HasPrefix(key, "alt+") {
+			mod, rest, _ := strings.Cut(key, "+")
+			parts[i] = strings.ToUpper(mod[:1]) + mod[1:] + "+" + strings.ToUpper(rest)
+		} else {
+			parts[i] = key
+		}
+		if len([]rune(parts[i])) != 1 {
+			allRunes = false
+		}
+	}
+	if allRunes {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts, " ")
+}
+
+// helpText renders the bindings of the given contexts as aligned columns
+func (k *keyMap) helpText(contexts ...string) string {
+	var b strings.Builder
+	for _, section := range keyContextTitles {
+		include := false
+		for _, context := range contexts {
+			include = include || context == section.Context
+		}
+		if !include {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(section.Title + "\n")
+
+		bindings := k.bindings(section.Context)
+		width := 0
+		for _, binding := range bindings {
+			width = max(width, len([]rune(k.allKeys(section.Context, binding.Action))))
+		}
+		for _, binding := range bindings {
+			keys := k.allKeys(section.Context, binding.Action)
+			padding := strings.Repeat(" ", width-len([]rune(keys)))
+			fmt.Fprintf(&b, "  %s%s  %s\n", keys, padding, binding.Help)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
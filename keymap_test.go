@@ -0,0 +1,249 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyMap(t *testing.T) {
+	t.Run("DefaultBindingsAreValid", func(t *testing.T) {
+		keys := defaultKeyMap()
+		for context, bindings := range keys.contexts {
+			assertNoError(t, validateBindings(context, bindings))
+		}
+	})
+
+	t.Run("ResolveSingleKeys", func(t *testing.T) {
+		tests := []struct {
+			context string
+			key     string
+			want    string
+		}{
+			{keyContextViewer, "j", actionDown},
+			{keyContextViewer, "down", actionDown},
+			{keyContextViewer, "pgup", actionPageUp},
+			{keyContextViewer, "?", actionHelp},
+			{keyContextViewer, "x", ""},
+			{keyContextPrompt, "enter", actionSubmit},
+			{keyContextFilter, "ctrl+e", actionSearchWord},
+			{keyContextSelector, "R", actionRegion},
+		}
+
+		keys := defaultKeyMap()
+		for _, tt := range tests {
+			t.Run(tt.context+" "+tt.key, func(t *testing.T) {
+				// Act
+				action, pending := keys.resolve(tt.context, nil, tt.key)
+
+				// Assert
+				assertStringEqual(t, action, tt.want)
+				assertSliceLength(t, pending, 0, "pending keys")
+			})
+		}
+	})
+
+	t.Run("ResolveSequences", func(t *testing.T) {
+		// Arrange
+		keys, err := defaultKeyMap().withOverrides(keyMapOverrides{
+			keyContextViewer: {actionTop: {"g g", "home"}, actionBottom: {"g e"}},
+		})
+		assertNoError(t, err)
+
+		// Act & Assert - first key waits for the rest of the sequence
+		action, pending := keys.resolve(keyContextViewer, nil, "g")
+		assertStringEqual(t, action, "")
+		assertSliceLength(t, pending, 1, "pending after g")
+
+		action, pending = keys.resolve(keyContextViewer, pending, "g")
+		assertStringEqual(t, action, actionTop)
+		assertSliceLength(t, pending, 0, "pending after gg")
+
+		action, _ = keys.resolve(keyContextViewer, []string{"g"}, "e")
+		assertStringEqual(t, action, actionBottom)
+
+		// A broken sequence retries the last key on its own
+		action, pending = keys.resolve(keyContextViewer, []string{"g"}, "j")
+		assertStringEqual(t, action, actionDown)
+		assertSliceLength(t, pending, 0, "pending after broken sequence")
+	})
+
+	t.Run("InvalidOverrides", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			overrides keyMapOverrides
+			want      string
+		}{
+			{"unknown context", keyMapOverrides{"nope": {actionQuit: {"x"}}}, "unknown context"},
+			{"unknown action", keyMapOverrides{keyContextViewer: {"fly": {"x"}}}, "unknown viewer action"},
+			{"no keys", keyMapOverrides{keyContextViewer: {actionQuit: {" "}}}, "at least one key"},
+			{"duplicate", keyMapOverrides{keyContextViewer: {actionQuit: {"j"}}}, "bound to both"},
+			{"shadowed sequence", keyMapOverrides{keyContextViewer: {actionTop: {"c c"}}}, "hides sequence"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := defaultKeyMap().withOverrides(tt.overrides)
+				assertError(t, err, tt.want)
+			})
+		}
+	})
+
+	t.Run("OverridesDoNotModifyDefaults", func(t *testing.T) {
+		// Arrange
+		base := defaultKeyMap()
+
+		// Act
+		_, err := base.withOverrides(keyMapOverrides{keyContextViewer: {actionQuit: {"ctrl+q"}}})
+
+		// Assert
+		assertNoError(t, err)
+		assertStringEqual(t, base.allKeys(keyContextViewer, actionQuit), "q/Ctrl+C")
+	})
+
+	t.Run("Labels", func(t *testing.T) {
+		tests := []struct {
+			seq  []string
+			want string
+		}{
+			{[]string{"g", "g"}, "gg"},
+			{[]string{"up"}, "↑"},
+			{[]string{"pgdown"}, "PgDn"},
+			{[]string{"ctrl+f"}, "Ctrl+F"},
+			{[]string{" "}, "Space"},
+			{[]string{"g", "end"}, "g End"},
+		}
+
+		for _, tt := range tests {
+			assertStringEqual(t, keySequenceLabel(tt.seq), tt.want)
+		}
+	})
+
+	t.Run("ConfigFileAliases", func(t *testing.T) {
+		// Arrange & Act
+		file, err := parseConfigFile([]byte(`{"keys":{"viewer":{"pageDown":["space","PageDown"]}}}`))
+
+		// Assert
+		assertNoError(t, err)
+		action, _ := file.keys.resolve(keyContextViewer, nil, " ")
+		assertStringEqual(t, action, actionPageDown)
+		action, _ = file.keys.resolve(keyContextViewer, nil, "pgdown")
+		assertStringEqual(t, action, actionPageDown)
+	})
+
+	t.Run("HelpTextFollowsBindings", func(t *testing.T) {
+		// Arrange
+		keys, err := defaultKeyMap().withOverrides(keyMapOverrides{keyContextViewer: {actionCopy: {"y y"}}})
+		assertNoError(t, err)
+
+		// Act
+		help := keys.helpText(keyContextViewer, keyContextPrompt)
+
+		// Assert
+		assertStringContains(t, help, "Log viewer")
+		assertStringContains(t, help, "Search prompt")
+		assertStringContains(t, help, "yy")
+		assertStringContains(t, help, "Copy log line to clipboard")
+		if strings.Contains(help, "Log group selection") {
+			t.Error("help should only include the requested contexts")
+		}
+	})
+}
+
+func TestViewerKeyBindings(t *testing.T) {
+	t.Run("HelpOverlay", func(t *testing.T) {
+		// Arrange
+		model := createTestLogModel("test")
+		simulateWindowResize(model, 120, 40)
+
+		// Act
+		simulateKeyPress(model, "?")
+		view := model.View()
+
+		// Assert
+		assertBoolEqual(t, model.showHelp, true, "help shown")
+		assertStringContains(t, view, "Toggle raw/formatted mode")
+
+		// Other keys are swallowed while help is shown
+		simulateKeyPress(model, "/")
+		assertBoolEqual(t, model.searchMode, false, "search blocked by help")
+
+		simulateKeyPress(model, "?")
+		assertBoolEqual(t, model.showHelp, false, "help closed")
+	})
+
+	t.Run("RemappedSequence", func(t *testing.T) {
+		// Arrange
+		model := createTestLogModel("test")
+		keys, err := defaultKeyMap().withOverrides(keyMapOverrides{keyContextViewer: {actionTop: {"g g"}}})
+		assertNoError(t, err)
+		model.config.Keys = keys
+		for i := 0; i < 5; i++ {
+			model.store.Append(createTestLogEntry("line"))
+		}
+		model.followMode = false
+		model.cursor = 4
+		simulateWindowResize(model, 120, 40)
+
+		// Act & Assert
+		simulateKeyPress(model, "g")
+		assertIntEqual(t, model.cursor, 4, "cursor after first g")
+		assertStringContains(t, model.View(), "Keys: g-")
+
+		simulateKeyPress(model, "g")
+		assertIntEqual(t, model.cursor, 0, "cursor after gg")
+	})
+
+	t.Run("PromptTypesPrintableKeys", func(t *testing.T) {
+		// Arrange
+		model := createTestLogModel("test")
+		simulateKeyPress(model, "/")
+
+		// Act
+		for _, key := range []string{"q", "J", "F"} {
+			_, cmd := simulateKeyPress(model, key)
+			if cmd != nil {
+				t.Errorf("typing %q in the prompt should not trigger a command", key)
+			}
+		}
+
+		// Assert
+		assertStringEqual(t, model.searchQuery, "qJF")
+		assertBoolEqual(t, model.searchMode, true, "still in search mode")
+	})
+
+	t.Run("ControlsBarUsesBindings", func(t *testing.T) {
+		// Arrange
+		model := createTestLogModel("test")
+		model.width = 200
+		keys, err := defaultKeyMap().withOverrides(keyMapOverrides{keyContextViewer: {actionSearch: {"s"}}})
+		assertNoError(t, err)
+		model.config.Keys = keys
+
+		// Act
+		controls := renderControlsBar(model)
+
+		// Assert
+		assertStringContains(t, controls, "s search")
+		assertStringContains(t, controls, "? help")
+	})
+
+	t.Run("MatchStatusUsesBindings", func(t *testing.T) {
+		// Arrange
+		model := createTestLogModel("test")
+		model.store.Append(makeLogEntry(testTimestamp, "error", model.config))
+		simulateWindowResize(model, 200, 30)
+		keys, err := defaultKeyMap().withOverrides(keyMapOverrides{keyContextViewer: {actionNextMatch: {"ctrl+n"}, actionSearch: {"s"}}})
+		assertNoError(t, err)
+		model.config.Keys = keys
+
+		// Act
+		simulateKeyPress(model, "s")
+		for _, r := range "error" {
+			simulateKeyPress(model, string(r))
+		}
+		simulateKeyPress(model, "enter")
+
+		// Assert
+		assertStringContains(t, model.View(), "Ctrl+N=next, N=prev, s=new search")
+	})
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+AWS Lambda Platform Logs
+
+The Lambda runtime writes platform lines around every invocation:
+
+	INIT_START Runtime Version: python:3.12.v30	Runtime Version ARN: ...
+	START RequestId: 8f3c0a1e-... Version: $LATEST
+	END RequestId: 8f3c0a1e-...
+	REPORT RequestId: 8f3c0a1e-...	Duration: 102.25 ms	Billed Duration: 103 ms	Memory Size: 128 MB	Max Memory Used: 70 MB	Init Duration: 150.52 ms
+	2024-10-16T12:00:03.000Z 8f3c0a1e-... Task timed out after 3.00 seconds
+
+The lambda parser turns them into fields (requestId, durationMs, billedDurationMs,
+memorySizeMB, maxMemoryUsedMB, initDurationMs, timedOut) and renders REPORT lines
+as a one-line summary. Application lines carry the request ID in the Node.js and
+Python runtime formats, or in a requestId-style JSON field.
+
+Invocation grouping (I key) moves all lines of an invocation together under a
+header with its duration and memory, flagging cold starts (Init Duration or a
+preceding INIT_START) and timeouts. Lines without a request ID belong to the
+invocation running in the same log stream.
+*/
+
+const lambdaParserName = "lambda"
+
+// Lambda platform events, stored in the "event" field
+const (
+	lambdaEventInit    = "INIT_START"
+	lambdaEventStart   = "START"
+	lambdaEventEnd     = "END"
+	lambdaEventReport  = "REPORT"
+	lambdaEventTimeout = "TIMEOUT"
+)
+
+var (
+	lambdaStartRegex   = regexp.MustCompile(`^START RequestId: (\S+)(?: Version: (\S+))?`)
+	lambdaEndRegex     = regexp.MustCompile(`^END RequestId: (\S+)`)
+	lambdaReportRegex  = regexp.MustCompile(`^REPORT RequestId: (\S+)`)
+	lambdaInitRegex    = regexp.MustCompile(`^INIT_START Runtime Version: (\S+)`)
+	lambdaTimeoutRegex = regexp.MustCompile(`^(?:\S+\s+)?([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\s+Task timed out after ([\d.]+) seconds`)
+
+	// Node.js "time\tid\tLEVEL\tmsg" and Python "[LEVEL]\ttime\tid\tmsg" runtime lines
+	lambdaAppLineRegex = regexp.MustCompile(`^(?:\[[A-Z]+\]\t)?\S+\t([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\t`)
+)
+
+// requestIDFieldNames are JSON keys that hold a Lambda request ID
+var requestIDFieldNames = []string{"requestId", "request_id", "awsRequestId", "function_request_id", "AWSRequestId"}
+
+// lambdaReportFields maps REPORT labels to field names
+var lambdaReportFields = map[string]string{
+	"Duration":         "durationMs",
+	"Billed Duration":  "billedDurationMs",
+	"Memory Size":      "memorySizeMB",
+	"Max Memory Used":  "maxMemoryUsedMB",
+	"Init Duration":    "initDurationMs",
+	"Restore Duration": "restoreDurationMs",
+	"Status":           "status",
+	"Error Type":       "errorType",
+	"XRAY TraceId":     "xrayTraceId",
+}
+
+// lambdaParser handles Lambda platform lines
+type lambdaParser struct{}
+
+func (lambdaParser) Name() string { return lambdaParserName }
+
+func (lambdaParser) Detect(message string) bool {
+	return strings.HasPrefix(message, "START RequestId: ") ||
+		strings.HasPrefix(message, "END RequestId: ") ||
+		strings.HasPrefix(message, "REPORT RequestId: ") ||
+		strings.HasPrefix(message, "INIT_START ") ||
+		strings.Contains(message, "Task timed out after")
+}
+
+func (lambdaParser) Parse(message string) (*parsedLog, bool) {
+	fields := make(map[string]interface{})
+
+	switch {
+	case lambdaStartRegex.MatchString(message):
+		m := lambdaStartRegex.FindStringSubmatch(message)
+		fields["event"], fields["requestId"] = lambdaEventStart, m[1]
+		if m[2] != "" {
+			fields["version"] = m[2]
+		}
+	case lambdaEndRegex.MatchString(message):
+		m := lambdaEndRegex.FindStringSubmatch(message)
+		fields["event"], fields["requestId"] = lambdaEventEnd, m[1]
+	case lambdaReportRegex.MatchString(message):
+		m := lambdaReportRegex.FindStringSubmatch(message)
+		fields["event"], fields["requestId"] = lambdaEventReport, m[1]
+		parseLambdaReport(message, fields)
+	case lambdaInitRegex.MatchString(message):
+		m := lambdaInitRegex.FindStringSubmatch(message)
+		fields["event"], fields["runtimeVersion"] = lambdaEventInit, m[1]
+	case lambdaTimeoutRegex.MatchString(message):
+		m := lambdaTimeoutRegex.FindStringSubmatch(message)
+		fields["event"], fields["requestId"] = lambdaEventTimeout, m[1]
+		fields["timedOut"] = true
+		fields["level"] = "ERROR"
+		if seconds, err := strconv.ParseFloat(m[2], 64); err == nil {
+			fields["timeoutMs"] = seconds * 1000
+		}
+	default:
+		return nil, false
+	}
+	return &parsedLog{Fields: fields}, true
+}
+
+// parseLambdaReport reads the tab-separated "Label: value unit" pairs of a REPORT line
+func parseLambdaReport(message string, fields map[string]interface{}) {
+	for _, part := range strings.Split(message, "\t") {
+		label, value, ok := strings.Cut(strings.TrimSpace(part), ": ")
+		if !ok {
+			continue
+		}
+		name, known := lambdaReportFields[label]
+		if !known {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if number, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSuffix(value, " ms"), " MB"), 64); err == nil {
+			fields[name] = number
+		} else {
+			fields[name] = value
+		}
+	}
+	if fields["status"] == "timeout" {
+		fields["timedOut"] = true
+		fields["level"] = "ERROR"
+	}
+}
+
+func (lambdaParser) Render(parsed *parsedLog, config *UIConfig) string {
+	fields := parsed.Fields
+	colors := config.Colors
+	styled := func(color, text string) string {
+		if !config.ColorizeFields {
+			return text
+		}
+		return config.FieldStyle(color).Render(text)
+	}
+	id, _ := fields["requestId"].(string)
+
+	switch fields["event"] {
+	case lambdaEventStart:
+		version, _ := fields["version"].(string)
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s",
+			styled(colors.MutedColor, "START"), styled(colors.IPColor, id), styled(colors.MutedColor, version)))
+	case lambdaEventEnd:
+		return fmt.Sprintf("%s %s", styled(colors.MutedColor, "END"), styled(colors.IPColor, id))
+	case lambdaEventInit:
+		return fmt.Sprintf("%s %s", styled(colors.WarningColor, "INIT_START"), styled(colors.MutedColor, fields["runtimeVersion"].(string)))
+	case lambdaEventTimeout:
+		return fmt.Sprintf("%s %s after %s",
+			styled(colors.ErrorColor, "TIMEOUT"), styled(colors.IPColor, id), formatMillis(fields["timeoutMs"]))
+	}
+
+	inv := invocation{RequestID: id}
+	inv.addReport(fields)
+	return fmt.Sprintf("%s %s %s", styled(colors.MutedColor, "REPORT"), styled(colors.IPColor, id), inv.summary(config))
+}
+
+// formatMillis renders a millisecond field value: "102.25 ms", "3.00 s" from 1s up
+func formatMillis(value interface{}) string {
+	ms, _ := value.(float64)
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2f s", ms/1000)
+	}
+	return strconv.FormatFloat(ms, 'f', -1, 64) + " ms"
+}
+
+// lambdaRequestID finds the Lambda request ID of any log line
+func lambdaRequestID(message string, fields map[string]interface{}) string {
+	for _, key := range requestIDFieldNames {
+		if id, ok := fields[key].(string); ok && id != "" {
+			return id
+		}
+	}
+	if m := lambdaAppLineRegex.FindStringSubmatch(message); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// invocation summarizes one Lambda request from its platform lines
+type invocation struct {
+	RequestID      string
+	Duration       float64 // ms
+	BilledDuration float64 // ms
+	InitDuration   float64 // ms, cold starts only
+	MemorySize     float64 // MB
+	MaxMemoryUsed  float64 // MB
+	Reported       bool    // A REPORT line was seen
+	ColdStart      bool
+	TimedOut       bool
+	Lines          int
+}
+
+// addReport copies the metrics of a parsed REPORT or TIMEOUT line
+func (inv *invocation) addReport(fields map[string]interface{}) {
+	number := func(name string) float64 {
+		value, _ := fields[name].(float64)
+		return value
+	}
+	if fields["event"] == lambdaEventReport {
+		inv.Reported = true
+		inv.Duration = number("durationMs")
+		inv.BilledDuration = number("billedDurationMs")
+		inv.MemorySize = number("memorySizeMB")
+		inv.MaxMemoryUsed = number("maxMemoryUsedMB")
+		if init := number("initDurationMs"); init > 0 {
+			inv.InitDuration = init
+			inv.ColdStart = true
+		}
+	}
+	if timedOut, _ := fields["timedOut"].(bool); timedOut {
+		inv.TimedOut = true
+	}
+}
+
+// summary renders duration, memory and flags, e.g.
+// "102.25 ms (billed 103 ms) · mem 70/128 MB (55%) · init 150.52 ms · COLD START"
+func (inv *invocation) summary(config *UIConfig) string {
+	colors := config.Colors
+	styled := func(color, text string, bold bool) string {
+		if !config.ColorizeFields {
+			return text
+		}
+		return config.FieldStyle(color).Bold(bold).Render(text)
+	}
+
+	var parts []string
+	if inv.Reported {
+		parts = append(parts, fmt.Sprintf("%s %s",
+			styled(colors.FieldColor, formatMillis(inv.Duration), true),
+			styled(colors.MutedColor, "(billed "+formatMillis(inv.BilledDuration)+")", false)))
+
+		if inv.MemorySize > 0 {
+			ratio := inv.MaxMemoryUsed / inv.MemorySize
+			memColor := colors.InfoColor
+			switch {
+			case ratio >= 0.95:
+				memColor = colors.ErrorColor
+			case ratio >= 0.8:
+				memColor = colors.WarningColor
+			}
+			parts = append(parts, styled(memColor, fmt.Sprintf("mem %g/%g MB (%.0f%%)",
+				inv.MaxMemoryUsed, inv.MemorySize, ratio*100), false))
+		}
+		if inv.InitDuration > 0 {
+			parts = append(parts, styled(colors.WarningColor, "init "+formatMillis(inv.InitDuration), false))
+		}
+	}
+	if inv.ColdStart {
+		parts = append(parts, styled(colors.WarningColor, "COLD START", true))
+	}
+	if inv.TimedOut {
+		parts = append(parts, styled(colors.ErrorColor, "TIMEOUT", true))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// groupInvocations orders rows so that the lines of each invocation are adjacent,
+// placed where the invocation's first visible line was. It returns the new order,
+// the invocation that starts at each group's first row, and the rows inside a group.
+func groupInvocations(logs []logEntry, rows []int) ([]int, map[int]*invocation, map[int]bool) {
+	invocations := make(map[string]*invocation)
+	groupOf := make([]string, len(logs))
+	running := make(map[string]string) // Stream -> request ID currently running
+	coldPending := make(map[string]bool)
+
+	for i := range logs {
+		entry := &logs[i]
+		event, _ := entry.Fields["event"].(string)
+		if event == lambdaEventInit && entry.Format == lambdaParserName {
+			coldPending[entry.Stream] = true
+			continue
+		}
+
+		id := entry.RequestID
+		if id == "" {
+			id = running[entry.Stream]
+		}
+		if id == "" {
+			continue
+		}
+		groupOf[i] = id
+
+		inv := invocations[id]
+		if inv == nil {
+			inv = &invocation{RequestID: id}
+			invocations[id] = inv
+		}
+		inv.Lines++
+
+		if entry.Format != lambdaParserName {
+			continue
+		}
+		switch event {
+		case lambdaEventStart:
+			running[entry.Stream] = id
+			if coldPending[entry.Stream] {
+				inv.ColdStart = true
+				delete(coldPending, entry.Stream)
+			}
+		case lambdaEventReport:
+			if running[entry.Stream] == id {
+				delete(running, entry.Stream)
+			}
+		}
+		inv.addReport(entry.Fields)
+	}
+
+	members := make(map[string][]int)
+	for _, i := range rows {
+		if id := groupOf[i]; id != "" {
+			members[id] = append(members[id], i)
+		}
+	}
+
+	ordered := make([]int, 0, len(rows))
+	headers := make(map[int]*invocation)
+	grouped := make(map[int]bool)
+	emitted := make(map[string]bool)
+	for _, i := range rows {
+		id := groupOf[i]
+		if id == "" {
+			ordered = append(ordered, i)
+			continue
+		}
+		if emitted[id] {
+			continue
+		}
+		emitted[id] = true
+		headers[members[id][0]] = invocations[id]
+		for _, member := range members[id] {
+			ordered = append(ordered, member)
+			grouped[member] = true
+		}
+	}
+	return ordered, headers, grouped
+}
+
+// header renders the line shown above a group of invocation lines
+func (inv *invocation) header(config *UIConfig) string {
+	title := config.HeaderStyle().Render("▸ " + inv.RequestID)
+	if summary := inv.summary(config); summary != "" {
+		return title + " " + summary
+	}
+	return title
+}
+
+// invocationStats counts invocations, cold starts and timeouts among group headers
+func invocationStats(headers map[int]*invocation) (total, cold, timedOut int) {
+	for _, inv := range headers {
+		total++
+		if inv.ColdStart {
+			cold++
+		}
+		if inv.TimedOut {
+			timedOut++
+		}
+	}
+	return total, cold, timedOut
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	testRequestA = "8f3c0a1e-1111-2222-3333-444455556666"
+	testRequestB = "9a4d1b2f-aaaa-bbbb-cccc-ddddeeeeffff"
+)
+
+// lambdaEntry builds an entry as it arrives from a log stream
+func lambdaEntry(stream, message string, config *UIConfig) logEntry {
+	entry := makeLogEntry(testTimestamp, message, config)
+	entry.Stream = stream
+	return entry
+}
+
+func TestLambdaParser(t *testing.T) {
+	t.Run("PlatformLines", func(t *testing.T) {
+		parser := lambdaParser{}
+
+		parsed, ok := parser.Parse("START RequestId: " + testRequestA + " Version: $LATEST")
+		assertBoolEqual(t, ok, true, "parse START")
+		assertStringEqual(t, parsed.Fields["event"].(string), lambdaEventStart)
+		assertStringEqual(t, parsed.Fields["version"].(string), "$LATEST")
+
+		parsed, ok = parser.Parse("END RequestId: " + testRequestA)
+		assertBoolEqual(t, ok, true, "parse END")
+		assertStringEqual(t, parsed.Fields["requestId"].(string), testRequestA)
+
+		_, ok = parser.Parse("STARTED something else")
+		assertBoolEqual(t, ok, false, "unrelated line")
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		// Arrange
+		line := "REPORT RequestId: " + testRequestA + "\tDuration: 102.25 ms\tBilled Duration: 103 ms\t" +
+			"Memory Size: 128 MB\tMax Memory Used: 70 MB\tInit Duration: 150.52 ms\t"
+
+		// Act
+		parsed, ok := lambdaParser{}.Parse(line)
+
+		// Assert
+		assertBoolEqual(t, ok, true, "parse REPORT")
+		want := map[string]float64{
+			"durationMs": 102.25, "billedDurationMs": 103, "memorySizeMB": 128,
+			"maxMemoryUsedMB": 70, "initDurationMs": 150.52,
+		}
+		for name, value := range want {
+			if got, _ := parsed.Fields[name].(float64); got != value {
+				t.Errorf("%s: got %v, want %v", name, parsed.Fields[name], value)
+			}
+		}
+
+		rendered := stripANSI(lambdaParser{}.Render(parsed, createTestConfig()))
+		assertStringEqual(t, rendered, "REPORT "+testRequestA+
+			" 102.25 ms (billed 103 ms) · mem 70/128 MB (55%) · init 150.52 ms · COLD START")
+	})
+
+	t.Run("Timeouts", func(t *testing.T) {
+		config := createTestConfig()
+
+		timeout := makeLogEntry(testTimestamp, "2024-10-16T12:00:03.000Z "+testRequestA+" Task timed out after 3.00 seconds", config)
+		assertStringEqual(t, timeout.Format, lambdaParserName)
+		assertStringEqual(t, stripANSI(timeout.Message), "TIMEOUT "+testRequestA+" after 3.00 s")
+		assertIntEqual(t, int(timeout.Level), int(levelError), "timeout level")
+
+		report := makeLogEntry(testTimestamp, "REPORT RequestId: "+testRequestA+"\tDuration: 3000.00 ms\tBilled Duration: 3000 ms\tMemory Size: 128 MB\tMax Memory Used: 127 MB\tStatus: timeout", config)
+		assertBoolEqual(t, report.Fields["timedOut"].(bool), true, "REPORT status timeout")
+		assertStringContains(t, stripANSI(report.Message), "TIMEOUT")
+	})
+
+	t.Run("RequestIDs", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			message string
+			want    string
+		}{
+			{"node", "2024-10-16T12:00:00.000Z\t" + testRequestA + "\tINFO\thello", testRequestA},
+			{"python", "[INFO]\t2024-10-16T12:00:00.000Z\t" + testRequestA + "\thello", testRequestA},
+			{"json", `{"level":"info","awsRequestId":"` + testRequestA + `"}`, testRequestA},
+			{"platform", "END RequestId: " + testRequestA, testRequestA},
+			{"plain", "hello from print()", ""},
+		}
+
+		config := createTestConfig()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assertStringEqual(t, makeLogEntry(testTimestamp, tt.message, config).RequestID, tt.want)
+			})
+		}
+	})
+
+	t.Run("RawModeKeepsOriginal", func(t *testing.T) {
+		line := "START RequestId: " + testRequestA + " Version: $LATEST"
+		entry := makeLogEntry(testTimestamp, line, &UIConfig{})
+		assertStringEqual(t, entry.Message, line)
+		assertStringEqual(t, entry.RequestID, testRequestA)
+	})
+}
+
+func TestInvocationGrouping(t *testing.T) {
+	// Arrange - two streams interleaved, stream 1 starts cold, B times out
+	config := createTestConfig()
+	logs := []logEntry{
+		lambdaEntry("s1", "INIT_START Runtime Version: python:3.12.v30", config),
+		lambdaEntry("s1", "START RequestId: "+testRequestA+" Version: $LATEST", config),
+		lambdaEntry("s2", "START RequestId: "+testRequestB+" Version: $LATEST", config),
+		lambdaEntry("s1", "print without request id", config),
+		lambdaEntry("s2", "[ERROR]\t2024-10-16T12:00:00.000Z\t"+testRequestB+"\tboom", config),
+		lambdaEntry("s1", "END RequestId: "+testRequestA, config),
+		lambdaEntry("s1", "REPORT RequestId: "+testRequestA+"\tDuration: 10.00 ms\tBilled Duration: 10 ms\tMemory Size: 128 MB\tMax Memory Used: 60 MB\tInit Duration: 200.00 ms", config),
+		lambdaEntry("s2", "2024-10-16T12:00:03.000Z "+testRequestB+" Task timed out after 3.00 seconds", config),
+		lambdaEntry("s1", "unrelated line after the invocation", config),
+	}
+	rows := []int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+
+	// Act
+	ordered, headers, grouped := groupInvocations(logs, rows)
+
+	// Assert
+	want := []int{0, 1, 3, 5, 6, 2, 4, 7, 8}
+	for i := range want {
+		if ordered[i] != want[i] {
+			t.Fatalf("order: got %v, want %v", ordered, want)
+		}
+	}
+	assertBoolEqual(t, headers[1].ColdStart, true, "A cold start")
+	assertBoolEqual(t, headers[1].TimedOut, false, "A timed out")
+	assertBoolEqual(t, headers[2].TimedOut, true, "B timed out")
+	assertBoolEqual(t, headers[2].ColdStart, false, "B cold start")
+	assertBoolEqual(t, grouped[3], true, "print line joins running invocation")
+	assertBoolEqual(t, grouped[8], false, "line after REPORT is ungrouped")
+
+	total, cold, timedOut := invocationStats(headers)
+	assertIntEqual(t, total, 2, "invocations")
+	assertIntEqual(t, cold, 1, "cold starts")
+	assertIntEqual(t, timedOut, 1, "timeouts")
+}
+
+func TestInvocationGroupingInViewer(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/aws/lambda/fn")
+	for _, msg := range []string{
+		"START RequestId: " + testRequestA + " Version: $LATEST",
+		"START RequestId: " + testRequestB + " Version: $LATEST",
+		"2024-10-16T12:00:00.000Z\t" + testRequestA + "\tINFO\tfrom A",
+		"END RequestId: " + testRequestA,
+	} {
+		model.store.Append(lambdaEntry("s", msg, model.config))
+	}
+	model.followMode = false
+	simulateWindowResize(model, 160, 40)
+
+	// Act
+	simulateKeyPress(model, "I")
+
+	// Assert
+	assertBoolEqual(t, model.groupInvocations, true, "grouping on")
+	assertStringContains(t, model.formatStatusMsg, "Grouped 2 invocations")
+	rows := model.visibleRows(model.safeLogs())
+	assertIntEqual(t, rows[1], 2, "A's app line follows its START")
+	assertStringContains(t, model.View(), "▸ "+testRequestA)
+
+	simulateKeyPress(model, "I")
+	assertBoolEqual(t, model.groupInvocations, false, "grouping off")
+}
+
+func TestInvocationGroupingFitsTerminal(t *testing.T) {
+	// Arrange - every invocation adds a header line above its rows
+	model := createTestLogModel("/aws/lambda/fn")
+	for i := 0; i < 30; i++ {
+		requestID := fmt.Sprintf("8f3c0a1e-1111-2222-3333-%012d", i)
+		model.store.Append(lambdaEntry("s", "START RequestId: "+requestID+" Version: $LATEST", model.config))
+		model.store.Append(lambdaEntry("s", "END RequestId: "+requestID, model.config))
+	}
+	model.followMode = false
+	model.cursor = 30
+	simulateWindowResize(model, 160, 30)
+
+	// Act
+	simulateKeyPress(model, "I")
+	view := model.View()
+
+	// Assert
+	if height := lipgloss.Height(view); height > 30 {
+		t.Fatalf("frame has %d lines, terminal has 30", height)
+	}
+	assertStringContains(t, view, "START 8f3c0a1e-1111-2222-3333-000000000015") // Cursor row stays in view
+}
+
+func TestRowPositionInGroupedOrder(t *testing.T) {
+	// Arrange - grouping moved row 4 before row 2
+	model := createTestLogModel("/aws/lambda/fn")
+	rows := []int{0, 1, 4, 2, 6}
+
+	// Act & Assert - a hidden cursor maps to the next entry, not the next position
+	model.cursor = 3
+	assertIntEqual(t, model.rowPosition(rows), 2, "cursor 3 maps to entry 4")
+	model.cursor = 5
+	assertIntEqual(t, model.rowPosition(rows), 4, "cursor 5 maps to entry 6")
+	model.cursor = 9
+	assertIntEqual(t, model.rowPosition(rows), 4, "cursor past the end maps to the latest entry")
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*
+Log Levels
+
+Every entry gets a level when it is created, in raw and formatted mode alike:
+
+	JSON       {"level":"error"}, "severity", "lvl", "levelname", pino/bunyan numbers (50 = error)
+	Brackets   [ERROR] ..., [WARN] ... (Lambda Python runtime: "[ERROR]\t2024-...\t<request id>\t...")
+	Prefixes   ERROR: ..., WARN: ..., "... ERROR [main] ..." (log4j/logback)
+	Tabs       "2024-...Z\t<request id>\tERROR\t..." (Lambda Node.js runtime)
+	klog       E1016 12:34:56.789012 ... (Kubernetes/glog)
+	logfmt     level=warn ...
+
+Only upper-case level words are matched in plain text, so "an error occurred"
+does not count as an error. Entries without a recognizable level stay visible
+under every threshold; they are often continuation lines of a leveled entry.
+*/
+
+// logLevel is the severity of a log entry; levelNone means it could not be detected
+type logLevel int
+
+const (
+	levelNone logLevel = iota
+	levelTrace
+	levelDebug
+	levelInfo
+	levelWarn
+	levelError
+	levelFatal
+)
+
+// levelThresholds is the cycling order of the minimum-level filter (L key)
+var levelThresholds = []logLevel{levelNone, levelDebug, levelInfo, levelWarn, levelError}
+
+// levelNames maps level words (upper case) to levels
+var levelNames = map[string]logLevel{
+	"TRACE":     levelTrace,
+	"DEBUG":     levelDebug,
+	"INFO":      levelInfo,
+	"NOTICE":    levelInfo,
+	"WARN":      levelWarn,
+	"WARNING":   levelWarn,
+	"ERR":       levelError,
+	"ERROR":     levelError,
+	"CRIT":      levelFatal,
+	"CRITICAL":  levelFatal,
+	"FATAL":     levelFatal,
+	"PANIC":     levelFatal,
+	"EMERG":     levelFatal,
+	"EMERGENCY": levelFatal,
+	"ALERT":     levelFatal,
+}
+
+// levelFieldNames are the JSON keys checked for a level, in priority order
+var levelFieldNames = []string{"level", "severity", "lvl", "levelname", "log_level", "loglevel"}
+
+var (
+	// An upper-case level word delimited by brackets, colons, pipes or whitespace
+	levelWordRegex = regexp.MustCompile(`(?:^|[\s\[(|])(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERR|ERROR|CRIT|CRITICAL|FATAL|PANIC|EMERG|EMERGENCY|ALERT)(?:[\s\])|:]|$)`)
+
+	// klog/glog header: Lmmdd hh:mm:ss
+	klogRegex = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}`)
+
+	// logfmt or key: value style level
+	levelKeyRegex = regexp.MustCompile(`(?i)\b(?:level|lvl|severity)[=:]\s*"?([a-z]+)`)
+)
+
+// levelScanLimit bounds how much of a plain text message is scanned for a level
+const levelScanLimit = 200
+
+// String returns the canonical upper-case name of the level
+func (l logLevel) String() string {
+	switch l {
+	case levelTrace:
+		return "TRACE"
+	case levelDebug:
+		return "DEBUG"
+	case levelInfo:
+		return "INFO"
+	case levelWarn:
+		return "WARN"
+	case levelError:
+		return "ERROR"
+	case levelFatal:
+		return "FATAL"
+	}
+	return "ALL"
+}
+
+// parseLevelName converts a level word in any case ("warn", "Warning") to a level
+func parseLevelName(name string) logLevel {
+	return levelNames[strings.ToUpper(strings.TrimSpace(name))]
+}
+
+// parseLevelSetting converts the minLevel config value; "" and "all" disable the threshold
+func parseLevelSetting(name string) (logLevel, error) {
+	if name == "" || strings.EqualFold(name, "all") {
+		return levelNone, nil
+	}
+	if level := parseLevelName(name); level != levelNone {
+		return level, nil
+	}
+	return levelNone, fmt.Errorf("unknown level %q", name)
+}
+
+// detectLevel finds the level of a message, preferring parsed fields over the text
+func detectLevel(message string, fields map[string]interface{}) logLevel {
+	if level := levelFromFields(fields); level != levelNone {
+		return level
+	}
+	return levelFromText(message)
+}
+
+// levelFromFields reads the level from parser fields (string names or pino/bunyan numbers)
+func levelFromFields(fields map[string]interface{}) logLevel {
+	for _, key := range levelFieldNames {
+		switch value := fields[key].(type) {
+		case string:
+			if level := parseLevelName(value); level != levelNone {
+				return level
+			}
+			if n, err := strconv.Atoi(value); err == nil {
+				return levelFromNumber(n)
+			}
+		case float64:
+			return levelFromNumber(int(value))
+		}
+	}
+	return levelNone
+}
+
+// levelFromNumber maps pino/bunyan numeric levels
+func levelFromNumber(n int) logLevel {
+	switch {
+	case n >= 60:
+		return levelFatal
+	case n >= 50:
+		return levelError
+	case n >= 40:
+		return levelWarn
+	case n >= 30:
+		return levelInfo
+	case n >= 20:
+		return levelDebug
+	case n >= 10:
+		return levelTrace
+	}
+	return levelNone
+}
+
+// levelFromText scans the start of a plain text message for a level marker
+func levelFromText(message string) logLevel {
+	message = strings.TrimSpace(message)
+	if len(message) > levelScanLimit {
+		message = message[:levelScanLimit]
+	}
+
+	if m := klogRegex.FindStringSubmatch(message); m != nil {
+		return map[string]logLevel{"I": levelInfo, "W": levelWarn, "E": levelError, "F": levelFatal}[m[1]]
+	}
+	if m := levelWordRegex.FindStringSubmatch(message); m != nil {
+		return levelNames[m[1]]
+	}
+	if m := levelKeyRegex.FindStringSubmatch(message); m != nil {
+		return parseLevelName(m[1])
+	}
+	return levelNone
+}
+
+// nextLevelThreshold returns the threshold after current in the L key cycle
+func nextLevelThreshold(current logLevel) logLevel {
+	for i, level := range levelThresholds {
+		if level == current {
+			return levelThresholds[(i+1)%len(levelThresholds)]
+		}
+	}
+	return levelNone
+}
+
+// levelCounts counts entries per detected level; the store keeps them current (see logstore.go)
+type levelCounts [levelFatal + 1]int
+
+// String renders non-zero counts from most to least severe, e.g. "E:3 W:12 I:240"
+func (c levelCounts) String() string {
+	var parts []string
+	for level := levelFatal; level > levelNone; level-- {
+		if c[level] > 0 {
+			parts = append(parts, fmt.Sprintf("%s:%d", level.String()[:1], c[level]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// LevelStyle returns the row style of a level, or ok=false when the zebra style applies
+func (c *UIConfig) LevelStyle(level logLevel) (style lipgloss.Style, ok bool) {
+	colors := c.Colors
+	switch level {
+	case levelTrace:
+		return c.FieldStyle(colors.LevelTraceColor), true
+	case levelDebug:
+		return c.FieldStyle(colors.LevelDebugColor), true
+	case levelWarn:
+		return c.FieldStyle(colors.LevelWarnColor), true
+	case levelError:
+		return c.FieldStyle(colors.LevelErrorColor), true
+	case levelFatal:
+		return c.FieldStyle(colors.LevelFatalColor).Bold(true), true
+	}
+	return lipgloss.Style{}, false
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogLevels(t *testing.T) {
+	t.Run("Detection", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			message string
+			want    logLevel
+		}{
+			{"json level", `{"level":"error","msg":"boom"}`, levelError},
+			{"json severity", `{"severity":"WARNING","message":"slow"}`, levelWarn},
+			{"json lvl", `{"lvl":"dbug"}`, levelNone},
+			{"json lvl debug", `{"lvl":"debug"}`, levelDebug},
+			{"json levelname", `{"levelname":"CRITICAL"}`, levelFatal},
+			{"pino number", `{"level":50,"msg":"x"}`, levelError},
+			{"bracketed", `[ERROR] database unavailable`, levelError},
+			{"prefix", `WARN: disk almost full`, levelWarn},
+			{"log4j", `2024-10-16 12:00:00,123 INFO [main] started`, levelInfo},
+			{"klog", `E1016 12:34:56.789012    1 controller.go:42] sync failed`, levelError},
+			{"klog warning", `W1016 12:34:56.789012 reflector.go:324] watch closed`, levelWarn},
+			{"lambda python", "[WARNING]\t2024-10-16T12:00:00.000Z\t8f3c0a1e-1111-2222-3333-444455556666\tretrying", levelWarn},
+			{"lambda node", "2024-10-16T12:00:00.000Z\t8f3c0a1e-1111-2222-3333-444455556666\tERROR\tInvoke Error", levelError},
+			{"logfmt", `time=2024-10-16 level=debug msg="cache miss"`, levelDebug},
+			{"first marker wins", `INFO: upstream returned ERROR`, levelInfo},
+			{"lower case prose", `an error occurred while retrying`, levelNone},
+			{"embedded word", `ERRORS_TOTAL=3`, levelNone},
+			{"plain", `GET /health 200`, levelNone},
+		}
+
+		config := createTestConfig()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				entry := makeLogEntry(testTimestamp, tt.message, config)
+				if entry.Level != tt.want {
+					t.Errorf("level of %q: got %s, want %s", tt.message, entry.Level, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("DetectedInRawMode", func(t *testing.T) {
+		config := &UIConfig{}
+		entry := makeLogEntry(testTimestamp, `{"level":"warn"}`, config)
+		if entry.Level != levelWarn {
+			t.Errorf("got %s, want WARN", entry.Level)
+		}
+	})
+
+	t.Run("Counts", func(t *testing.T) {
+		store := newLogStore(4)
+		for _, level := range []logLevel{levelInfo, levelError, levelWarn, levelWarn, levelNone} {
+			store.Append(logEntry{Level: level})
+		}
+		assertStringEqual(t, store.Levels().String(), "E:1 W:2") // INFO was overwritten
+
+		store.UpdateEntry(0, logEntry{Level: levelFatal})
+		assertStringEqual(t, store.Levels().String(), "F:1 W:2")
+	})
+
+	t.Run("ThresholdCycle", func(t *testing.T) {
+		level := levelNone
+		var seen []string
+		for range levelThresholds {
+			level = nextLevelThreshold(level)
+			seen = append(seen, level.String())
+		}
+		assertStringEqual(t, strings.Join(seen, " "), "DEBUG INFO WARN ERROR ALL")
+	})
+
+	t.Run("MinLevelSetting", func(t *testing.T) {
+		file, err := parseConfigFile([]byte(`{"settings":{"minLevel":"warn"}}`))
+		assertNoError(t, err)
+		config := NewUIConfig()
+		file.apply(config)
+		if config.MinLevel != levelWarn {
+			t.Errorf("minLevel: got %s, want WARN", config.MinLevel)
+		}
+
+		_, err = parseConfigFile([]byte(`{"settings":{"minLevel":"loud"}}`))
+		assertError(t, err, "minLevel")
+	})
+}
+
+func TestLevelThresholdInViewer(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("test")
+	for _, msg := range []string{"[INFO] one", "[ERROR] two", "[DEBUG] three", "no level", "[WARN] five"} {
+		model.store.Append(makeLogEntry(testTimestamp, msg, model.config))
+	}
+	model.followMode = false
+	model.cursor = 0
+	simulateWindowResize(model, 160, 40)
+
+	// Act - DEBUG, INFO, WARN
+	for i := 0; i < 3; i++ {
+		simulateKeyPress(model, "L")
+	}
+
+	// Assert
+	assertIntEqual(t, int(model.minLevel), int(levelWarn), "threshold")
+	assertIntEqual(t, model.cursor, 1, "cursor snapped to first visible row")
+	assertSliceLength(t, model.visibleRows(model.safeLogs()), 3, "visible rows")
+
+	simulateKeyPress(model, "j")
+	assertIntEqual(t, model.cursor, 3, "down skips hidden DEBUG line")
+	simulateKeyPress(model, "j")
+	assertIntEqual(t, model.cursor, 4, "down to WARN")
+
+	view := model.View()
+	assertStringContains(t, view, "3/3 logs")
+	assertStringContains(t, view, "below WARN hidden")
+	if contains(view, "three") {
+		t.Error("DEBUG line should be hidden")
+	}
+
+	// Search skips hidden lines
+	model.searchQuery = "t"
+	model.performSearch()
+	for _, idx := range model.matches {
+		if idx == 2 {
+			t.Error("hidden line should not be a search match")
+		}
+	}
+
+	// ERROR, then back to all levels
+	simulateKeyPress(model, "L")
+	assertSliceLength(t, model.visibleRows(model.safeLogs()), 2, "error and unleveled rows")
+	simulateKeyPress(model, "L")
+	assertIntEqual(t, int(model.minLevel), int(levelNone), "threshold reset")
+	assertSliceLength(t, model.visibleRows(model.safeLogs()), 5, "all rows")
+}
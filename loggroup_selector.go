@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // logGroupSelectorModel represents the log group selection TUI
@@ -20,6 +19,7 @@ type logGroupSelectorModel struct {
 	quit            bool
 	config          *UIConfig
 	searchQuery     string
+	pendingKeys     []string // Keys typed so far of a multi-key binding
 }
 
 // newLogGroupSelector creates a new log group selector
@@ -45,32 +45,35 @@ func (m *logGroupSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		action, pending := m.config.Keys.resolve(keyContextSelector, m.pendingKeys, msg.String())
+		m.pendingKeys = pending
+
+		switch action {
+		case actionQuit:
 			m.quit = true
 			return m, tea.Quit
 
-		case "R":
+		case actionRegion:
 			m.changeRegion = true
 			return m, tea.Quit
 
-		case "up", "k":
+		case actionUp:
 			if m.cursor > 0 {
 				m.cursor--
 			}
 
-		case "down", "j":
+		case actionDown:
 			if m.cursor < len(m.filteredGroups)-1 {
 				m.cursor++
 			}
 
-		case "enter":
+		case actionSelect:
 			if len(m.filteredGroups) > 0 {
 				m.selected = m.filteredGroups[m.cursor]
 				return m, tea.Quit
 			}
 
-		case "esc":
+		case actionClearFilter:
 			if m.searchQuery != "" {
 				// Clear search
 				m.searchQuery = ""
@@ -81,7 +84,7 @@ func (m *logGroupSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 
-		case "backspace":
+		case actionDeleteChar:
 			if len(m.searchQuery) > 0 {
 				m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
 				m.filterLogGroups()
@@ -89,7 +92,7 @@ func (m *logGroupSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		default:
 			// Auto-start search when typing alphanumeric characters
-			if len(msg.Runes) > 0 {
+			if len(msg.Runes) > 0 && len(pending) == 0 {
 				char := msg.Runes[0]
 				// Check if it's a printable character (letters, numbers, common symbols)
 				if (char >= 'a' && char <= 'z') || 
@@ -111,9 +114,7 @@ func (m *logGroupSelectorModel) View() string {
 	var b strings.Builder
 
 	// Title
-	title := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("12")).
+	title := m.config.HeaderStyle().
 		Render("📋 CloudWatch Log Group Selection")
 	
 	b.WriteString(title)
@@ -121,15 +122,17 @@ func (m *logGroupSelectorModel) View() string {
 
 	// Instructions and search status
 	var instructions string
+	keys := m.config.Keys
 	if m.searchQuery != "" {
-		instructions = fmt.Sprintf("Filter: %s_ | Esc to clear, Enter to select", m.searchQuery)
-		instructions = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11")).
+		instructions = fmt.Sprintf("Filter: %s_ | %s to clear, %s to select", m.searchQuery,
+			keys.hint(keyContextSelector, actionClearFilter), keys.hint(keyContextSelector, actionSelect))
+		instructions = m.config.SearchStyle().
 			Render(instructions)
 	} else {
-		instructions = "Type to filter, ↑↓/j/k to navigate, Enter to select, R to change region, q to quit"
-		instructions = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("8")).
+		instructions = fmt.Sprintf("Type to filter, %s to navigate, %s to select, %s to change region, %s to quit",
+			selectorNavigationKeys(keys), keys.hint(keyContextSelector, actionSelect),
+			keys.hint(keyContextSelector, actionRegion), keys.hint(keyContextSelector, actionQuit))
+		instructions = m.config.MutedStyle().
 			Render(instructions)
 	}
 	
@@ -163,8 +166,7 @@ func (m *logGroupSelectorModel) View() string {
 
 	// Show "no results" message if filtered list is empty
 	if len(m.filteredGroups) == 0 {
-		noResults := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("8")).
+		noResults := m.config.MutedStyle().
 			Render("No log groups match your search")
 		b.WriteString(noResults)
 		b.WriteString("\n")
@@ -184,9 +186,7 @@ func (m *logGroupSelectorModel) View() string {
 
 		if i == m.cursor {
 			// Highlight selected item
-			line := lipgloss.NewStyle().
-				Background(lipgloss.Color("12")).
-				Foreground(lipgloss.Color("0")).
+			line := m.config.SelectionStyle().
 				Render(fmt.Sprintf("> %s", logGroup))
 			b.WriteString(line)
 		} else {
@@ -204,8 +204,7 @@ func (m *logGroupSelectorModel) View() string {
 		} else {
 			scrollInfo += "]"
 		}
-		b.WriteString(lipgloss.NewStyle().
-			Foreground(lipgloss.Color("8")).
+		b.WriteString(m.config.MutedStyle().
 			Render(scrollInfo))
 	}
 
@@ -213,17 +212,25 @@ func (m *logGroupSelectorModel) View() string {
 	b.WriteString("\n\n")
 	var controls string
 	if m.searchQuery != "" {
-		controls = "Type to filter | Backspace: delete | Esc: clear | Enter: select | q: quit"
+		controls = fmt.Sprintf("Type to filter | %s: delete | %s: clear | %s: select | %s: quit",
+			keys.hint(keyContextSelector, actionDeleteChar), keys.hint(keyContextSelector, actionClearFilter),
+			keys.hint(keyContextSelector, actionSelect), keys.hint(keyContextSelector, actionQuit))
 	} else {
-		controls = "Type to filter | ↑↓/j/k: navigate | Enter: select | R: change region | q: quit"
+		controls = fmt.Sprintf("Type to filter | %s: navigate | %s: select | %s: change region | %s: quit",
+			selectorNavigationKeys(keys), keys.hint(keyContextSelector, actionSelect),
+			keys.hint(keyContextSelector, actionRegion), keys.hint(keyContextSelector, actionQuit))
 	}
-	b.WriteString(lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+	b.WriteString(m.config.MutedStyle().
 		Render(controls))
 
 	return b.String()
 }
 
+// selectorNavigationKeys describes the up/down bindings, e.g. "↑↓/k/j"
+func selectorNavigationKeys(keys *keyMap) string {
+	return keys.allKeys(keyContextSelector, actionUp) + " " + keys.allKeys(keyContextSelector, actionDown)
+}
+
 // filterLogGroups filters the log groups based on the search query
 func (m *logGroupSelectorModel) filterLogGroups() {
 	if m.searchQuery == "" {
@@ -1,59 +1,87 @@
 package main
 
-// logStore is a fixed-capacity ring buffer for log entries.
-// Memory-bounded, O(1) append, no reallocation after init.
+// logStore is a fixed-capacity buffer for log entries, oldest first.
+// Memory-bounded and O(1) amortized append: the entries are a window that
+// slides along a backing array of twice the capacity and is moved back to
+// the front when it reaches the end, so Slice never copies.
 type logStore struct {
-	entries  []logEntry
-	start    int // index of oldest entry
-	capacity int // max entries (fixed)
+	entries  []logEntry // entries[start:] are the stored entries; cap is 2*capacity
+	start    int        // index of oldest entry
+	capacity int        // max entries (fixed)
+	dropped  int        // Entries overwritten since creation
+	edits    int        // Calls of UpdateEntry since creation
+	levels   levelCounts
 }
 
-// newLogStore creates a ring buffer with fixed capacity
+// newLogStore creates a buffer with fixed capacity
 func newLogStore(capacity int) *logStore {
 	return &logStore{
-		entries:  make([]logEntry, 0, capacity),
+		entries:  make([]logEntry, 0, 2*capacity),
 		capacity: capacity,
 	}
 }
 
-// Append adds a log entry. When full, overwrites oldest entry.
+// Append adds a log entry. When full, drops the oldest entry.
 // Returns true if buffer wrapped (overwrote old entries).
 func (s *logStore) Append(entry logEntry) bool {
-	if len(s.entries) < s.capacity {
-		// Still growing, just append
-		s.entries = append(s.entries, entry)
-		return false
-	} else {
-		// Full, overwrite oldest and advance start pointer
-		s.entries[s.start] = entry
-		s.start = (s.start + 1) % s.capacity
-		return true // Buffer wrapped
+	wrapped := s.Len() == s.capacity
+	if wrapped {
+		// Full, drop the oldest entry
+		s.levels[s.entries[s.start].Level]--
+		s.entries[s.start] = logEntry{} // Release its strings
+		s.start++
+		s.dropped++
+
+		// Window at the end of the backing array: move it to the front
+		if len(s.entries) == cap(s.entries) {
+			n := copy(s.entries, s.entries[s.start:])
+			clear(s.entries[n:])
+			s.entries = s.entries[:n]
+			s.start = 0
+		}
 	}
+	s.entries = append(s.entries, entry)
+	s.levels[entry.Level]++
+	return wrapped
 }
 
 // Len returns the number of entries currently stored
 func (s *logStore) Len() int {
-	return len(s.entries)
+	return len(s.entries) - s.start
 }
 
-// Slice returns a linearized copy of all entries in chronological order
-func (s *logStore) Slice() []logEntry {
-	if len(s.entries) < s.capacity {
-		// Not full yet, return as-is
-		return s.entries
-	}
+// Dropped returns how many entries the buffer has overwritten. Entry i of
+// Slice is the (Dropped()+i)th entry ever appended, which lets caches keyed
+// by index follow the buffer as it wraps.
+func (s *logStore) Dropped() int {
+	return s.dropped
+}
 
-	// Full buffer, need to linearize circular structure
-	result := make([]logEntry, s.capacity)
-	copy(result, s.entries[s.start:])
-	copy(result[s.capacity-s.start:], s.entries[:s.start])
-	return result
+// Edits returns how many times entries were updated in place
+func (s *logStore) Edits() int {
+	return s.edits
+}
+
+// Levels returns the number of stored entries per level
+func (s *logStore) Levels() levelCounts {
+	return s.levels
+}
+
+// Slice returns all entries in chronological order. It shares the store's
+// memory: callers must not modify it or keep it across appends.
+func (s *logStore) Slice() []logEntry {
+	return s.entries[s.start:]
 }
 
-// UpdateEntry updates an entry at the given index (for reprocessing)
+// UpdateEntry updates an entry at the given index (for reprocessing).
+// The index is chronological, as returned by Slice.
 func (s *logStore) UpdateEntry(index int, entry logEntry) {
-	if index < 0 || index >= len(s.entries) {
+	if index < 0 || index >= s.Len() {
 		return // Bounds check
 	}
-	s.entries[index] = entry
+	slot := &s.entries[s.start+index]
+	s.levels[slot.Level]--
+	s.levels[entry.Level]++
+	*slot = entry
+	s.edits++
 }
@@ -149,6 +149,25 @@ func TestLogStore(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("UpdateAfterWrap", func(t *testing.T) {
+		// Arrange - A B C D in a store of 3 keeps B C D
+		store := createTestLogStore(3)
+		for _, msg := range []string{"A", "B", "C", "D"} {
+			store.Append(createTestLogEntry(msg))
+		}
+
+		// Act - index 0 is the oldest entry in Slice order
+		store.UpdateEntry(0, createTestLogEntry("b"))
+
+		// Assert
+		logs := store.Slice()
+		for i, want := range []string{"b", "C", "D"} {
+			assertStringEqual(t, logs[i].Message, want)
+		}
+		assertIntEqual(t, store.Dropped(), 1, "overwritten entries")
+		assertIntEqual(t, store.Edits(), 1, "updates")
+	})
 }
 
 // Benchmark tests for performance validation
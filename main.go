@@ -17,6 +17,7 @@ func main() {
 	flagVersion := flag.Bool("version", false, "show version")
 	flagProfile := flag.String("profile", "", "AWS profile to use (skips profile selection)")
 	flagRegion := flag.String("region", "", "AWS region to use (overrides profile default)")
+	flagConfig := flag.String("config", defaultConfigPath(), "path to the JSON config file")
 	flagHelp := flag.Bool("help", false, "show help")
 	
 	// Custom usage function
@@ -53,13 +54,22 @@ func main() {
 
 	// Load configuration
 	uiConfig := NewUIConfig()
+	configFile, err := loadConfigFile(*flagConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if colorDisabled() {
+		uiConfig.disableColor()
+	}
+	configFile.apply(uiConfig)
+	uiConfig.StatePath = defaultStatePath()
 
 	// Display welcome message
-	displayWelcome()
+	displayWelcome(uiConfig)
 
 	// Select or use provided AWS profile and region
 	var profile, region string
-	var err error
 	
 	// Parse profile from flag or positional argument
 	if *flagProfile != "" {
@@ -183,7 +193,7 @@ func main() {
 		}
 
 		// Display success message and controls
-		displayConnectionSuccess(profile, chosenLogGroup)
+		displayConnectionSuccess(profile, chosenLogGroup, uiConfig)
 
 		// Inner loop for log viewer (allows going back to log group selection)
 		for {
@@ -226,12 +236,15 @@ func startLogViewer(profile, logGroupName, region string, uiConfig *UIConfig) (i
 		return 0, err
 	}
 
+	// Merge per-log-group profile overrides onto the base settings
+	uiConfig = uiConfig.ForLogGroup(logGroupName)
+
 	model := logModel{
 		profile:          profile,
 		logGroup:         logGroupName,
 		client:           client,
 		config:           uiConfig,
-		store:            newLogStore(5000), // Fixed capacity ring buffer
+		store:            newLogStore(uiConfig.MaxLogBuffer), // Fixed capacity ring buffer
 		height:           uiConfig.DefaultHeight,
 		width:            uiConfig.DefaultWidth,
 		initialLoad:      true,
@@ -240,7 +253,11 @@ func startLogViewer(profile, logGroupName, region string, uiConfig *UIConfig) (i
 		currentTimeRange: uiConfig.LogTimeRange,
 		lastFormatState:  uiConfig.ParseAccessLogs, // Initialize with current config state
 		highlighted:      make(map[int]string),     // Initialize highlighted cache
+		minLevel:         uiConfig.MinLevel,
 	}
+	model.columns = initialColumns(uiConfig, logGroupName)
+	model.columnsMode = len(model.columns) > 0
+	model.highlightRules = initialHighlightRules(uiConfig)
 
 	// Use alt-screen mode without mouse capture to allow normal text selection
 	p := tea.NewProgram(&model, tea.WithAltScreen())
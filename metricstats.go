@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*
+Metric Statistics
+
+M opens an overlay that aggregates every EMF metric (see parser_emf.go) in the
+buffer, per namespace, metric and dimension set:
+
+	Namespace  Metric   Dimensions                  Count  Min  Max    Avg    Unit
+	Shop       Latency  Service=cart,Operation=Add  42     3.1  120.5  18.27  Milliseconds
+
+The statistics are computed when the overlay opens; reopen it to include
+newer events. It scrolls with the movement keys; M or esc closes it.
+*/
+
+// metricStat aggregates the values of one metric and dimension set
+type metricStat struct {
+	emfMetric // Values stays empty; the aggregate is kept instead
+	Count     int
+	Min, Max  float64
+	Sum       float64
+}
+
+// metricStats is the state of the statistics overlay
+type metricStats struct {
+	stats  []*metricStat
+	offset int
+}
+
+// aggregateMetrics computes the statistics of every EMF event among logs
+func aggregateMetrics(logs []logEntry) []*metricStat {
+	byKey := make(map[string]*metricStat)
+	var stats []*metricStat
+	for i := range logs {
+		if logs[i].Format != emfParserName {
+			continue
+		}
+		for _, metric := range emfMetrics(logs[i].Fields) {
+			key := metric.Namespace + "\x00" + metric.Name + "\x00" + metric.Dimensions
+			stat := byKey[key]
+			if stat == nil {
+				stat = &metricStat{emfMetric: metric, Min: metric.Values[0], Max: metric.Values[0]}
+				stat.Values = nil
+				byKey[key] = stat
+				stats = append(stats, stat)
+			}
+			for _, value := range metric.Values {
+				stat.Count++
+				stat.Sum += value
+				stat.Min = math.Min(stat.Min, value)
+				stat.Max = math.Max(stat.Max, value)
+			}
+		}
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Dimensions < b.Dimensions
+	})
+	return stats
+}
+
+// openMetricStats shows the statistics overlay, or explains why there is none
+func (m *logModel) openMetricStats() {
+	stats := aggregateMetrics(m.safeLogs())
+	if len(stats) == 0 {
+		m.formatStatusMsg = "No EMF metrics in the buffer"
+		return
+	}
+	m.stats = &metricStats{stats: stats}
+	m.followMode = false
+}
+
+// handleStatsKey scrolls or closes the statistics overlay
+func (m *logModel) handleStatsKey(action string) {
+	s := m.stats
+	height := m.detailHeight() - 1 // The column names take a line
+	scroll := func(delta int) {
+		s.offset = max(0, min(s.offset+delta, len(s.stats)-height))
+	}
+	switch action {
+	case actionMetrics, actionClearSearch, actionBack:
+		m.stats = nil
+	case actionUp:
+		scroll(-1)
+	case actionDown:
+		scroll(1)
+	case actionPageUp:
+		scroll(-height)
+	case actionPageDown:
+		scroll(height)
+	case actionTop:
+		s.offset = 0
+	case actionBottom:
+		scroll(len(s.stats))
+	}
+}
+
+// renderStats renders the statistics overlay as aligned columns
+func (m *logModel) renderStats() string {
+	s := m.stats
+	height := m.detailHeight() - 1
+	end := min(len(s.stats), s.offset+height)
+
+	header := []string{"Namespace", "Metric", "Dimensions", "Count", "Min", "Max", "Avg", "Unit"}
+	rows := [][]string{header}
+	for _, stat := range s.stats[s.offset:end] {
+		dimensions := stat.Dimensions
+		if dimensions == "" {
+			dimensions = "-"
+		}
+		rows = append(rows, []string{
+			stat.Namespace, stat.Name, dimensions, strconv.Itoa(stat.Count),
+			formatMetricValue(stat.Min), formatMetricValue(stat.Max),
+			formatMetricValue(stat.Sum / float64(stat.Count)), stat.Unit,
+		})
+	}
+
+	table := columnTable{widths: make([]int, len(header))}
+	for _, row := range rows {
+		for i, cell := range row {
+			table.widths[i] = min(maxColumnWidth, max(table.widths[i], lipgloss.Width(cell)))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(m.config.HeaderStyle().Render(table.padColumns(header)) + "\n")
+	for _, row := range rows[1:] {
+		b.WriteString(table.padColumns(row) + "\n")
+	}
+
+	keys := m.config.Keys
+	footer := fmt.Sprintf("Metrics %d-%d of %d · %s or %s to close",
+		min(s.offset+1, end), end, len(s.stats),
+		keys.hint(keyContextViewer, actionMetrics), keys.hint(keyContextViewer, actionClearSearch))
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(m.config.Colors.BorderColor)).
+		Padding(0, 1).
+		Render(m.config.HeaderStyle().Render("EMF metrics") + "\n" + b.String() + m.config.MutedStyle().Render(footer))
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
@@ -52,6 +54,17 @@ type logModel struct {
 	searchMode       bool
 	searchQuery      string
 	searchRegex      *regexp.Regexp
+	searchOptions    searchOptions // Literal or regex, case and whole-word matching (see search.go)
+	searchError      string        // Compile error of the query being typed
+	filter           *logFilter    // Hides rows that do not match, nil when off (see filter.go)
+	filterMode       bool          // The filter prompt is open
+	filterQuery      string        // Filter being typed
+	filterError      string        // Compile error of the filter being typed
+	filterContext    int           // Rows shown before and after each filter match
+	highlightRules   []HighlightRule // Named highlight rules (see highlightrules.go)
+	ruleMode         bool            // The highlight rule prompt is open
+	ruleQuery        string          // Rule being typed
+	ruleError        string          // Compile error of the rule being typed
 	matches          []int
 	currentMatch     int
 	height           int
@@ -72,6 +85,25 @@ type logModel struct {
 	highlighted         map[int]string // Cache of highlighted lines (by index)
 	lastSearchQuery     string         // Track last search query to avoid reprocessing
 	backToLogGroups     bool           // Flag to indicate user wants to go back to log group selection
+	showHelp            bool           // Help overlay listing the active key bindings
+	pendingKeys         []string       // Keys typed so far of a multi-key binding
+	minLevel            logLevel       // Hide entries below this level (levelNone shows all)
+	groupInvocations    bool           // Show Lambda lines grouped by invocation
+	rejectsOnly         bool           // Show only rejected VPC flow log records
+	detail              *detailView    // Detail overlay of one entry, nil when closed
+	tracesExpanded      bool           // Stack traces start expanded instead of folded
+	toggledTraces       map[int]bool   // Folds toggled with z, by traceKey of the head row
+	columns             []string       // Field paths of columns mode
+	columnsMode         bool           // Show entries as aligned field columns
+	picker              *columnPicker  // Column picker overlay, nil when closed
+	stats               *metricStats   // EMF metric statistics overlay, nil when closed
+	searchGen           int                // Generation of the current search scan (see searchjob.go)
+	searchCancel        context.CancelFunc // Cancels the background scan, nil when none runs
+	searchPending       string             // Query of the background scan, "" when none runs
+	rulePicker          *rulePicker    // Highlight rule list overlay, nil when closed
+	traceToggles        int            // Counts fold toggles, part of the row layout key
+	flowLogOrders       map[string][]string // Field order of the last VPC flow log header per stream
+	rowCache            rowCache       // Row layout of the last frame (see viewrows.go)
 }
 
 // safeLogs returns logs safely, never panics
@@ -98,9 +130,14 @@ func (m *logModel) fixCursor() {
 		m.cursor = 0
 	}
 
-	// Follow mode: always track latest
-	if m.followMode {
-		m.cursor = len(logs) - 1
+	// Follow mode: always track latest; otherwise stay on a visible row
+	rows := m.visibleRows(logs)
+	if len(rows) > 0 {
+		if m.followMode {
+			m.cursor = rows[len(rows)-1]
+		} else {
+			m.cursor = rows[m.rowPosition(rows)]
+		}
 	}
 
 	// Perform lazy reprocessing when cursor moves
@@ -115,12 +152,16 @@ func (m *logModel) centerOnCursor() {
 
 // Init initializes the model
 func (m *logModel) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.fetchLogs(),
 		tea.Tick(time.Duration(m.config.RefreshInterval)*time.Second, func(t time.Time) tea.Msg {
 			return tickMsg(t)
 		}),
-	)
+	}
+	if m.config.TimeFormat == timeFormatRelative {
+		cmds = append(cmds, relativeTickCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles messages and updates the model
@@ -131,98 +172,7 @@ func (m *logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 
 	case tea.KeyMsg:
-		key := msg.String()
-
-		// Handle global keys that work in any mode
-		switch key {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "J":
-			cmd := m.toggleFormat()
-			return m, cmd
-		case "F":
-			cmd := m.toggleFollow()
-			return m, cmd
-		}
-
-		if m.searchMode {
-			switch key {
-			case "enter":
-				m.searchMode = false
-				m.performSearch()
-				m.followMode = false
-			case "esc":
-				m.searchMode = false
-				m.searchQuery = ""
-				m.matches = nil
-				m.followMode = false
-			case "backspace":
-				if len(m.searchQuery) > 0 {
-					m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
-				}
-			default:
-				if len(key) == 1 && key != "J" && key != "F" {
-					m.searchQuery += key
-				}
-			}
-		} else {
-			switch key {
-			case "/":
-				m.searchMode = true
-				m.searchQuery = ""
-				m.followMode = false
-			case "esc":
-				// Clear search results and return to normal browsing
-				m.clearSearchState()
-			case "b", "backspace":
-				// Return to log group selection
-				return m, backToLogGroupsCmd
-			case "n":
-				m.nextMatch()
-				m.followMode = false
-			case "N":
-				m.prevMatch()
-				m.followMode = false
-			case "up", "k":
-				m.cursor--
-				m.followMode = false
-				m.fixCursor()
-			case "down", "j":
-				m.cursor++
-				m.fixCursor()
-			case "pageup", "ctrl+b":
-				m.cursor -= m.height
-				m.followMode = false
-				m.fixCursor()
-			case "pagedown", "ctrl+f":
-				m.cursor += m.height
-				m.fixCursor()
-			case "g":
-				m.cursor = 0
-				m.followMode = false
-				m.fixCursor()
-			case "G":
-				m.followMode = true
-				m.fixCursor()
-				// Start tick cycle for follow mode
-				return m, tea.Tick(time.Duration(m.config.RefreshInterval)*time.Second, func(t time.Time) tea.Msg {
-					return tickMsg(t)
-				})
-			case "H":
-				return m, m.fetchHistoryLogs()
-			case "c":
-				// Copy current log line to clipboard
-				return m, m.copyCurrentLine()
-			case "end":
-				// Jump to latest logs (same as G but more intuitive)
-				m.followMode = true
-				m.fixCursor()
-				// Start tick cycle for follow mode
-				return m, tea.Tick(time.Duration(m.config.RefreshInterval)*time.Second, func(t time.Time) tea.Msg {
-					return tickMsg(t)
-				})
-			}
-		}
+		return m, m.handleKey(msg.String())
 
 	case tickMsg:
 		// Only fetch logs and schedule next tick if follow mode is enabled
@@ -237,10 +187,30 @@ func (m *logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// If follow mode is disabled, don't schedule another tick
 		return m, nil
 
+	case relativeTickMsg:
+		// Keep relative timestamps current until another format is chosen
+		if m.config.TimeFormat == timeFormatRelative {
+			m.restampEntries()
+			return m, relativeTickCmd()
+		}
+		return m, nil
+
 	case delayedSearchMsg:
 		// Handle delayed search after buffer rollover
 		m.searchQuery = msg.query
-		m.performSearch()
+		return m, m.searchCmd(false)
+
+	case searchDebounceMsg:
+		// Typing paused: scan unless another key arrived since
+		if msg.gen == m.searchGen {
+			return m, m.startSearchScan()
+		}
+
+	case searchResultMsg:
+		// Results of an outdated query are dropped
+		if msg.gen == m.searchGen {
+			m.applySearchResult(msg)
+		}
 
 	case loadingMsg:
 		m.loading = bool(msg)
@@ -262,6 +232,7 @@ func (m *logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Append to ring buffer and detect if it wrapped
 			wrapped := false
 			for _, log := range msg.logs {
+				m.followFlowLogHeader(&log) // Records follow their stream's header
 				if m.store.Append(log) {
 					wrapped = true
 				}
@@ -289,9 +260,8 @@ func (m *logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Always scroll to bottom when follow mode is on
 			if m.followMode {
-				logs := m.safeLogs()
-				if len(logs) > 0 {
-					m.cursor = len(logs) - 1
+				if rows := m.visibleRows(m.safeLogs()); len(rows) > 0 {
+					m.cursor = rows[len(rows)-1]
 				}
 			}
 		}
@@ -347,6 +317,231 @@ func (m *logModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleKey dispatches a key press through the key map of the active mode
+func (m *logModel) handleKey(key string) tea.Cmd {
+	keys := m.config.Keys
+
+	if m.searchMode {
+		return m.handlePromptKey(key)
+	}
+	if m.filterMode {
+		return m.handleFilterPromptKey(key)
+	}
+	if m.ruleMode {
+		return m.handleRulePromptKey(key)
+	}
+
+	// The detail view has its own key context
+	if m.detail != nil {
+		action, pending := keys.resolve(keyContextDetail, m.pendingKeys, key)
+		m.pendingKeys = pending
+		return m.handleDetailKey(action)
+	}
+
+	action, pending := keys.resolve(keyContextViewer, m.pendingKeys, key)
+	m.pendingKeys = pending
+
+	// The help overlay swallows everything except closing it and quitting
+	if m.showHelp {
+		switch action {
+		case actionQuit:
+			return tea.Quit
+		case actionHelp, actionClearSearch:
+			m.showHelp = false
+		}
+		return nil
+	}
+
+	// The column picker overlay takes the movement keys
+	if m.picker != nil {
+		if action == actionQuit {
+			return tea.Quit
+		}
+		return m.handlePickerKey(action)
+	}
+	if m.stats != nil {
+		if action == actionQuit {
+			return tea.Quit
+		}
+		m.handleStatsKey(action)
+		return nil
+	}
+	if m.rulePicker != nil {
+		if action == actionQuit {
+			return tea.Quit
+		}
+		return m.handleRulePickerKey(action)
+	}
+
+	switch action {
+	case actionQuit:
+		return tea.Quit
+	case actionHelp:
+		m.showHelp = true
+	case actionDetail:
+		m.openDetail()
+	case actionFormat:
+		return m.toggleFormat()
+	case actionFollow:
+		return m.toggleFollow()
+	case actionTheme:
+		return m.cycleTheme()
+	case actionSearch:
+		m.searchMode = true
+		m.searchQuery = ""
+		m.searchError = ""
+		m.followMode = false
+	case actionClearSearch:
+		// Clear search results and return to normal browsing
+		m.clearSearchState()
+	case actionBack:
+		// Return to log group selection
+		return backToLogGroupsCmd
+	case actionNextMatch:
+		m.nextMatch()
+		m.followMode = false
+	case actionPrevMatch:
+		m.prevMatch()
+		m.followMode = false
+	case actionUp:
+		m.moveCursor(-1)
+		m.followMode = false
+		m.fixCursor()
+	case actionDown:
+		m.moveCursor(1)
+		m.fixCursor()
+	case actionPageUp:
+		m.moveCursor(-m.height)
+		m.followMode = false
+		m.fixCursor()
+	case actionPageDown:
+		m.moveCursor(m.height)
+		m.fixCursor()
+	case actionTop:
+		m.cursor = 0
+		m.followMode = false
+		m.fixCursor()
+	case actionLevel:
+		return m.cycleLevelThreshold()
+	case actionInvocations:
+		return m.toggleInvocationGrouping()
+	case actionRejects:
+		return m.toggleRejectFilter()
+	case actionFold:
+		m.toggleTraceAtCursor()
+		return clearFormatStatusCmd()
+	case actionColumns:
+		return m.toggleColumnsMode()
+	case actionPickColumns:
+		m.openColumnPicker()
+		return clearFormatStatusCmd()
+	case actionFoldAll:
+		m.toggleAllTraces()
+		return clearFormatStatusCmd()
+	case actionBottom:
+		// Jump to latest logs and start the tick cycle for follow mode
+		m.followMode = true
+		m.fixCursor()
+		return tea.Tick(time.Duration(m.config.RefreshInterval)*time.Second, func(t time.Time) tea.Msg {
+			return tickMsg(t)
+		})
+	case actionMetrics:
+		m.openMetricStats()
+		return clearFormatStatusCmd()
+	case actionTimestamps:
+		return m.cycleTimeFormat()
+	case actionIngestion:
+		return m.toggleIngestion()
+	case actionReveal:
+		return m.toggleReveal()
+	case actionFilter:
+		m.openFilterPrompt()
+	case actionHighlight:
+		m.openRulePrompt()
+	case actionRuleList:
+		m.openRulePicker()
+		return clearFormatStatusCmd()
+	case actionMoreContext:
+		return m.changeFilterContext(1)
+	case actionLessContext:
+		return m.changeFilterContext(-1)
+	case actionHistory:
+		return m.fetchHistoryLogs()
+	case actionCopy:
+		// Copy current log line to clipboard
+		return m.copyCurrentLine()
+	}
+	return nil
+}
+
+// handlePromptKey edits the search prompt; printable keys are always typed
+func (m *logModel) handlePromptKey(key string) tea.Cmd {
+	action, pending := m.config.Keys.resolve(keyContextPrompt, m.pendingKeys, key)
+	m.pendingKeys = pending
+
+	switch action {
+	case actionSubmit:
+		if m.searchError != "" {
+			return nil // Keep the prompt open until the pattern compiles
+		}
+		m.searchMode = false
+		m.followMode = false
+		return m.searchCmd(false)
+	case actionCancel:
+		m.searchMode = false
+		m.searchQuery = ""
+		m.searchError = ""
+		m.clearSearchState() // Drops the matches found while typing
+		m.followMode = false
+	default:
+		cmd := m.editPrompt(&m.searchQuery, action, key, pending)
+		m.checkSearchQuery()
+		if m.searchError != "" {
+			return cmd // Matches of the last valid query stay until the pattern compiles
+		}
+		return tea.Batch(cmd, m.searchCmd(true))
+	}
+	return nil
+}
+
+// editPrompt applies a key to the text of a prompt; printable keys are always typed
+func (m *logModel) editPrompt(text *string, action, key string, pending []string) tea.Cmd {
+	switch action {
+	case actionDeleteChar:
+		*text = trimLastRune(*text)
+	case actionDeleteWord:
+		*text = trimLastWord(*text)
+	case actionSearchRegex, actionSearchCase, actionSearchWord:
+		m.toggleSearchOption(action)
+	default:
+		if len(pending) > 0 {
+			return nil
+		}
+		if len([]rune(key)) == 1 {
+			*text += key
+		} else if quit, _ := m.config.Keys.resolve(keyContextViewer, nil, key); quit == actionQuit {
+			// Non-printable quit keys (ctrl+c) still work while typing
+			return tea.Quit
+		}
+	}
+	return nil
+}
+
+// trimLastRune removes the last character of s
+func trimLastRune(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	return string(r[:len(r)-1])
+}
+
+// trimLastWord removes the last word of s and the spaces after it, like ctrl+w in a shell
+func trimLastWord(s string) string {
+	s = strings.TrimRight(s, " ")
+	return s[:strings.LastIndex(s, " ")+1]
+}
+
 // toggleFormat toggles log formatting between raw and formatted
 func (m *logModel) toggleFormat() tea.Cmd {
 	// Flip state
@@ -380,6 +575,77 @@ func (m *logModel) toggleFormat() tea.Cmd {
 	return clearFormatStatusCmd()
 }
 
+// cycleTheme switches to the next color theme and re-renders colored entries
+func (m *logModel) cycleTheme() tea.Cmd {
+	if m.config.NoColor {
+		m.formatStatusMsg = "Colors disabled (NO_COLOR or dumb terminal)"
+		return clearFormatStatusCmd()
+	}
+
+	name := m.config.NextTheme()
+
+	// Formatted entries carry the old theme's colors
+	m.reprocessVisibleLogs()
+	m.needsLazyReprocess = true
+	if len(m.matches) > 0 {
+		m.applyHighlights()
+	}
+
+	m.formatStatusMsg = fmt.Sprintf("Theme: %s", name)
+	return clearFormatStatusCmd()
+}
+
+// cycleLevelThreshold raises the minimum level shown, wrapping back to all levels
+func (m *logModel) cycleLevelThreshold() tea.Cmd {
+	m.minLevel = nextLevelThreshold(m.minLevel)
+	m.fixCursor()
+
+	// Hidden entries must not stay in the match list
+	search := m.research()
+
+	if m.minLevel == levelNone {
+		m.formatStatusMsg = "Showing all levels"
+	} else {
+		m.formatStatusMsg = fmt.Sprintf("Showing %s and above", m.minLevel)
+	}
+	return tea.Batch(search, clearFormatStatusCmd())
+}
+
+// toggleInvocationGrouping switches grouping of Lambda lines by request ID
+func (m *logModel) toggleInvocationGrouping() tea.Cmd {
+	m.groupInvocations = !m.groupInvocations
+	m.fixCursor()
+
+	if !m.groupInvocations {
+		m.formatStatusMsg = "Invocation grouping off"
+		return clearFormatStatusCmd()
+	}
+
+	total, cold, timedOut := invocationStats(m.layoutRows(m.safeLogs()).headers)
+	if total == 0 {
+		m.formatStatusMsg = "Invocation grouping on (no Lambda request IDs found)"
+	} else {
+		m.formatStatusMsg = fmt.Sprintf("Grouped %d invocations: %d cold starts, %d timeouts", total, cold, timedOut)
+	}
+	return clearFormatStatusCmd()
+}
+
+// toggleRejectFilter switches between all rows and rejected flow log records only
+func (m *logModel) toggleRejectFilter() tea.Cmd {
+	m.rejectsOnly = !m.rejectsOnly
+	m.fixCursor()
+
+	// Hidden entries must not stay in the match list
+	search := m.research()
+
+	if m.rejectsOnly {
+		m.formatStatusMsg = "Showing rejected flows only"
+	} else {
+		m.formatStatusMsg = "Showing all flows"
+	}
+	return tea.Batch(search, clearFormatStatusCmd())
+}
+
 // reprocessVisibleLogs regenerates visible logs based on the current format setting
 func (m *logModel) reprocessVisibleLogs() {
 	logs := m.safeLogs()
@@ -391,13 +657,10 @@ func (m *logModel) reprocessVisibleLogs() {
 	const uiReservedHeight = 6 // Header + status + borders
 	viewportHeight := m.height - uiReservedHeight
 	bufferSize := viewportHeight * 2 // 2x viewport for smooth scrolling
-	
-	start := max(0, m.cursor - bufferSize)
-	end := min(len(logs), m.cursor + bufferSize)
 
-	// Only reprocess visible range + buffer for better performance
-	for i := start; i < end; i++ {
-		entry := makeLogEntry(logs[i].Timestamp, logs[i].OriginalMessage, m.config)
+	// Only reprocess visible rows + buffer for better performance
+	for _, i := range m.rowWindow(m.visibleRows(logs), bufferSize) {
+		entry := reformatEntry(logs[i], m.config)
 		m.store.UpdateEntry(i, entry)
 	}
 }
@@ -424,12 +687,12 @@ func (m *logModel) lazyReprocessNearby() {
 	viewportHeight := m.height - uiReservedHeight
 	batchSize := max(30, viewportHeight/2) // Smaller batch size for better performance
 	
-	start := max(0, m.cursor - batchSize/2)
-	end := min(len(logs), start + batchSize)
+	rows := m.visibleRows(logs)
+	batch := m.rowWindow(rows, batchSize/2)
 
 	// Reprocess small batch around cursor
-	for i := start; i < end; i++ {
-		entry := makeLogEntry(logs[i].Timestamp, logs[i].OriginalMessage, m.config)
+	for _, i := range batch {
+		entry := reformatEntry(logs[i], m.config)
 		m.store.UpdateEntry(i, entry)
 	}
 
@@ -437,7 +700,7 @@ func (m *logModel) lazyReprocessNearby() {
 	m.lastLazyReprocess = m.cursor
 
 	// Check if we've processed all logs
-	if start == 0 && end == len(logs) {
+	if len(batch) == len(logs) {
 		m.needsLazyReprocess = false
 	}
 }
@@ -451,7 +714,7 @@ func (m *logModel) forceCompleteReprocess() {
 
 	// Reprocess all logs to ensure search accuracy
 	for i := 0; i < len(logs); i++ {
-		entry := makeLogEntry(logs[i].Timestamp, logs[i].OriginalMessage, m.config)
+		entry := reformatEntry(logs[i], m.config)
 		m.store.UpdateEntry(i, entry)
 	}
 
@@ -461,6 +724,7 @@ func (m *logModel) forceCompleteReprocess() {
 
 // clearSearchState clears all search-related state
 func (m *logModel) clearSearchState() {
+	m.cancelSearchScan()
 	m.searchRegex = nil
 	m.matches = nil
 	m.currentMatch = 0
@@ -474,9 +738,8 @@ func (m *logModel) toggleFollow() tea.Cmd {
 	m.followMode = !m.followMode
 	if m.followMode {
 		// Jump to the latest log immediately
-		logs := m.safeLogs()
-		if len(logs) > 0 {
-			m.cursor = len(logs) - 1
+		if rows := m.visibleRows(m.safeLogs()); len(rows) > 0 {
+			m.cursor = rows[len(rows)-1]
 		}
 		// Start the tick cycle for follow mode
 		return tea.Tick(time.Duration(m.config.RefreshInterval)*time.Second, func(t time.Time) tea.Msg {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -46,6 +47,9 @@ var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
 
 // stripANSI removes ANSI escape sequences from a string
 func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s // Uncolored lines need no copy
+	}
 	return ansiEscape.ReplaceAllString(s, "")
 }
 
@@ -95,13 +99,7 @@ func (m *logModel) fetchLogs() tea.Cmd {
 				return err
 			}
 
-			var logs []logEntry
-			for _, event := range output.Events {
-				if event.Timestamp != nil && event.Message != nil {
-					timestamp := time.UnixMilli(*event.Timestamp)
-					logs = append(logs, makeLogEntry(timestamp, *event.Message, m.config))
-				}
-			}
+			logs := eventEntries(output.Events, m.config)
 
 			// Return both logs and pagination info
 			return logsWithTokenMsg{logs, output.NextToken, m.initialLoad}
@@ -133,18 +131,30 @@ func (m *logModel) fetchHistoryLogs() tea.Cmd {
 			return err
 		}
 
-		var logs []logEntry
-		for _, event := range output.Events {
-			if event.Timestamp != nil && event.Message != nil {
-				timestamp := time.UnixMilli(*event.Timestamp)
-				logs = append(logs, makeLogEntry(timestamp, *event.Message, m.config))
-			}
-		}
+		logs := eventEntries(output.Events, m.config)
 
 		return logsWithTokenMsg{logs: logs, nextToken: nil, isInitial: false}
 	}
 }
 
+// eventEntries converts CloudWatch events to log entries, skipping incomplete events
+func eventEntries(events []types.FilteredLogEvent, cfg *UIConfig) []logEntry {
+	var logs []logEntry
+	for _, event := range events {
+		if event.Timestamp != nil && event.Message != nil {
+			timestamp := time.UnixMilli(*event.Timestamp)
+			entry := makeLogEntry(timestamp, *event.Message, cfg)
+			entry.Stream = aws.ToString(event.LogStreamName)
+			if event.IngestionTime != nil {
+				entry.IngestionTime = time.UnixMilli(*event.IngestionTime)
+				entry.restamp(cfg, time.Now())
+			}
+			logs = append(logs, entry)
+		}
+	}
+	return logs
+}
+
 // expandSearchWindow expands the search time window when no logs are found
 func (m *logModel) expandSearchWindow() tea.Cmd {
 	return func() tea.Msg {
@@ -198,48 +208,21 @@ func (m *logModel) performSearch() {
 	m.lastSearchQuery = m.searchQuery
 
 	// If we need lazy reprocessing, force complete reprocessing before search
-	// to ensure search results are accurate
+	// to ensure search results are accurate (small buffers only, see searchjob.go)
 	if m.needsLazyReprocess {
 		m.forceCompleteReprocess()
 	}
 
-	regex, err := regexp.Compile("(?i)" + regexp.QuoteMeta(m.searchQuery))
+	scan, err := m.newSearchScan()
 	if err != nil {
-		// Set status message for regex error
-		m.statusMessage = fmt.Sprintf("Invalid search pattern: %v", err)
+		// Set status message for regex or query error
+		m.statusMessage = fmt.Sprintf("Invalid search pattern: %s", searchErrorText(err))
 		return
 	}
 
-	m.searchRegex = regex
-	m.matches = []int{}
-
-	// Always search full buffer, not just visible slice
-	logs := m.safeLogs()
-	for i, log := range logs {
-		// Always search in the display text (what user sees) to ensure highlighting works
-		displayText := stripANSI(log.Raw)
-		
-		if regex.MatchString(displayText) {
-			m.matches = append(m.matches, i)
-		}
-	}
-
-	if len(m.matches) > 0 {
-		// Start at the LAST match (most recent/latest log)
-		m.currentMatch = len(m.matches) - 1
-		// Defensive bounds check
-		if m.currentMatch >= 0 && m.currentMatch < len(m.matches) {
-			m.cursor = m.matches[m.currentMatch]
-		}
-		m.followMode = false // Prevent tick from overwriting cursor
-		m.centerOnCursor()   // Center viewport on found match
-		m.statusMessage = fmt.Sprintf("Found %d matches", len(m.matches))
-	} else {
-		m.statusMessage = fmt.Sprintf("No matches found for '%s'", m.searchQuery)
-	}
-
-	// Precompute highlighted lines
-	m.applyHighlights()
+	// Always search full buffer, not just visible slice; highlights come with the matches
+	result, _ := scan.run(context.Background())
+	m.applySearchResult(result)
 }
 
 // nextMatch moves to the next search match (backward in time to older logs)
@@ -247,6 +230,11 @@ func (m *logModel) nextMatch() {
 	if len(m.matches) == 0 {
 		return
 	}
+	previous := -1 // Row of the current match before moving
+	if m.currentMatch >= 0 && m.currentMatch < len(m.matches) {
+		previous = m.matches[m.currentMatch]
+	}
+
 	// Go backward in the matches array (to older logs)
 	m.currentMatch = (m.currentMatch - 1 + len(m.matches)) % len(m.matches)
 	
@@ -256,7 +244,8 @@ func (m *logModel) nextMatch() {
 	}
 	m.followMode = false        // Disable follow persistently
 	m.centerOnCursor()          // Center viewport on match
-	m.applyHighlights()         // Refresh all highlights with new current match
+	m.repaintMatch(previous, false) // Only the old and new current match change
+	m.repaintMatch(m.cursor, true)
 }
 
 // prevMatch moves to the previous search match (forward in time to newer logs)
@@ -264,6 +253,11 @@ func (m *logModel) prevMatch() {
 	if len(m.matches) == 0 {
 		return
 	}
+	previous := -1 // Row of the current match before moving
+	if m.currentMatch >= 0 && m.currentMatch < len(m.matches) {
+		previous = m.matches[m.currentMatch]
+	}
+
 	// Go forward in the matches array (to newer logs)
 	m.currentMatch = (m.currentMatch + 1) % len(m.matches)
 	
@@ -273,7 +267,8 @@ func (m *logModel) prevMatch() {
 	}
 	m.followMode = false        // Disable follow persistently
 	m.centerOnCursor()          // Center viewport on match
-	m.applyHighlights()         // Refresh all highlights with new current match
+	m.repaintMatch(previous, false) // Only the old and new current match change
+	m.repaintMatch(m.cursor, true)
 }
 
 // View renders the TUI
@@ -287,40 +282,53 @@ func (m *logModel) View() string {
 	}
 
 	// Header
-	header := m.config.HeaderStyle().Render(fmt.Sprintf("CloudWatch Logs: %s", m.logGroup))
+	headerText := fmt.Sprintf("CloudWatch Logs: %s", m.logGroup)
+	if m.config.ActiveProfile != "" {
+		headerText += fmt.Sprintf(" [settings: %s]", m.config.ActiveProfile)
+	}
+	header := m.config.HeaderStyle().Render(headerText) + m.config.redactionIndicator()
+	if m.filter != nil {
+		logs := m.safeLogs()
+		shown, _ := m.shownRows(logs)
+		header += m.filterIndicator(len(shown), len(logs))
+	}
 
 	// Build status line
 	var statusBar string
 
 	switch {
-	case m.searchMode:
+	case len(m.pendingKeys) > 0:
 		statusBar = m.config.SearchStyle().
-			Render(fmt.Sprintf("Search: %s_ (follow disabled)", m.searchQuery))
+			Render(fmt.Sprintf("Keys: %s-", keySequenceLabel(m.pendingKeys)))
+	case m.searchMode:
+		statusBar = m.searchPromptStatus()
+	case m.filterMode:
+		statusBar = m.filterPromptStatus()
+	case m.ruleMode:
+		statusBar = m.rulePromptStatus()
 	case len(m.matches) > 0:
+		keys := m.config.Keys
 		statusBar = m.config.MatchStyle().
-			Render(fmt.Sprintf("Matches: %d/%d (follow disabled) | n=next, N=prev, /=new search",
-				m.currentMatch+1, len(m.matches)))
+			Render(fmt.Sprintf("Matches: %d/%d [%s] (follow disabled) | %s=next, %s=prev, %s=new search",
+				m.currentMatch+1, len(m.matches), m.searchModeLabel(m.searchQuery),
+				keys.hint(keyContextViewer, actionNextMatch), keys.hint(keyContextViewer, actionPrevMatch),
+				keys.hint(keyContextViewer, actionSearch)))
 	case m.formatStatusMsg != "":
-		statusBar = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("10")).
+		statusBar = m.config.InfoStyle().
 			Render(fmt.Sprintf("⚙️  %s", m.formatStatusMsg))
 	case m.statusMessage != "":
-		statusBar = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11")).
+		statusBar = m.config.WarningStyle().
 			Render(fmt.Sprintf("🔍 %s", m.statusMessage))
 	case m.loading:
-		statusBar = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11")).
+		statusBar = m.config.WarningStyle().
 			Render("⏳ Loading logs...")
 	case m.lastError != nil:
-		statusBar = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("9")).
+		statusBar = m.config.ErrorStyle().
 			Render(fmt.Sprintf("❌ Error: %v", m.lastError))
 	}
 
 	// Always render the controls menu below the status
-	controls := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+	controls := m.config.MutedStyle().
 		BorderTop(true).
 		BorderStyle(lipgloss.NormalBorder()).
 		PaddingTop(1).
@@ -332,39 +340,94 @@ func (m *logModel) View() string {
 		statusBar = controls
 	}
 
+	// Help overlay replaces the log viewport
+	if m.showHelp {
+		return lipgloss.JoinVertical(lipgloss.Left, header, statusBar, "", m.renderHelp())
+	}
+	if m.detail != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, header, statusBar, "", m.renderDetail())
+	}
+	if m.picker != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, header, statusBar, "", m.renderPicker())
+	}
+	if m.stats != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, header, statusBar, "", m.renderStats())
+	}
+	if m.rulePicker != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, header, statusBar, "", m.renderRulePicker())
+	}
+
 	// Get logs safely
 	logs := m.safeLogs()
 	if len(logs) == 0 {
 		return lipgloss.JoinVertical(lipgloss.Left, header, statusBar, "", "No logs yet")
 	}
 
-	// Calculate viewport
+	layout := m.layoutRows(logs)
+	rows := layout.rows
+	if len(rows) == 0 {
+		empty := fmt.Sprintf("No %s or higher logs (press %s to show more levels)",
+			m.minLevel, m.config.Keys.hint(keyContextViewer, actionLevel))
+		if m.rejectsOnly {
+			empty = fmt.Sprintf("No rejected flows (press %s to show all)", m.config.Keys.hint(keyContextViewer, actionRejects))
+		}
+		if m.filter != nil {
+			empty = fmt.Sprintf("No lines match the filter (press %s to change it)", m.config.Keys.hint(keyContextViewer, actionFilter))
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, header, statusBar, "", empty)
+	}
+
+	// Calculate viewport; columns mode spends a line on the column names
 	viewportHeight := m.height - uiReservedHeight
-	start := m.cursor - viewportHeight/2 // Center cursor in viewport
+	showColumns := m.columnsMode && len(m.columns) > 0
+	if showColumns {
+		viewportHeight--
+	}
+	cursorRow := m.rowPosition(rows)
+	start := cursorRow - viewportHeight/2 // Center cursor in viewport
 	if start < 0 {
 		start = 0
 	}
 	end := start + viewportHeight // Reserve space for UI elements
-	if end > len(logs) {
-		end = len(logs)
+	if end > len(rows) {
+		end = len(rows)
 		start = end - viewportHeight
 		if start < 0 {
 			start = 0
 		}
 	}
+	start, end = layout.fitWindow(start, end, cursorRow, viewportHeight) // Headers and separators take lines too
 
 	// Build log content with clean visual isolation
 	var logContent strings.Builder
 	logContent.Grow(4096)
 
-	for i := start; i < end && i < len(logs); i++ {
+	var table columnTable
+	if showColumns {
+		table = layoutColumns(logs, rows[start:end], m.columns)
+		stampWidth := lipgloss.Width(timestampPrefix(&logs[rows[start]], m.config, time.Now()))
+		logContent.WriteString(m.config.HeaderStyle().Render(table.header(m.columns, stampWidth)) + "\n")
+	}
+
+	for pos := start; pos < end; pos++ {
+		i := rows[pos]
 		entry := logs[i]
 		line := entry.Raw
 
-		// 1) Use precomputed highlight if present
-		if hl, ok := m.highlighted[i]; ok {
+		// 1) Use precomputed highlight if present; collapsed traces show their exception line
+		fold := layout.folds[i]
+		folded := fold != nil && !m.traceExpanded(i)
+		hl, hasHighlight := m.rowHighlight(i, &entry)
+		if hasHighlight && !folded {
 			line = hl
 		}
+		if folded {
+			line = fold.render(logs, m.config)
+		} else if showColumns {
+			if columns, ok := table.row(&entry, i, m.config); ok {
+				line = columns
+			}
+		}
 
 		// 2) Soft-wrap BEFORE styling so styles don't get re-rendered
 		if m.width > contentPadding {
@@ -376,12 +439,26 @@ func (m *logModel) View() string {
 		// 3) Split multi-line entries to isolate per-visual line rendering
 		subLines := strings.Split(line, "\n")
 
+		// Filter context groups are separated like grep -C output
+		if layout.breaks[i] {
+			logContent.WriteString(m.config.MutedStyle().Render("--") + "\n")
+		}
+
+		// Invocation grouping: header above the group, member lines indented
+		if inv := layout.headers[i]; inv != nil {
+			logContent.WriteString(inv.header(m.config) + "\n")
+		}
+		indent := ""
+		if layout.grouped[i] {
+			indent = "  "
+		}
+
 		for j, sub := range subLines {
 			var rendered string
 
 			// 4) Apply cursor ONLY to the selected logical row (first subline)
 			if i == m.cursor && j == 0 {
-				if _, hasHighlight := m.highlighted[i]; hasHighlight {
+				if hasHighlight {
 					// Don't overwrite highlights - just add cursor indicator
 					rendered = "▌ " + sub
 				} else {
@@ -389,22 +466,25 @@ func (m *logModel) View() string {
 					rendered = m.config.CursorStyle().Render(sub)
 				}
 			} else {
-				// Base zebra for this logical row (NOT per subline)
-				base := m.config.EvenRowStyle()
-				if i%2 != 0 {
-					base = m.config.OddRowStyle()
+				// Level color, or base zebra for this logical row (NOT per subline)
+				base, ok := m.config.LevelStyle(entry.Level)
+				if !ok {
+					base = m.config.EvenRowStyle()
+					if pos%2 != 0 {
+						base = m.config.OddRowStyle()
+					}
 				}
 				rendered = base.Render(sub)
 			}
 
-			logContent.WriteString(rendered)
+			logContent.WriteString(indent + rendered)
 			if j < len(subLines)-1 {
 				logContent.WriteString("\n")
 			}
 		}
 
 		// 5) Separator between logical rows
-		if i < end-1 && i < len(logs)-1 {
+		if pos < end-1 {
 			logContent.WriteString("\n")
 		}
 	}
@@ -412,7 +492,7 @@ func (m *logModel) View() string {
 	// Stable border with fixed width calculation
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.DoubleBorder()).
-		BorderForeground(lipgloss.Color("8")).
+		BorderForeground(lipgloss.Color(m.config.Colors.BorderColor)).
 		Width(m.width - 4). // Fixed width: terminal width minus border chars
 		Padding(0, 1)
 
@@ -438,7 +518,7 @@ func (m *logModel) copyCurrentLine() tea.Cmd {
 	}
 	
 	// Get the current log line (use original unformatted message for copying)
-	logLine := logs[m.cursor].OriginalMessage
+	logLine := m.config.redact(logs[m.cursor].OriginalMessage)
 	
 	// Copy to clipboard using different methods based on OS
 	return func() tea.Msg {
@@ -464,6 +544,10 @@ func (m *logModel) applyHighlights() {
 	if len(m.matches) > 0 && m.currentMatch >= 0 && m.currentMatch < len(m.matches) {
 		currentMatchIdx = m.matches[m.currentMatch]
 	}
+
+	// Highlight rules are painted first so search matches stay on top
+	rules := m.ruleLayers()
+	rules = rules[:len(rules):len(rules)]
 	
 	for _, idx := range m.matches {
 		if idx < 0 || idx >= len(logs) {
@@ -474,18 +558,12 @@ func (m *logModel) applyHighlights() {
 		displayText := stripANSI(logs[idx].Raw)
 
 		// Apply highlights - use different style for current match
-		var highlighted string
+		style := m.config.HighlightStyle()
 		if idx == currentMatchIdx {
 			// Current match gets special highlighting
-			highlighted = m.searchRegex.ReplaceAllStringFunc(displayText, func(match string) string {
-				return m.config.CurrentMatchStyle().Render(match)
-			})
-		} else {
-			// Regular matches get normal highlighting
-			highlighted = m.searchRegex.ReplaceAllStringFunc(displayText, func(match string) string {
-				return m.config.HighlightStyle().Render(match)
-			})
+			style = m.config.CurrentMatchStyle()
 		}
+		highlighted, _ := paintMatches(displayText, append(rules, highlightLayer{re: m.searchRegex, style: style}))
 
 		m.highlighted[idx] = highlighted
 	}
@@ -508,31 +586,47 @@ func renderControlsBar(m *logModel) string {
 		followStatus = "ON"
 	}
 
+	keys := m.config.Keys
+	hint := func(action string) string { return keys.hint(keyContextViewer, action) }
+
 	logInfo := ""
 	logs := m.safeLogs()
 	if len(logs) > 0 {
-		logInfo = fmt.Sprintf(" | %d/%d logs", m.cursor+1, len(logs))
+		rows := m.visibleRows(logs)
+		logInfo = fmt.Sprintf(" | %d/%d logs", min(m.rowPosition(rows)+1, len(rows)), len(rows))
+		if hidden := len(logs) - len(rows); hidden > 0 {
+			logInfo += fmt.Sprintf(" (%d %s hidden)", hidden, m.hiddenReason())
+		}
+		if counts := m.store.Levels().String(); counts != "" {
+			logInfo += " | " + counts
+		}
 	}
 
 	// Build help text with priority order (most important commands first)
-	essentialControls := fmt.Sprintf("b back, q quit%s", logInfo)
-	
+	essentialControls := fmt.Sprintf("%s help, %s back, %s quit%s",
+		hint(actionHelp), hint(actionBack), hint(actionQuit), logInfo)
+	matchKeys := hint(actionNextMatch) + "/" + hint(actionPrevMatch)
+
 	// Try different levels of detail based on available width
 	fullControls := fmt.Sprintf(
-		"/ search, Esc clear, n/N next, c copy, J fmt (%s), F follow (%s), H hist, %s",
-		formatStatus, followStatus, essentialControls,
+		"%s search, %s clear, %s next, %s copy, %s fmt (%s), %s follow (%s), %s level (%s), %s theme, %s hist, %s",
+		hint(actionSearch), hint(actionClearSearch), matchKeys, hint(actionCopy),
+		hint(actionFormat), formatStatus, hint(actionFollow), followStatus,
+		hint(actionLevel), m.minLevel, hint(actionTheme), hint(actionHistory), essentialControls,
 	)
-	
+
 	mediumControls := fmt.Sprintf(
-		"/ search, n/N next, c copy, J fmt (%s), F follow (%s), %s",
-		formatStatus, followStatus, essentialControls,
+		"%s search, %s next, %s copy, %s fmt (%s), %s follow (%s), %s",
+		hint(actionSearch), matchKeys, hint(actionCopy),
+		hint(actionFormat), formatStatus, hint(actionFollow), followStatus, essentialControls,
 	)
-	
+
 	shortControls := fmt.Sprintf(
-		"/ search, c copy, J fmt (%s), F follow (%s), %s",
-		formatStatus, followStatus, essentialControls,
+		"%s search, %s copy, %s fmt (%s), %s follow (%s), %s",
+		hint(actionSearch), hint(actionCopy),
+		hint(actionFormat), formatStatus, hint(actionFollow), followStatus, essentialControls,
 	)
-	
+
 	// Choose the longest version that fits
 	var controlsText string
 	if len(fullControls) <= m.width {
@@ -549,3 +643,17 @@ func renderControlsBar(m *logModel) string {
 		MaxWidth(m.width).
 		Render(controlsText)
 }
+
+// renderHelp renders the help overlay from the active key map
+func (m *logModel) renderHelp() string {
+	keys := m.config.Keys
+	help := keys.helpText(keyContextViewer, keyContextPrompt, keyContextFilter, keyContextDetail)
+	footer := fmt.Sprintf("\nPress %s or %s to close",
+		keys.hint(keyContextViewer, actionHelp), keys.hint(keyContextViewer, actionClearSearch))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(m.config.Colors.BorderColor)).
+		Padding(0, 1).
+		Render(help + "\n" + m.config.MutedStyle().Render(footer))
+}
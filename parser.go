@@ -1,160 +1,40 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
-	"regexp"
 	"strings"
 	"time"
-
-	"github.com/charmbracelet/lipgloss"
-)
-
-// Pre-compiled regex patterns for better performance
-var (
-	// Common Log Format: IP - - [timestamp] "METHOD path protocol" status size "referer" "user-agent"
-	accessLogRegex = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "(\S+) ([^"]*) ([^"]*)" (\d+) (\S+) "([^"]*)" "([^"]*)"`)
-
-	// JSON object detection within log messages - simplified to avoid catastrophic backtracking
-	jsonRegex = regexp.MustCompile(`\{[^{}]{0,1000}\}`)
 )
 
-// AccessLogEntry represents a parsed access log entry
-type AccessLogEntry struct {
-	IP        string
-	Timestamp string
-	Method    string
-	Path      string
-	Protocol  string
-	Status    string
-	Size      string
-	Referer   string
-	UserAgent string
-}
-
 // logEntry represents a log entry with original and formatted versions
 type logEntry struct {
 	Timestamp       time.Time
 	OriginalMessage string // Store the original unformatted message
 	Message         string // Store the formatted message
 	Raw             string // Store the complete display line
-}
 
-// isJSON checks if a string is valid JSON with safety checks
-func isJSON(s string) bool {
-	// Quick checks to avoid expensive JSON parsing
-	if len(s) == 0 {
-		return false
-	}
-	
-	s = strings.TrimSpace(s)
-	if len(s) == 0 {
-		return false
-	}
-	
-	// Avoid parsing very large JSON to prevent performance issues
-	if len(s) > 5000 {
-		return false
-	}
-	
-	// Quick check for obvious JSON patterns
-	first := s[0]
-	if first == '{' || first == '[' || first == '"' || 
-	   s == "null" || s == "true" || s == "false" ||
-	   (first >= '0' && first <= '9') || first == '-' {
-		var js json.RawMessage
-		return json.Unmarshal([]byte(s), &js) == nil
-	}
-	
-	return false
-}
+	Format string                 // Name of the parser that understood the message, "" if none
+	Fields map[string]interface{} // Fields extracted by that parser (see parser_registry.go)
+	Level  logLevel               // Detected severity (see levels.go)
 
-// formatJSON pretty-prints JSON with syntax highlighting
-func formatJSON(jsonStr string, indent string) string {
-	var obj interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &obj); err != nil {
-		return jsonStr // Return original if not valid JSON
-	}
+	Stream        string    // Log stream the event came from
+	IngestionTime time.Time // When CloudWatch ingested the event; zero if unknown (see timestamps.go)
+	RequestID     string    // Lambda request ID of the invocation, if any (see lambda.go)
+	StackLines    int       // Lines of the message that look like stack frames (see stacktrace.go)
 
-	formatted, err := json.MarshalIndent(obj, "", indent)
-	if err != nil {
-		return jsonStr // Return original if formatting fails
-	}
-
-	return string(formatted)
+	plain     string   // Raw without colors, cached by restamp for search and filters
+	flowOrder []string // Field order from a VPC flow log header of the stream (see parser_vpcflow.go)
 }
 
-// parseAccessLog attempts to parse common access log formats (Apache/Nginx)
-func parseAccessLog(logLine string) *AccessLogEntry {
-	matches := accessLogRegex.FindStringSubmatch(logLine)
-
-	if len(matches) >= 10 {
-		return &AccessLogEntry{
-			IP:        matches[1],
-			Timestamp: matches[2],
-			Method:    matches[3],
-			Path:      matches[4],
-			Protocol:  matches[5],
-			Status:    matches[6],
-			Size:      matches[7],
-			Referer:   matches[8],
-			UserAgent: matches[9],
-		}
+// plainText is the display line without colors, as search and filters match it
+func (e *logEntry) plainText() string {
+	if e.plain == "" && e.Raw != "" {
+		return stripANSI(e.Raw) // Built without restamp
 	}
-
-	return nil
+	return e.plain
 }
 
-// formatAccessLog formats an access log entry with colors and structure
-func formatAccessLog(entry *AccessLogEntry, config *UIConfig) string {
-	if !config.ColorizeFields {
-		return fmt.Sprintf("%s %s %s %s %s",
-			entry.IP, entry.Method, entry.Path, entry.Status, entry.Size)
-	}
-
-	// Color coding based on HTTP status
-	var statusStyle lipgloss.Style
-	switch {
-	case strings.HasPrefix(entry.Status, "2"):
-		statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true) // Green for 2xx
-	case strings.HasPrefix(entry.Status, "3"):
-		statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true) // Yellow for 3xx
-	case strings.HasPrefix(entry.Status, "4"):
-		statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true) // Red for 4xx
-	case strings.HasPrefix(entry.Status, "5"):
-		statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true) // Dark red for 5xx
-	default:
-		statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("15")) // White for others
-	}
-
-	// Method colors with better distinction
-	var methodStyle lipgloss.Style
-	switch entry.Method {
-	case "GET":
-		methodStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true) // Blue
-	case "POST":
-		methodStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true) // Magenta
-	case "PUT":
-		methodStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Bold(true) // Cyan
-	case "DELETE":
-		methodStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true) // Red
-	default:
-		methodStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("15")) // White
-	}
-
-	// Better styling for different elements
-	ipStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))    // Cyan for IP
-	pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15")) // White for path
-	sizeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))  // Gray for size
-
-	// Simple, clean single-line format
-	return fmt.Sprintf("%s %s %s %s %s",
-		ipStyle.Render(entry.IP),
-		methodStyle.Render(entry.Method),
-		pathStyle.Render(entry.Path),
-		statusStyle.Render(entry.Status),
-		sizeStyle.Render(entry.Size))
-}
+// maxMessageLength is the longest message that is parsed and formatted
+const maxMessageLength = 10000
 
 // formatLogMessage formats a log message, applying appropriate formatting
 func formatLogMessage(message string, config *UIConfig) string {
@@ -162,60 +42,64 @@ func formatLogMessage(message string, config *UIConfig) string {
 	if !config.ParseAccessLogs && !config.PrettyPrintJSON {
 		return strings.TrimSpace(message)
 	}
+	return renderLogMessage(message, parseMessage(message, config), config)
+}
 
-	message = strings.TrimSpace(message)
+// parseMessage trims the message and runs it through the parser chain.
+// Very long messages only reach parsers built for them (see largeMessageParser).
+func parseMessage(message string, config *UIConfig) *parsedLog {
+	return parseLogMessage(strings.TrimSpace(message), config)
+}
 
-	// Skip formatting for very long messages to prevent performance issues
-	const maxMessageLength = 10000
-	if len(message) > maxMessageLength {
+// renderLogMessage renders a message with the parser that claimed it, if formatting allows
+func renderLogMessage(message string, parsed *parsedLog, config *UIConfig) string {
+	message = strings.TrimSpace(message)
+	if !config.ParseAccessLogs && !config.PrettyPrintJSON {
 		return message
 	}
-
-	// Try access log parsing first
-	if config.ParseAccessLogs {
-		if accessEntry := parseAccessLog(message); accessEntry != nil {
-			return formatAccessLog(accessEntry, config)
-		}
+	if parsed != nil && renderEnabled(parsed.Format, config) {
+		return parsed.parser.Render(parsed, config)
 	}
-
-	// Fall back to JSON formatting
-	if config.PrettyPrintJSON {
-		// Check if the entire message is JSON (but only for reasonable sizes)
-		if len(message) < 5000 && isJSON(message) {
-			return formatJSON(message, config.JSONIndent)
-		}
-
-		// Look for JSON objects within the message (with safety limits)
-		if len(message) < 5000 {
-			// Use a defer/recover to catch any potential panics from regex
-			func() {
-				defer func() {
-					if recover() != nil {
-						// If regex panics, just return the original message
-						return
-					}
-				}()
-				
-				message = jsonRegex.ReplaceAllStringFunc(message, func(match string) string {
-					if len(match) < 2000 && isJSON(match) {
-						return formatJSON(match, config.JSONIndent)
-					}
-					return match
-				})
-			}()
-		}
+	if len(message) > maxMessageLength {
+		return message
 	}
 
+	// Look for JSON objects within the message
+	if config.PrettyPrintJSON && parserEnabled(jsonParserName, config) {
+		return formatEmbeddedJSON(message, config)
+	}
 	return message
 }
 
 // makeLogEntry creates log entries consistently
 func makeLogEntry(ts time.Time, originalMsg string, cfg *UIConfig) logEntry {
-	formatted := formatLogMessage(originalMsg, cfg)
-	return logEntry{
+	message := cfg.redact(originalMsg) // Secrets never reach parsing, rendering or search
+	parsed := parseMessage(message, cfg)
+	formatted := renderLogMessage(message, parsed, cfg)
+	entry := logEntry{
 		Timestamp:       ts,
 		OriginalMessage: originalMsg,
 		Message:         formatted,
-		Raw:             fmt.Sprintf("[%s] %s", ts.Format("15:04:05"), formatted),
 	}
+	if parsed != nil {
+		entry.Format = parsed.Format
+		entry.Fields = parsed.Fields
+	}
+	entry.Level = detectLevel(originalMsg, entry.Fields)
+	entry.RequestID = lambdaRequestID(originalMsg, entry.Fields)
+	entry.StackLines = countStackLines(originalMsg)
+	entry.restamp(cfg, time.Now())
+	return entry
+}
+
+// reformatEntry rebuilds an entry with the current settings, keeping its event metadata
+func reformatEntry(old logEntry, cfg *UIConfig) logEntry {
+	entry := makeLogEntry(old.Timestamp, old.OriginalMessage, cfg)
+	entry.Stream = old.Stream
+	entry.IngestionTime = old.IngestionTime
+	if old.flowOrder != nil {
+		entry = withFlowLogOrder(entry, old.flowOrder, cfg)
+	}
+	entry.restamp(cfg, time.Now())
+	return entry
 }
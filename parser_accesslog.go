@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	// Combined Log Format: IP - - [timestamp] "METHOD path protocol" status size "referer" "user-agent"
+	accessLogRegex = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "(\S+) ([^"]*) ([^"]*)" (\d+) (\S+) "([^"]*)" "([^"]*)"`)
+
+	// Common Log Format: host ident user [timestamp] "METHOD path [protocol]" status size.
+	// The host may be IPv4, IPv6 (bare or bracketed) or a name.
+	clfRegex = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "([A-Z]+) (\S+)(?: ([^"]*))?" (\d{3}) (\d+|-)\s*$`)
+)
+
+const (
+	accessLogParserName = "accessLog"
+	clfParserName       = "clf"
+)
+
+// AccessLogEntry represents a parsed access log entry
+type AccessLogEntry struct {
+	IP        string
+	Timestamp string
+	Method    string
+	Path      string
+	Protocol  string
+	Status    string
+	Size      string
+	Referer   string
+	UserAgent string
+}
+
+// accessLogParser handles Apache/Nginx combined access logs
+type accessLogParser struct{}
+
+func (accessLogParser) Name() string { return accessLogParserName }
+
+// Detect looks for the quoted request line that follows the bracketed timestamp
+func (accessLogParser) Detect(message string) bool {
+	return strings.Contains(message, "] \"")
+}
+
+func (accessLogParser) Parse(message string) (*parsedLog, bool) {
+	entry := parseAccessLog(message)
+	if entry == nil {
+		return nil, false
+	}
+	return &parsedLog{Fields: accessLogFields(entry), Value: entry}, true
+}
+
+func (accessLogParser) Render(parsed *parsedLog, config *UIConfig) string {
+	return formatAccessLog(parsed.Value.(*AccessLogEntry), config)
+}
+
+// parseAccessLog attempts to parse common access log formats (Apache/Nginx)
+func parseAccessLog(logLine string) *AccessLogEntry {
+	matches := accessLogRegex.FindStringSubmatch(logLine)
+
+	if len(matches) >= 10 {
+		return &AccessLogEntry{
+			IP:        matches[1],
+			Timestamp: matches[2],
+			Method:    matches[3],
+			Path:      matches[4],
+			Protocol:  matches[5],
+			Status:    matches[6],
+			Size:      matches[7],
+			Referer:   matches[8],
+			UserAgent: matches[9],
+		}
+	}
+
+	return nil
+}
+
+// formatAccessLog formats an access log entry with colors and structure
+func formatAccessLog(entry *AccessLogEntry, config *UIConfig) string {
+	if !config.ColorizeFields {
+		return fmt.Sprintf("%s %s %s %s %s",
+			entry.IP, entry.Method, entry.Path, entry.Status, entry.Size)
+	}
+
+	colors := config.Colors
+
+	// Better styling for different elements
+	ipStyle := config.FieldStyle(colors.IPColor)      // Cyan for IP
+	pathStyle := config.FieldStyle(colors.FieldColor) // White for path
+	sizeStyle := config.FieldStyle(colors.SizeColor)  // Gray for size
+
+	// Simple, clean single-line format
+	return fmt.Sprintf("%s %s %s %s %s",
+		ipStyle.Render(entry.IP),
+		accessMethodStyle(entry.Method, config).Render(entry.Method),
+		pathStyle.Render(entry.Path),
+		accessStatusStyle(entry.Status, config).Render(entry.Status),
+		sizeStyle.Render(entry.Size))
+}
+
+// accessStatusStyle colors an HTTP status code by class
+func accessStatusStyle(status string, config *UIConfig) lipgloss.Style {
+	colors := config.Colors
+	switch {
+	case strings.HasPrefix(status, "2"):
+		return config.FieldStyle(colors.Status2xxColor).Bold(true) // Green for 2xx
+	case strings.HasPrefix(status, "3"):
+		return config.FieldStyle(colors.Status3xxColor).Bold(true) // Yellow for 3xx
+	case strings.HasPrefix(status, "4"):
+		return config.FieldStyle(colors.Status4xxColor).Bold(true) // Red for 4xx
+	case strings.HasPrefix(status, "5"):
+		return config.FieldStyle(colors.Status5xxColor).Bold(true) // Dark red for 5xx
+	}
+	return config.FieldStyle(colors.FieldColor) // White for others
+}
+
+// accessMethodStyle colors an HTTP method
+func accessMethodStyle(method string, config *UIConfig) lipgloss.Style {
+	colors := config.Colors
+	switch method {
+	case "GET":
+		return config.FieldStyle(colors.MethodGetColor).Bold(true) // Blue
+	case "POST":
+		return config.FieldStyle(colors.MethodPostColor).Bold(true) // Magenta
+	case "PUT":
+		return config.FieldStyle(colors.MethodPutColor).Bold(true) // Cyan
+	case "DELETE":
+		return config.FieldStyle(colors.MethodDeleteColor).Bold(true) // Red
+	}
+	return config.FieldStyle(colors.FieldColor) // White
+}
+
+// formatAccessLogDetails renders extra values after the formatAccessLog columns,
+// skipping empty and "-" values
+func formatAccessLogDetails(line string, config *UIConfig, details ...string) string {
+	for _, detail := range details {
+		if detail == "" || detail == "-" {
+			continue
+		}
+		if config.ColorizeFields {
+			detail = config.FieldStyle(config.Colors.SizeColor).Render(detail)
+		}
+		line += " " + detail
+	}
+	return line
+}
+
+// accessLogFields exposes the common access log columns as fields
+func accessLogFields(entry *AccessLogEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"ip":         entry.IP,
+		"timestamp":  entry.Timestamp,
+		"method":     entry.Method,
+		"path":       entry.Path,
+		"protocol":   entry.Protocol,
+		"status":     entry.Status,
+		"size":       entry.Size,
+		"referer":    entry.Referer,
+		"user_agent": entry.UserAgent,
+	}
+}
+
+// clfParser handles plain Common Log Format lines, which lack the referer and
+// user agent of the combined format
+type clfParser struct{}
+
+func (clfParser) Name() string { return clfParserName }
+
+func (clfParser) Detect(message string) bool {
+	return strings.Contains(message, "] \"")
+}
+
+func (clfParser) Parse(message string) (*parsedLog, bool) {
+	m := clfRegex.FindStringSubmatch(message)
+	if m == nil {
+		return nil, false
+	}
+	entry := &AccessLogEntry{
+		IP:        m[1],
+		Timestamp: m[4],
+		Method:    m[5],
+		Path:      m[6],
+		Protocol:  m[7],
+		Status:    m[8],
+		Size:      m[9],
+	}
+	fields := accessLogFields(entry)
+	fields["user"] = m[3]
+	return &parsedLog{Fields: fields, Value: entry}, true
+}
+
+func (clfParser) Render(parsed *parsedLog, config *UIConfig) string {
+	return formatAccessLog(parsed.Value.(*AccessLogEntry), config)
+}
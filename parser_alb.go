@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+/*
+Application Load Balancer Access Logs
+
+Space-separated, with quoted request, user agent and trace fields:
+
+	type time elb client:port target:port request_processing_time target_processing_time
+	response_processing_time elb_status_code target_status_code received_bytes sent_bytes
+	"request" "user_agent" ssl_cipher ssl_protocol target_group_arn "trace_id" "domain_name" ...
+
+Rendered like formatAccessLog, followed by the target status (when it differs),
+the total processing time and the X-Ray trace ID.
+*/
+
+const albParserName = "alb"
+
+// albRequestTypes are the values of the first ALB log field
+var albRequestTypes = map[string]bool{"http": true, "https": true, "h2": true, "grpcs": true, "ws": true, "wss": true}
+
+// albFieldNames names the positional ALB fields after the client and target addresses
+var albFieldNames = []string{
+	"ssl_cipher", "ssl_protocol", "target_group_arn", "trace_id", "domain_name",
+	"chosen_cert_arn", "matched_rule_priority", "request_creation_time", "actions_executed",
+	"redirect_url", "error_reason", "target_port_list", "target_status_code_list",
+	"classification", "classification_reason",
+}
+
+// albParser handles Application Load Balancer access logs
+type albParser struct{}
+
+func (albParser) Name() string { return albParserName }
+
+func (albParser) Detect(message string) bool {
+	kind, rest, ok := strings.Cut(message, " ")
+	return ok && albRequestTypes[kind] && len(rest) > 20 && rest[4] == '-' && rest[10] == 'T'
+}
+
+func (albParser) Parse(message string) (*parsedLog, bool) {
+	parts := splitQuotedFields(message)
+	if len(parts) < 14 {
+		return nil, false
+	}
+
+	method, target, protocol := splitRequestLine(parts[12])
+	if method == "" {
+		return nil, false
+	}
+	clientIP, clientPort := splitHostPort(parts[3])
+
+	fields := map[string]interface{}{
+		"type":           parts[0],
+		"time":           parts[1],
+		"elb":            parts[2],
+		"ip":             clientIP,
+		"client_port":    clientPort,
+		"target":         parts[4],
+		"status":         parts[8],
+		"target_status":  parts[9],
+		"received_bytes": parts[10],
+		"size":           parts[11],
+		"method":         method,
+		"url":            target,
+		"path":           requestPath(target),
+		"protocol":       protocol,
+		"user_agent":     parts[13],
+		"request_time":   parseSeconds(parts[5]),
+		"target_time":    parseSeconds(parts[6]),
+		"response_time":  parseSeconds(parts[7]),
+	}
+	for i, name := range albFieldNames {
+		if 14+i < len(parts) {
+			fields[name] = parts[14+i]
+		}
+	}
+
+	entry := &AccessLogEntry{
+		IP:        clientIP,
+		Timestamp: parts[1],
+		Method:    method,
+		Path:      requestPath(target),
+		Protocol:  protocol,
+		Status:    parts[8],
+		Size:      parts[11],
+		UserAgent: parts[13],
+	}
+	return &parsedLog{Fields: fields, Value: entry}, true
+}
+
+func (albParser) Render(parsed *parsedLog, config *UIConfig) string {
+	fields := parsed.Fields
+	entry := parsed.Value.(*AccessLogEntry)
+	line := formatAccessLog(entry, config)
+
+	// The target status matters when the load balancer answered differently
+	if target, _ := fields["target_status"].(string); target != entry.Status {
+		label := "target " + target
+		if config.ColorizeFields && target != "-" {
+			label = "target " + accessStatusStyle(target, config).Render(target)
+		}
+		line += " " + label
+	}
+
+	// -1 marks a time that could not be measured (e.g. no target reached)
+	var total float64
+	for _, name := range []string{"request_time", "target_time", "response_time"} {
+		if seconds, ok := fields[name].(float64); ok && seconds > 0 {
+			total += seconds
+		}
+	}
+	trace, _ := fields["trace_id"].(string)
+	errorReason, _ := fields["error_reason"].(string)
+	return formatAccessLogDetails(line, config, fmt.Sprintf("%.3fs", total), errorReason, trace)
+}
+
+// splitQuotedFields splits a line on spaces, keeping "quoted values" whole (without quotes)
+func splitQuotedFields(line string) []string {
+	var fields []string
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ':
+			i++
+		case line[i] == '"':
+			end := strings.IndexByte(line[i+1:], '"')
+			if end < 0 {
+				fields = append(fields, line[i+1:])
+				return fields
+			}
+			fields = append(fields, line[i+1:i+1+end])
+			i += end + 2
+		default:
+			end := strings.IndexByte(line[i:], ' ')
+			if end < 0 {
+				fields = append(fields, line[i:])
+				return fields
+			}
+			fields = append(fields, line[i:i+end])
+			i += end
+		}
+	}
+	return fields
+}
+
+// splitRequestLine splits "GET http://host/path HTTP/1.1"
+func splitRequestLine(request string) (method, target, protocol string) {
+	parts := strings.Fields(request)
+	if len(parts) < 2 || parts[0] == "-" {
+		return "", "", ""
+	}
+	if len(parts) > 2 {
+		protocol = parts[2]
+	}
+	return parts[0], parts[1], protocol
+}
+
+// requestPath strips scheme, host and port from an absolute request URL
+func requestPath(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return target
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
+
+// splitHostPort splits "ip:port" where ip may be a bare or bracketed IPv6 address
+func splitHostPort(address string) (host, port string) {
+	i := strings.LastIndexByte(address, ':')
+	if i < 0 || strings.HasSuffix(address, "]") {
+		return strings.Trim(address, "[]"), ""
+	}
+	host, port = address[:i], address[i+1:]
+	if _, err := strconv.Atoi(port); err != nil {
+		return strings.Trim(address, "[]"), ""
+	}
+	return strings.Trim(host, "[]"), port
+}
+
+// parseSeconds converts an ALB/CloudFront time field; "-" and garbage become -1
+func parseSeconds(value string) float64 {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return -1
+	}
+	return seconds
+}
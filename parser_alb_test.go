@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testALBLine = `https 2024-10-16T12:00:00.123456Z app/my-lb/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80 ` +
+		`0.000 0.001 0.000 200 200 34 366 "GET https://www.example.com:443/api/users?id=1 HTTP/1.1" "curl/8.0" ` +
+		`ECDHE-RSA-AES128-GCM-SHA256 TLSv1.2 arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/tg/73e2d6bc24d8a067 ` +
+		`"Root=1-58337262-36d228ad5d99923122bbe354" "www.example.com" "-" 0 2024-10-16T12:00:00.120000Z "forward" "-" "-" "10.0.0.1:80" "200" "-" "-"`
+	testCloudFrontLine = "2024-10-16\t12:00:00\tSEA19-C1\t2390282\t2001:db8::1\tGET\td111111abcdef8.cloudfront.net\t/index.html\t200\t-\t" +
+		"Mozilla/5.0%20(Macintosh)\tlang=en\t-\tHit\tSOX4xwn4XV6Q4rgb7XiVGOHms_BGlTAC4KyHmureZmBNrjGdRLiNIQ==\t" +
+		"example.com\thttps\t197\t0.002\t-\tTLSv1.3\tTLS_AES_128_GCM_SHA256\tHit\tHTTP/2.0"
+)
+
+func TestAccessLogVariants(t *testing.T) {
+	t.Run("CommonLogFormat", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			line     string
+			ip       string
+			protocol string
+		}{
+			{"ipv4", `10.0.0.1 - frank [20/Oct/2025:14:30:00 +0000] "GET /index.html HTTP/1.0" 200 2326`, "10.0.0.1", "HTTP/1.0"},
+			{"ipv6", `2001:db8::1 - - [20/Oct/2025:14:30:00 +0000] "POST /login HTTP/2.0" 401 -`, "2001:db8::1", "HTTP/2.0"},
+			{"no protocol", `10.0.0.1 - - [20/Oct/2025:14:30:00 +0000] "GET /" 200 12`, "10.0.0.1", ""},
+		}
+
+		config := createTestConfig()
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				entry := makeLogEntry(testTimestamp, tt.line, config)
+				assertStringEqual(t, entry.Format, clfParserName)
+				assertStringEqual(t, entry.Fields["ip"].(string), tt.ip)
+				assertStringEqual(t, entry.Fields["protocol"].(string), tt.protocol)
+				assertStringContains(t, stripANSI(entry.Message), tt.ip)
+			})
+		}
+
+		// Combined lines still belong to the accessLog parser
+		assertStringEqual(t, makeLogEntry(testTimestamp, testAccessLogLine, config).Format, accessLogParserName)
+	})
+
+	t.Run("ALB", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+
+		// Act
+		entry := makeLogEntry(testTimestamp, testALBLine, config)
+
+		// Assert
+		assertStringEqual(t, entry.Format, albParserName)
+		assertStringEqual(t, entry.Fields["ip"].(string), "192.168.131.39")
+		assertStringEqual(t, entry.Fields["client_port"].(string), "2817")
+		assertStringEqual(t, entry.Fields["path"].(string), "/api/users?id=1")
+		assertStringEqual(t, entry.Fields["trace_id"].(string), "Root=1-58337262-36d228ad5d99923122bbe354")
+		rendered := stripANSI(entry.Message)
+		assertStringContains(t, rendered, "GET /api/users?id=1")
+		assertStringContains(t, rendered, "0.001s")
+		if strings.Contains(rendered, "target 200") {
+			t.Errorf("matching target status should not be shown: %q", rendered)
+		}
+	})
+
+	t.Run("ALBTargetFailure", func(t *testing.T) {
+		line := strings.Replace(testALBLine, "192.168.131.39:2817", "[2001:db8::7]:443", 1)
+		line = strings.Replace(line, " 200 200 ", " 502 - ", 1)
+
+		entry := makeLogEntry(testTimestamp, line, createTestConfig())
+
+		assertStringEqual(t, entry.Fields["ip"].(string), "2001:db8::7")
+		assertStringEqual(t, entry.Fields["status"].(string), "502")
+		assertStringContains(t, stripANSI(entry.Message), "502")
+		assertStringContains(t, stripANSI(entry.Message), "target -")
+	})
+
+	t.Run("CloudFront", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+
+		// Act
+		entry := makeLogEntry(testTimestamp, testCloudFrontLine, config)
+
+		// Assert
+		assertStringEqual(t, entry.Format, cloudFrontParserName)
+		assertStringEqual(t, entry.Fields["ip"].(string), "2001:db8::1")
+		assertStringEqual(t, entry.Fields["user_agent"].(string), "Mozilla/5.0 (Macintosh)")
+		assertStringEqual(t, entry.Fields["edge_result_type"].(string), "Hit")
+		rendered := stripANSI(entry.Message)
+		for _, want := range []string{"GET /index.html?lang=en", "200", "Hit", "0.002s", "SEA19-C1"} {
+			assertStringContains(t, rendered, want)
+		}
+
+		// Header lines are not records
+		assertStringEqual(t, makeLogEntry(testTimestamp, "#Version: 1.0", config).Format, "")
+	})
+
+	t.Run("SplitHelpers", func(t *testing.T) {
+		hosts := map[string][2]string{
+			"10.0.0.1:80":       {"10.0.0.1", "80"},
+			"[2001:db8::1]:443": {"2001:db8::1", "443"},
+			"2001:db8::1:2817":  {"2001:db8::1", "2817"},
+			"[2001:db8::1]":     {"2001:db8::1", ""},
+			"-":                 {"-", ""},
+		}
+		for address, want := range hosts {
+			host, port := splitHostPort(address)
+			if host != want[0] || port != want[1] {
+				t.Errorf("splitHostPort(%q) = %q, %q; want %q, %q", address, host, port, want[0], want[1])
+			}
+		}
+
+		got := splitQuotedFields(`a "b c" d "" "unterminated`)
+		assertStringEqual(t, strings.Join(got, "|"), "a|b c|d||unterminated")
+	})
+}
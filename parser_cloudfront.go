@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/*
+CloudFront Standard Logs
+
+Tab-separated W3C records in the fixed standard log field order:
+
+	date time x-edge-location sc-bytes c-ip cs-method cs(Host) cs-uri-stem sc-status
+	cs(Referer) cs(User-Agent) cs-uri-query cs(Cookie) x-edge-result-type x-edge-request-id
+	x-host-header cs-protocol cs-bytes time-taken x-forwarded-for ssl-protocol ssl-cipher
+	x-edge-response-result-type cs-protocol-version ...
+
+"#Version" and "#Fields" header lines are left as they are. Rendered like
+formatAccessLog, followed by the edge result (Hit, Miss, Error...), the time
+taken and the edge location.
+*/
+
+const cloudFrontParserName = "cloudfront"
+
+// cloudFrontFieldNames are the standard log fields in order, as field names
+var cloudFrontFieldNames = []string{
+	"date", "time", "edge_location", "size", "ip", "method", "host", "path", "status",
+	"referer", "user_agent", "query", "cookie", "edge_result_type", "request_id",
+	"host_header", "scheme", "received_bytes", "time_taken", "forwarded_for",
+	"ssl_protocol", "ssl_cipher", "edge_response_result_type", "protocol",
+	"fle_status", "fle_encrypted_fields", "client_port", "time_to_first_byte",
+	"edge_detailed_result_type", "content_type", "content_length", "range_start", "range_end",
+}
+
+// cloudFrontMinFields is the number of fields up to and including time-taken
+const cloudFrontMinFields = 19
+
+// cloudFrontParser handles CloudFront standard (access) logs
+type cloudFrontParser struct{}
+
+func (cloudFrontParser) Name() string { return cloudFrontParserName }
+
+// Detect looks for the leading "YYYY-MM-DD\tHH:MM:SS\t" of a record
+func (cloudFrontParser) Detect(message string) bool {
+	return len(message) > 20 && message[4] == '-' && message[10] == '\t' && message[13] == ':' && message[19] == '\t'
+}
+
+func (cloudFrontParser) Parse(message string) (*parsedLog, bool) {
+	parts := strings.Split(message, "\t")
+	if len(parts) < cloudFrontMinFields {
+		return nil, false
+	}
+
+	fields := make(map[string]interface{}, len(parts))
+	for i, value := range parts {
+		if i >= len(cloudFrontFieldNames) {
+			break
+		}
+		fields[cloudFrontFieldNames[i]] = value
+	}
+
+	// User agents and referers are URL-encoded ("Mozilla/5.0%20(Macintosh...")
+	userAgent := cloudFrontUnescape(parts[10])
+	fields["user_agent"] = userAgent
+	fields["referer"] = cloudFrontUnescape(parts[9])
+	fields["time_taken"] = parseSeconds(parts[18])
+
+	path := parts[7]
+	if query := parts[11]; query != "-" && query != "" {
+		path += "?" + query
+	}
+
+	entry := &AccessLogEntry{
+		IP:        parts[4],
+		Timestamp: parts[0] + " " + parts[1],
+		Method:    parts[5],
+		Path:      path,
+		Status:    parts[8],
+		Size:      parts[3],
+		Referer:   cloudFrontUnescape(parts[9]),
+		UserAgent: userAgent,
+	}
+	if len(parts) > 23 {
+		entry.Protocol = parts[23]
+	}
+	return &parsedLog{Fields: fields, Value: entry}, true
+}
+
+func (cloudFrontParser) Render(parsed *parsedLog, config *UIConfig) string {
+	fields := parsed.Fields
+	line := formatAccessLog(parsed.Value.(*AccessLogEntry), config)
+
+	result, _ := fields["edge_result_type"].(string)
+	if result != "" && result != "-" {
+		if config.ColorizeFields {
+			result = cloudFrontResultStyle(result, config)
+		}
+		line += " " + result
+	}
+
+	timeTaken := ""
+	if seconds, _ := fields["time_taken"].(float64); seconds >= 0 {
+		timeTaken = fmt.Sprintf("%.3fs", seconds)
+	}
+	location, _ := fields["edge_location"].(string)
+	return formatAccessLogDetails(line, config, timeTaken, location)
+}
+
+// cloudFrontResultStyle colors the edge result type: hits green, misses yellow, errors red
+func cloudFrontResultStyle(result string, config *UIConfig) string {
+	colors := config.Colors
+	color := colors.FieldColor
+	switch {
+	case strings.Contains(result, "Hit"):
+		color = colors.Status2xxColor
+	case result == "Miss" || result == "Redirect":
+		color = colors.Status3xxColor
+	case strings.Contains(result, "Error") || result == "LimitExceeded" || result == "CapacityExceeded":
+		color = colors.Status5xxColor
+	}
+	return config.FieldStyle(color).Render(result)
+}
+
+// cloudFrontUnescape decodes the URL-encoded user agent and referer fields
+func cloudFrontUnescape(value string) string {
+	if decoded, err := url.PathUnescape(value); err == nil {
+		return decoded
+	}
+	return value
+}
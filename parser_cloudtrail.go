@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+/*
+CloudTrail Events
+
+CloudTrail trails delivered to CloudWatch Logs write one JSON event per log
+event, often far larger than the JSON parser accepts. Events are summarized on
+one line:
+
+	2024-10-16T12:00:00Z alice s3:GetObject 203.0.113.7 AccessDenied arn:aws:s3:::bucket/key
+
+that is event time, principal, eventSource:eventName (without ".amazonaws.com"),
+source IP, error code and resource ARNs. Events with an errorCode are logged at
+ERROR level so they stand out. The full event is shown in the detail view.
+*/
+
+const cloudTrailParserName = "cloudTrail"
+
+// cloudTrailMaxResources is the number of resource ARNs listed in the summary
+const cloudTrailMaxResources = 2
+
+// cloudTrailParser handles CloudTrail events of any size
+type cloudTrailParser struct{}
+
+func (cloudTrailParser) Name() string { return cloudTrailParserName }
+
+// acceptsLargeMessages lets whole events through the message length guard
+func (cloudTrailParser) acceptsLargeMessages() bool { return true }
+
+func (cloudTrailParser) Detect(message string) bool {
+	return strings.HasPrefix(message, "{") &&
+		strings.Contains(message, `"eventVersion"`) && strings.Contains(message, `"eventSource"`)
+}
+
+func (cloudTrailParser) Parse(message string) (*parsedLog, bool) {
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &event); err != nil {
+		return nil, false
+	}
+	if _, ok := event["eventName"].(string); !ok {
+		return nil, false
+	}
+	if code, _ := event["errorCode"].(string); code != "" {
+		event["level"] = "ERROR"
+	}
+	return &parsedLog{Fields: event, Value: event}, true
+}
+
+func (cloudTrailParser) Render(parsed *parsedLog, config *UIConfig) string {
+	event := parsed.Value.(map[string]interface{})
+	str := func(key string) string {
+		value, _ := event[key].(string)
+		return value
+	}
+
+	source := strings.TrimSuffix(str("eventSource"), ".amazonaws.com")
+	action := source + ":" + str("eventName")
+	principal := cloudTrailPrincipal(event)
+	sourceIP := str("sourceIPAddress")
+	errorCode := str("errorCode")
+	resources := cloudTrailResources(event)
+
+	if !config.ColorizeFields {
+		return formatAccessLogDetails(str("eventTime"), config, principal, action, sourceIP, errorCode, resources)
+	}
+
+	colors := config.Colors
+	line := config.FieldStyle(colors.SizeColor).Render(str("eventTime")) + " " +
+		config.FieldStyle(colors.IPColor).Render(principal) + " " +
+		config.FieldStyle(colors.MethodGetColor).Bold(true).Render(action)
+	if sourceIP != "" {
+		line += " " + config.FieldStyle(colors.FieldColor).Render(sourceIP)
+	}
+	if errorCode != "" {
+		line += " " + config.FieldStyle(colors.Status5xxColor).Bold(true).Render(errorCode)
+	}
+	return formatAccessLogDetails(line, config, resources)
+}
+
+// cloudTrailPrincipal names who made the call: a user, role session, root or service
+func cloudTrailPrincipal(event map[string]interface{}) string {
+	identity, _ := event["userIdentity"].(map[string]interface{})
+	str := func(key string) string {
+		value, _ := identity[key].(string)
+		return value
+	}
+
+	arn := str("arn")
+	switch str("type") {
+	case "Root":
+		return "root"
+	case "AssumedRole":
+		// arn:aws:sts::123456789012:assumed-role/Role/session
+		if _, role, ok := strings.Cut(arn, ":assumed-role/"); ok {
+			return role
+		}
+	case "AWSService":
+		if service := str("invokedBy"); service != "" {
+			return service
+		}
+	}
+	for _, value := range []string{str("userName"), arn, str("principalId"), str("type")} {
+		if value != "" {
+			return value
+		}
+	}
+	return "-"
+}
+
+// cloudTrailResources lists the ARNs of the resources an event touched
+func cloudTrailResources(event map[string]interface{}) string {
+	resources, _ := event["resources"].([]interface{})
+	var arns []string
+	for _, resource := range resources {
+		fields, _ := resource.(map[string]interface{})
+		if arn, _ := fields["ARN"].(string); arn != "" {
+			arns = append(arns, arn)
+		}
+	}
+	if len(arns) > cloudTrailMaxResources {
+		return fmt.Sprintf("%s +%d", strings.Join(arns[:cloudTrailMaxResources], ","), len(arns)-cloudTrailMaxResources)
+	}
+	return strings.Join(arns, ",")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// cloudTrailEvent builds a CloudTrail event; extra is spliced into the top-level object
+func cloudTrailEvent(extra string) string {
+	return `{"eventVersion":"1.08","userIdentity":{"type":"AssumedRole","principalId":"AROA:alice",` +
+		`"arn":"arn:aws:sts::123456789012:assumed-role/Admin/alice"},"eventTime":"2024-10-16T12:00:00Z",` +
+		`"eventSource":"s3.amazonaws.com","eventName":"GetObject","awsRegion":"us-east-1",` +
+		`"sourceIPAddress":"203.0.113.7","resources":[{"ARN":"arn:aws:s3:::bucket/key","type":"AWS::S3::Object"}]` +
+		extra + `}`
+}
+
+func TestCloudTrailParser(t *testing.T) {
+	t.Run("Summary", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+
+		// Act
+		entry := makeLogEntry(testTimestamp, cloudTrailEvent(""), config)
+
+		// Assert
+		assertStringEqual(t, entry.Format, cloudTrailParserName)
+		assertStringEqual(t, stripANSI(entry.Message),
+			"2024-10-16T12:00:00Z Admin/alice s3:GetObject 203.0.113.7 arn:aws:s3:::bucket/key")
+		assertIntEqual(t, int(entry.Level), int(levelNone), "successful call level")
+	})
+
+	t.Run("ErrorsAreHighlighted", func(t *testing.T) {
+		entry := makeLogEntry(testTimestamp, cloudTrailEvent(`,"errorCode":"AccessDenied","errorMessage":"no"`), createTestConfig())
+		assertStringContains(t, stripANSI(entry.Message), "203.0.113.7 AccessDenied")
+		assertIntEqual(t, int(entry.Level), int(levelError), "error level")
+	})
+
+	t.Run("LargeEvents", func(t *testing.T) {
+		// Arrange - well over the JSON parser and message length limits
+		message := cloudTrailEvent(`,"requestParameters":{"policy":"` + strings.Repeat("x", 3*maxMessageLength) + `"}`)
+
+		// Act
+		entry := makeLogEntry(testTimestamp, message, createTestConfig())
+
+		// Assert
+		assertStringEqual(t, entry.Format, cloudTrailParserName)
+		assertStringContains(t, stripANSI(entry.Message), "s3:GetObject")
+
+		// Other large messages are still left alone
+		large := makeLogEntry(testTimestamp, `{"msg":"`+strings.Repeat("x", 2*maxMessageLength)+`"}`, createTestConfig())
+		assertStringEqual(t, large.Format, "")
+	})
+
+	t.Run("Principals", func(t *testing.T) {
+		tests := map[string]string{
+			`{"type":"Root","arn":"arn:aws:iam::123456789012:root"}`:         "root",
+			`{"type":"IAMUser","userName":"bob"}`:                            "bob",
+			`{"type":"AWSService","invokedBy":"lambda.amazonaws.com"}`:       "lambda.amazonaws.com",
+			`{"type":"AssumedRole","arn":"arn:aws:sts::1:assumed-role/R/s"}`: "R/s",
+		}
+		for identity, want := range tests {
+			parsed, ok := cloudTrailParser{}.Parse(`{"eventName":"X","userIdentity":` + identity + `}`)
+			assertBoolEqual(t, ok, true, "parse")
+			assertStringEqual(t, cloudTrailPrincipal(parsed.Fields), want)
+		}
+	})
+}
+
+func TestDetailView(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("cloudtrail")
+	model.store.Append(makeLogEntry(testTimestamp, cloudTrailEvent(`,"errorCode":"AccessDenied"`), model.config))
+	model.followMode = false
+	model.cursor = 0
+	simulateWindowResize(model, 160, 16) // Shorter than the event
+
+	// Act
+	simulateKeyPress(model, "enter")
+
+	// Assert - key order is kept
+	if model.detail == nil {
+		t.Fatal("detail view should be open")
+	}
+	view := model.View()
+	assertStringContains(t, view, `eventVersion: "1.08"`)
+	if strings.Index(view, "eventVersion") > strings.Index(view, "userIdentity") {
+		t.Error("detail view should keep the event's key order")
+	}
+
+	for range model.detailHeight() {
+		simulateKeyPress(model, "j")
+	}
+	assertIntEqual(t, model.detail.offset, 1, "scrolled with the tree cursor")
+	assertIntEqual(t, model.cursor, 0, "cursor unchanged")
+
+	simulateKeyPress(model, "esc")
+	if model.detail != nil {
+		t.Error("esc should close the detail view")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+/*
+Container Log Envelopes
+
+Fluent Bit on EKS and FireLens on ECS wrap every application line in a JSON
+envelope:
+
+	{"log":"level=info msg=\"served\"\n","stream":"stderr","kubernetes":{"namespace_name":"shop","pod_name":"cart-7d9f","container_name":"app"}}
+	{"log":"GET /health 200","container_name":"app","ecs_task_arn":"arn:aws:ecs:eu-west-1:123456789012:task/prod/0f3e...","source":"stdout"}
+
+The container parser unwraps the "log" line, runs it through the parser chain
+again and renders the result after a compact prefix of the metadata:
+
+	[shop/cart-7d9f/app] INFO served
+	[0f3e.../app] GET /health 200
+
+stderr lines are marked in the prefix. The fields are the envelope's keys with
+the inner line's fields on top, so levels, request IDs and columns come from
+the application line.
+*/
+
+const containerParserName = "container"
+
+// containerLog is an unwrapped envelope
+type containerLog struct {
+	prefix string     // Metadata shown before the line, without brackets
+	line   string     // The inner log line
+	inner  *parsedLog // The inner line parsed by the chain, nil when nothing claimed it
+}
+
+// containerParser handles Fluent Bit, FireLens and Docker JSON envelopes
+type containerParser struct{}
+
+func (containerParser) Name() string { return containerParserName }
+
+func (containerParser) Detect(message string) bool {
+	return strings.HasPrefix(message, "{") && strings.Contains(message, `"log"`)
+}
+
+// Parse unwraps the envelope; parseNested parses the inner line
+func (containerParser) Parse(message string) (*parsedLog, bool) {
+	var envelope map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &envelope); err != nil {
+		return nil, false
+	}
+	line, ok := envelope["log"].(string)
+	if !ok || !isContainerEnvelope(envelope) {
+		return nil, false
+	}
+
+	fields := make(map[string]interface{}, len(envelope))
+	for key, value := range envelope {
+		fields[key] = value
+	}
+	return &parsedLog{
+		Fields: fields,
+		Value:  &containerLog{prefix: containerPrefix(envelope), line: strings.TrimSpace(line)},
+	}, true
+}
+
+func (containerParser) Render(parsed *parsedLog, config *UIConfig) string {
+	c := parsed.Value.(*containerLog)
+	prefix := "[" + c.prefix + "]"
+	if config.ColorizeFields {
+		prefix = config.MutedStyle().Render(prefix)
+	}
+	return prefix + " " + renderLogMessage(c.line, c.inner, config)
+}
+
+// parseNested runs the inner line through the chain and lays its fields over the envelope's
+func (containerParser) parseNested(parsed *parsedLog, config *UIConfig) {
+	c := parsed.Value.(*containerLog)
+	c.inner = parseLogMessage(c.line, config)
+	if c.inner != nil {
+		for key, value := range c.inner.Fields {
+			parsed.Fields[key] = value
+		}
+	}
+	if levelFromFields(parsed.Fields) == levelNone {
+		if level := levelFromText(c.line); level != levelNone {
+			parsed.Fields["level"] = level.String()
+		}
+	}
+}
+
+// isContainerEnvelope reports whether the keys next to "log" identify a log shipper envelope
+func isContainerEnvelope(envelope map[string]interface{}) bool {
+	if _, ok := envelope["kubernetes"].(map[string]interface{}); ok {
+		return true
+	}
+	for _, key := range []string{"ecs_task_arn", "ecs_cluster", "container_name", "container_id", "stream"} {
+		if _, ok := envelope[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containerPrefix summarizes where a line came from: namespace/pod/container,
+// task/container or just the container, followed by "stderr" for error output
+func containerPrefix(envelope map[string]interface{}) string {
+	str := func(fields map[string]interface{}, key string) string {
+		value, _ := fields[key].(string)
+		return value
+	}
+
+	var parts []string
+	if k8s, ok := envelope["kubernetes"].(map[string]interface{}); ok {
+		parts = append(parts, str(k8s, "namespace_name"), str(k8s, "pod_name"), str(k8s, "container_name"))
+	} else {
+		task := str(envelope, "ecs_task_arn")
+		if i := strings.LastIndex(task, "/"); i >= 0 {
+			task = task[i+1:]
+		}
+		parts = append(parts, task, str(envelope, "container_name"))
+	}
+
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	prefix := strings.Join(nonEmpty, "/")
+
+	stream := str(envelope, "stream")
+	if stream == "" {
+		stream = str(envelope, "source") // FireLens
+	}
+	if stream == "stderr" {
+		prefix = strings.TrimSpace(prefix + " stderr")
+	}
+	if prefix == "" {
+		prefix = stream
+	}
+	return prefix
+}
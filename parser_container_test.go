@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestContainerParser(t *testing.T) {
+	t.Run("Kubernetes", func(t *testing.T) {
+		// Arrange
+		message := `{"log":"level=error msg=\"payment failed\" order=42\n","stream":"stderr","time":"2026-01-02T03:04:05Z",` +
+			`"kubernetes":{"namespace_name":"shop","pod_name":"cart-7d9f","container_name":"app","labels":{"team":"a"}}}`
+
+		// Act
+		entry := makeLogEntry(testTimestamp, message, createTestConfig())
+
+		// Assert - the inner logfmt line is parsed and its level used
+		assertStringEqual(t, entry.Format, containerParserName)
+		assertStringEqual(t, stripANSI(entry.Message), "[shop/cart-7d9f/app stderr] ERROR payment failed order=42")
+		assertIntEqual(t, int(entry.Level), int(levelError), "level from the inner line")
+		assertStringEqual(t, entry.Fields["order"].(string), "42")
+		value, ok := fieldPath(entry.Fields, "kubernetes.pod_name")
+		assertBoolEqual(t, ok, true, "envelope metadata kept")
+		assertStringEqual(t, formatFieldValue(value), "cart-7d9f")
+	})
+
+	t.Run("FireLens", func(t *testing.T) {
+		message := `{"log":"WARN disk almost full","container_name":"worker","source":"stdout",` +
+			`"ecs_cluster":"prod","ecs_task_arn":"arn:aws:ecs:eu-west-1:123456789012:task/prod/0f3e9a"}`
+		entry := makeLogEntry(testTimestamp, message, createTestConfig())
+		assertStringEqual(t, stripANSI(entry.Message), "[0f3e9a/worker] WARN disk almost full")
+		assertIntEqual(t, int(entry.Level), int(levelWarn), "level from plain text")
+	})
+
+	t.Run("InnerJSON", func(t *testing.T) {
+		message := `{"log":"{\"level\":\"info\",\"msg\":\"ok\"}","stream":"stdout"}`
+		entry := makeLogEntry(testTimestamp, message, createTestConfig())
+		assertStringContains(t, stripANSI(entry.Message), "[stdout] {\n  \"level\": \"info\"")
+		assertStringEqual(t, entry.Fields["msg"].(string), "ok")
+	})
+
+	t.Run("NotAnEnvelope", func(t *testing.T) {
+		entry := makeLogEntry(testTimestamp, `{"log":"just a field","user":"bob"}`, createTestConfig())
+		assertStringEqual(t, entry.Format, jsonParserName)
+	})
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+/*
+Custom Parsers
+
+Formats no built-in parser covers can be declared in the config file as a
+regular expression with named groups. Every group becomes a field, usable in
+columns and level detection like the fields of the built-in parsers:
+
+	{
+	  "settings": {
+	    "parsers": {
+	      "custom": [
+	        {
+	          "name": "payments",
+	          "match": "^(?P<time>\\S+) (?P<sev>[A-Z]+) txn=(?P<txn>\\S+) amount=(?P<amount>[\\d.]+) (?P<msg>.*)$",
+	          "template": "{sev} {txn} {amount} {msg}",
+	          "fields": {
+	            "sev":    { "level": true },
+	            "txn":    { "color": "#5fafff" },
+	            "amount": { "type": "number", "color": "#ffaf00" }
+	          }
+	        }
+	      ]
+	    }
+	  }
+	}
+
+The template names groups in braces; without one the group values are joined
+with spaces. Field hints set a display color, a type ("string", "number" or
+"bool") for the field value, and mark the group holding the line's level.
+Custom parsers are tried before the built-in ones and can be named in "order"
+and "enabled" of the same settings block; a profile's definition replaces one
+of the same name. Since they come first, Detect only runs the expression on
+lines containing its longest required literal (" amount=" above).
+*/
+
+// Field types of custom parser hints
+const (
+	customFieldString = "string"
+	customFieldNumber = "number"
+	customFieldBool   = "bool"
+)
+
+// templatePlaceholderRegex matches a {group} placeholder of a template
+var templatePlaceholderRegex = regexp.MustCompile(`\{(\w+)\}`)
+
+// customParserDefinition is a parser declared in the config file
+type customParserDefinition struct {
+	Name     string                     `json:"name"`
+	Match    string                     `json:"match"`              // Regular expression with named groups
+	Template string                     `json:"template,omitempty"` // Display line with {group} placeholders
+	Fields   map[string]customFieldHint `json:"fields,omitempty"`   // Hints by group name
+
+	parser *customParser // Built by validate (see ParserSettings.validate)
+}
+
+// customFieldHint describes how one named group is typed and shown
+type customFieldHint struct {
+	Type  string `json:"type,omitempty"`  // "string" (default), "number" or "bool"
+	Color string `json:"color,omitempty"` // Display color, e.g. "#ffaf00" or "214"
+	Level bool   `json:"level,omitempty"` // The group holds the line's level
+}
+
+// customParser is a compiled customParserDefinition
+type customParser struct {
+	def     customParserDefinition
+	re      *regexp.Regexp
+	groups  []string // Named groups in pattern order
+	literal string   // Text every match contains, "" if none is required
+}
+
+// compile validates the definition and builds its parser
+func (d customParserDefinition) compile() (*customParser, error) {
+	if d.Name == "" {
+		return nil, fmt.Errorf("custom parser is missing a name")
+	}
+	for _, name := range parserNames() {
+		if strings.EqualFold(name, d.Name) {
+			return nil, fmt.Errorf("custom parser %q has the name of a built-in parser", d.Name)
+		}
+	}
+	re, err := regexp.Compile(d.Match)
+	if err != nil {
+		return nil, fmt.Errorf("custom parser %q: invalid match: %w", d.Name, err)
+	}
+
+	var groups []string
+	for _, group := range re.SubexpNames() {
+		if group != "" && !containsString(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("custom parser %q: match has no named groups", d.Name)
+	}
+
+	for _, m := range templatePlaceholderRegex.FindAllStringSubmatch(d.Template, -1) {
+		if !containsString(groups, m[1]) {
+			return nil, fmt.Errorf("custom parser %q: template uses unknown group %q", d.Name, m[1])
+		}
+	}
+	levels := 0
+	for group, hint := range d.Fields {
+		if !containsString(groups, group) {
+			return nil, fmt.Errorf("custom parser %q: hint for unknown group %q", d.Name, group)
+		}
+		switch hint.Type {
+		case "", customFieldString, customFieldNumber, customFieldBool:
+		default:
+			return nil, fmt.Errorf("custom parser %q: group %q has unknown type %q", d.Name, group, hint.Type)
+		}
+		if hint.Level {
+			levels++
+		}
+	}
+	if levels > 1 {
+		return nil, fmt.Errorf("custom parser %q: only one group can hold the level", d.Name)
+	}
+	return &customParser{def: d, re: re, groups: groups, literal: requiredLiteral(d.Match)}, nil
+}
+
+// requiredLiteral returns the longest case-sensitive literal that every match of pattern contains
+func requiredLiteral(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	longest := ""
+	var walk func(re *syntax.Regexp)
+	walk = func(re *syntax.Regexp) {
+		switch re.Op {
+		case syntax.OpLiteral:
+			if re.Flags&syntax.FoldCase == 0 && len(re.Rune) > len([]rune(longest)) {
+				longest = string(re.Rune)
+			}
+		case syntax.OpCapture, syntax.OpPlus:
+			walk(re.Sub[0]) // Matched at least once
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				walk(sub)
+			}
+		}
+	}
+	walk(re)
+	return longest
+}
+
+func (p *customParser) Name() string { return p.def.Name }
+
+// Detect skips lines without the literal the pattern requires; the match itself is done once in Parse
+func (p *customParser) Detect(message string) bool {
+	return strings.Contains(message, p.literal)
+}
+
+func (p *customParser) Parse(message string) (*parsedLog, bool) {
+	match := p.re.FindStringSubmatch(message)
+	if match == nil {
+		return nil, false
+	}
+
+	text := make(map[string]string, len(p.groups))
+	for i, group := range p.re.SubexpNames() {
+		if group != "" && text[group] == "" { // A repeated group name keeps its first value
+			text[group] = match[i]
+		}
+	}
+
+	fields := make(map[string]interface{}, len(text)+1)
+	for group, value := range text {
+		fields[group] = p.typedValue(group, value)
+		if p.def.Fields[group].Level {
+			fields["level"] = value
+		}
+	}
+	return &parsedLog{Fields: fields, Value: text}, true
+}
+
+// typedValue converts a group value according to its type hint
+func (p *customParser) typedValue(group, value string) interface{} {
+	switch p.def.Fields[group].Type {
+	case customFieldNumber:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case customFieldBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func (p *customParser) Render(parsed *parsedLog, config *UIConfig) string {
+	text := parsed.Value.(map[string]string)
+	show := func(group string) string {
+		value := text[group]
+		if value == "" {
+			value = "-"
+		}
+		if color := p.def.Fields[group].Color; color != "" && config.ColorizeFields {
+			return config.FieldStyle(color).Render(value)
+		}
+		return value
+	}
+
+	if p.def.Template == "" {
+		values := make([]string, len(p.groups))
+		for i, group := range p.groups {
+			values[i] = show(group)
+		}
+		return strings.Join(values, " ")
+	}
+	return templatePlaceholderRegex.ReplaceAllStringFunc(p.def.Template, func(placeholder string) string {
+		return show(placeholder[1 : len(placeholder)-1])
+	})
+}
+
+// mergeCustomParsers returns base with the definitions of other added; a definition replaces one of the same name
+func mergeCustomParsers(base, other []customParserDefinition) []customParserDefinition {
+	result := append([]customParserDefinition(nil), base...)
+	for _, def := range other {
+		replaced := false
+		for i := range result {
+			if result[i].Name == def.Name {
+				result[i], replaced = def, true
+			}
+		}
+		if !replaced {
+			result = append(result, def)
+		}
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+)
+
+const paymentsConfig = `{"settings":{"parsers":{"custom":[{
+	"name": "payments",
+	"match": "^(?P<time>\\S+) (?P<sev>[A-Z]+) txn=(?P<txn>\\S+) amount=(?P<amount>[\\d.]+) (?P<msg>.*)$",
+	"template": "{sev} {txn} {amount} {msg}",
+	"fields": {"sev": {"level": true}, "amount": {"type": "number", "color": "214"}}
+}]}}}`
+
+func TestCustomParser(t *testing.T) {
+	t.Run("FromConfigFile", func(t *testing.T) {
+		// Arrange
+		file, err := parseConfigFile([]byte(paymentsConfig))
+		assertNoError(t, err)
+		config := createTestConfig()
+		file.apply(config)
+
+		// Act
+		entry := makeLogEntry(testTimestamp, "2026-01-02T03:04:05Z WARN txn=t-42 amount=19.99 card declined", config)
+
+		// Assert
+		assertStringEqual(t, entry.Format, "payments")
+		assertStringEqual(t, stripANSI(entry.Message), "WARN t-42 19.99 card declined")
+		assertIntEqual(t, int(entry.Level), int(levelWarn), "level from hinted group")
+		if amount, ok := entry.Fields["amount"].(float64); !ok || amount != 19.99 {
+			t.Errorf("amount: got %#v, want 19.99", entry.Fields["amount"])
+		}
+		assertStringEqual(t, entry.Fields["txn"].(string), "t-42")
+
+		other := makeLogEntry(testTimestamp, `{"level":"info"}`, config)
+		assertStringEqual(t, other.Format, jsonParserName)
+	})
+
+	t.Run("NoTemplate", func(t *testing.T) {
+		p, err := customParserDefinition{Name: "kv", Match: `^(?P<a>\w+):(?P<b>\w*)$`}.compile()
+		assertNoError(t, err)
+		parsed, ok := p.Parse("x:")
+		assertBoolEqual(t, ok, true, "match")
+		assertStringEqual(t, p.Render(parsed, createTestConfig()), "x -")
+	})
+
+	t.Run("RequiredLiteral", func(t *testing.T) {
+		tests := []struct {
+			pattern string
+			want    string
+		}{
+			{`^(?P<time>\S+) (?P<sev>[A-Z]+) txn=(?P<txn>\S+) amount=(?P<amount>[\d.]+) (?P<msg>.*)$`, " amount="},
+			{`(?P<a>\w+)(?:ERROR)+`, "ERROR"},
+			{`(?P<a>foo|bar)`, ""},
+			{`(?i)(?P<a>timeout)`, ""},
+			{`(?P<a>x)?retry`, "retry"},
+		}
+		for _, tt := range tests {
+			assertStringEqual(t, requiredLiteral(tt.pattern), tt.want)
+		}
+	})
+
+	t.Run("DetectPrecheck", func(t *testing.T) {
+		// Arrange
+		file, err := parseConfigFile([]byte(paymentsConfig))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser := file.Settings.Parsers.Custom[0].parser
+
+		// Act & Assert - the chain reuses the parser compiled by validation
+		if parser == nil {
+			t.Fatal("validation should keep the compiled parser")
+		}
+		chain := buildParserChain(*file.Settings.Parsers)
+		if chain[0] != logParser(parser) {
+			t.Error("the chain should not compile the definition again")
+		}
+		assertBoolEqual(t, parser.Detect("2026-01-02T03:04:05Z WARN txn=t-42 amount=19.99 card declined"), true, "payment line")
+		assertBoolEqual(t, parser.Detect("GET /health 200"), false, "line without the literal")
+	})
+
+	t.Run("ProfileReplacesDefinition", func(t *testing.T) {
+		base := []customParserDefinition{{Name: "a", Match: "(?P<x>1)"}, {Name: "b", Match: "(?P<x>2)"}}
+		merged := mergeCustomParsers(base, []customParserDefinition{{Name: "a", Match: "(?P<y>3)"}})
+		assertIntEqual(t, len(merged), 2, "definitions")
+		assertStringEqual(t, merged[0].Match, "(?P<y>3)")
+	})
+
+	t.Run("InvalidDefinitions", func(t *testing.T) {
+		tests := []struct {
+			name string
+			def  string
+			want string
+		}{
+			{"no name", `{"match":"(?P<a>x)"}`, "missing a name"},
+			{"builtin name", `{"name":"json","match":"(?P<a>x)"}`, "built-in parser"},
+			{"bad regex", `{"name":"x","match":"("}`, "invalid match"},
+			{"no groups", `{"name":"x","match":"abc"}`, "no named groups"},
+			{"unknown template group", `{"name":"x","match":"(?P<a>x)","template":"{b}"}`, "unknown group \"b\""},
+			{"unknown type", `{"name":"x","match":"(?P<a>x)","fields":{"a":{"type":"date"}}}`, "unknown type"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Act
+				_, err := parseConfigFile([]byte(`{"settings":{"parsers":{"custom":[` + tt.def + `]}}}`))
+
+				// Assert
+				assertError(t, err, tt.want)
+			})
+		}
+	})
+}
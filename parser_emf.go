@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+Embedded Metric Format
+
+CloudWatch EMF events are JSON objects whose _aws.CloudWatchMetrics directives
+name the metrics and dimensions stored in the other keys:
+
+	{"_aws":{"Timestamp":1700000000000,"CloudWatchMetrics":[{"Namespace":"Shop",
+	  "Dimensions":[["Service","Operation"]],"Metrics":[{"Name":"Latency","Unit":"Milliseconds"}]}]},
+	 "Service":"cart","Operation":"Add","Latency":12.5}
+
+They are rendered as the namespace, the dimensions and each metric with its
+unit:
+
+	Shop {Service=cart, Operation=Add} Latency=12.5 (Milliseconds)
+
+The fields are the event's keys, so columns and level detection keep working.
+M opens an overlay with statistics of every metric over the buffer (see
+metricstats.go).
+*/
+
+const emfParserName = "emf"
+
+// emfMetric is one metric value set of an EMF event, for one dimension set
+type emfMetric struct {
+	Namespace  string
+	Name       string
+	Unit       string
+	Dimensions string // "Service=cart,Operation=Add" in declared order; "" when undimensioned
+	Values     []float64
+}
+
+// emfParser handles CloudWatch Embedded Metric Format events
+type emfParser struct{}
+
+func (emfParser) Name() string { return emfParserName }
+
+func (emfParser) Detect(message string) bool {
+	return strings.HasPrefix(message, "{") && strings.Contains(message, `"CloudWatchMetrics"`)
+}
+
+func (emfParser) Parse(message string) (*parsedLog, bool) {
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &event); err != nil {
+		return nil, false
+	}
+	if _, ok := emfDirectives(event); !ok {
+		return nil, false
+	}
+	return &parsedLog{Fields: event, Value: event}, true
+}
+
+func (emfParser) Render(parsed *parsedLog, config *UIConfig) string {
+	event := parsed.Value.(map[string]interface{})
+	directives, _ := emfDirectives(event)
+	colors := config.Colors
+	style := func(color, text string) string {
+		if !config.ColorizeFields {
+			return text
+		}
+		return config.FieldStyle(color).Render(text)
+	}
+
+	var parts []string
+	for _, directive := range directives {
+		namespace, _ := directive["Namespace"].(string)
+		parts = append(parts, style(colors.HeaderColor, namespace))
+
+		var dimensions []string
+		for _, name := range emfDimensionNames(directive) {
+			dimensions = append(dimensions, name+"="+formatFieldValue(event[name]))
+		}
+		if len(dimensions) > 0 {
+			parts = append(parts, style(colors.SizeColor, "{"+strings.Join(dimensions, ", ")+"}"))
+		}
+
+		for _, metric := range emfMetricDefinitions(directive) {
+			value := style(colors.JSONNumberColor, formatFieldValue(event[metric.Name]))
+			pair := style(colors.JSONKeyColor, metric.Name) + "=" + value
+			if metric.Unit != "" && metric.Unit != "None" {
+				pair += " " + style(colors.MutedColor, "("+metric.Unit+")")
+			}
+			parts = append(parts, pair)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// emfDirectives returns the CloudWatchMetrics directives of an event
+func emfDirectives(event map[string]interface{}) ([]map[string]interface{}, bool) {
+	aws, _ := event["_aws"].(map[string]interface{})
+	list, ok := aws["CloudWatchMetrics"].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	directives := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		directive, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		directives = append(directives, directive)
+	}
+	return directives, true
+}
+
+// emfMetricDefinitions returns the Name and Unit of each metric of a directive
+func emfMetricDefinitions(directive map[string]interface{}) []emfMetric {
+	list, _ := directive["Metrics"].([]interface{})
+	metrics := make([]emfMetric, 0, len(list))
+	for _, item := range list {
+		definition, _ := item.(map[string]interface{})
+		name, _ := definition["Name"].(string)
+		unit, _ := definition["Unit"].(string)
+		if name != "" {
+			metrics = append(metrics, emfMetric{Name: name, Unit: unit})
+		}
+	}
+	return metrics
+}
+
+// emfDimensionSets returns the dimension sets of a directive
+func emfDimensionSets(directive map[string]interface{}) [][]string {
+	list, _ := directive["Dimensions"].([]interface{})
+	var sets [][]string
+	for _, item := range list {
+		names, _ := item.([]interface{})
+		set := make([]string, 0, len(names))
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				set = append(set, s)
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// emfDimensionNames lists every dimension of a directive once, in declared order
+func emfDimensionNames(directive map[string]interface{}) []string {
+	var names []string
+	for _, set := range emfDimensionSets(directive) {
+		for _, name := range set {
+			if !containsString(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// emfMetrics returns every metric of an event once per dimension set, as CloudWatch stores them
+func emfMetrics(event map[string]interface{}) []emfMetric {
+	directives, _ := emfDirectives(event)
+	var metrics []emfMetric
+	for _, directive := range directives {
+		namespace, _ := directive["Namespace"].(string)
+		sets := emfDimensionSets(directive)
+		if len(sets) == 0 {
+			sets = [][]string{nil}
+		}
+		for _, definition := range emfMetricDefinitions(directive) {
+			values := emfValues(event[definition.Name])
+			if len(values) == 0 {
+				continue
+			}
+			for _, set := range sets {
+				dimensions := make([]string, len(set))
+				for i, name := range set {
+					dimensions[i] = name + "=" + formatFieldValue(event[name])
+				}
+				metrics = append(metrics, emfMetric{
+					Namespace:  namespace,
+					Name:       definition.Name,
+					Unit:       definition.Unit,
+					Dimensions: strings.Join(dimensions, ","),
+					Values:     values,
+				})
+			}
+		}
+	}
+	return metrics
+}
+
+// emfValues reads a metric value, which is a number or an array of numbers
+func emfValues(value interface{}) []float64 {
+	switch v := value.(type) {
+	case float64:
+		return []float64{v}
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return []float64{f}
+		}
+	case []interface{}:
+		var values []float64
+		for _, item := range v {
+			values = append(values, emfValues(item)...)
+		}
+		return values
+	}
+	return nil
+}
+
+// formatMetricValue renders a statistic with at most two decimals
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// emfEvent builds an EMF event with one latency value
+func emfEvent(operation string, latency string) string {
+	return fmt.Sprintf(`{"_aws":{"Timestamp":1700000000000,"CloudWatchMetrics":[{"Namespace":"Shop",`+
+		`"Dimensions":[["Service","Operation"],["Service"]],"Metrics":[{"Name":"Latency","Unit":"Milliseconds"},{"Name":"Errors"}]}]},`+
+		`"Service":"cart","Operation":%q,"Latency":%s,"Errors":0,"requestId":"r-1"}`, operation, latency)
+}
+
+func TestEMFParser(t *testing.T) {
+	t.Run("Render", func(t *testing.T) {
+		// Act
+		entry := makeLogEntry(testTimestamp, emfEvent("Add", "12.5"), createTestConfig())
+
+		// Assert
+		assertStringEqual(t, entry.Format, emfParserName)
+		assertStringEqual(t, stripANSI(entry.Message), "Shop {Service=cart, Operation=Add} Latency=12.5 (Milliseconds) Errors=0")
+		assertStringEqual(t, entry.Fields["requestId"].(string), "r-1")
+	})
+
+	t.Run("NotEMF", func(t *testing.T) {
+		entry := makeLogEntry(testTimestamp, `{"_aws":{"CloudWatchMetrics":"none"},"a":1}`, createTestConfig())
+		assertStringEqual(t, entry.Format, jsonParserName)
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+		logs := []logEntry{
+			makeLogEntry(testTimestamp, emfEvent("Add", "10"), config),
+			makeLogEntry(testTimestamp, emfEvent("Add", "[20,30]"), config),
+			makeLogEntry(testTimestamp, emfEvent("Remove", "5"), config),
+			makeLogEntry(testTimestamp, "plain line", config),
+		}
+
+		// Act
+		stats := aggregateMetrics(logs)
+
+		// Assert - metrics sort by name, then dimension set
+		var got []string
+		for _, stat := range stats {
+			got = append(got, fmt.Sprintf("%s %s count=%d min=%s max=%s avg=%s", stat.Name, stat.Dimensions, stat.Count,
+				formatMetricValue(stat.Min), formatMetricValue(stat.Max), formatMetricValue(stat.Sum/float64(stat.Count))))
+		}
+		want := []string{
+			"Errors Service=cart count=3 min=0 max=0 avg=0",
+			"Errors Service=cart,Operation=Add count=2 min=0 max=0 avg=0",
+			"Errors Service=cart,Operation=Remove count=1 min=0 max=0 avg=0",
+			"Latency Service=cart count=4 min=5 max=30 avg=16.25",
+			"Latency Service=cart,Operation=Add count=3 min=10 max=30 avg=20",
+			"Latency Service=cart,Operation=Remove count=1 min=5 max=5 avg=5",
+		}
+		assertIntEqual(t, len(got), len(want), "metric rows")
+		for i := range want {
+			assertStringEqual(t, got[i], want[i])
+		}
+	})
+}
+
+func TestMetricStatsOverlay(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	model.store.Append(makeLogEntry(testTimestamp, emfEvent("Add", "12.5"), model.config))
+	model.followMode = false
+	simulateWindowResize(model, 160, 40)
+
+	// Act
+	simulateKeyPress(model, "M")
+
+	// Assert
+	if model.stats == nil {
+		t.Fatal("stats overlay should be open")
+	}
+	view := model.View()
+	assertStringContains(t, view, "Latency  Service=cart,Operation=Add  1      12.5  12.5  12.5  Milliseconds")
+
+	simulateKeyPress(model, "esc")
+	if model.stats != nil {
+		t.Error("esc should close the stats overlay")
+	}
+}
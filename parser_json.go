@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const jsonParserName = "json"
+
+// maxNestedJSONDepth limits how many levels of JSON-in-string are decoded
+const maxNestedJSONDepth = 8
+
+// jsonParser handles messages that are a JSON object or array
+type jsonParser struct{}
+
+func (jsonParser) Name() string { return jsonParserName }
+
+func (jsonParser) Detect(message string) bool {
+	return len(message) > 0 && (message[0] == '{' || message[0] == '[')
+}
+
+// Parse decodes the message into an ordered tree; the fields are the
+// top-level keys of an object. String values that hold JSON are decoded too.
+func (jsonParser) Parse(message string) (*parsedLog, bool) {
+	root, err := parseJSONTree(message)
+	if err != nil {
+		return nil, false
+	}
+	fields, _ := decodeNestedJSON(root.interfaceValue(), 0).(map[string]interface{})
+	return &parsedLog{Fields: fields, Value: root}, true
+}
+
+func (jsonParser) Render(parsed *parsedLog, config *UIConfig) string {
+	return indentJSON(parsed.Value.(*jsonNode), config.JSONIndent, config)
+}
+
+// decodeNestedJSON replaces string values that contain a JSON object or array
+// with the decoded value, recursively, so escaped payloads render as structure
+func decodeNestedJSON(value interface{}, depth int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = decodeNestedJSON(child, depth)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = decodeNestedJSON(child, depth)
+		}
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if depth >= maxNestedJSONDepth || len(trimmed) < 2 || (trimmed[0] != '{' && trimmed[0] != '[') {
+			return v
+		}
+		var decoded interface{}
+		if json.Unmarshal([]byte(trimmed), &decoded) != nil {
+			return v
+		}
+		return decodeNestedJSON(decoded, depth+1)
+	}
+	return value
+}
+
+// jsonObjectEnd returns the end of the balanced object or array starting at
+// s[start], skipping brackets inside strings, or -1 when it is not closed
+func jsonObjectEnd(s string, start int) int {
+	var stack []byte
+	inString, escaped := false, false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{':
+			stack = append(stack, '}')
+		case c == '[':
+			stack = append(stack, ']')
+		case c == '}' || c == ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				return -1
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// findEmbeddedJSON returns the [start, end) spans of the JSON objects in a
+// plain text message, at any nesting depth
+func findEmbeddedJSON(message string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(message); i++ {
+		if message[i] != '{' {
+			continue
+		}
+		end := jsonObjectEnd(message, i)
+		if end < 0 || !json.Valid([]byte(message[i:end])) {
+			continue
+		}
+		spans = append(spans, [2]int{i, end})
+		i = end - 1
+	}
+	return spans
+}
+
+// formatEmbeddedJSON pretty-prints JSON objects found inside a plain text
+// message, such as the payload after an "INFO 2026-01-02T03:04:05Z " prefix
+func formatEmbeddedJSON(message string, config *UIConfig) string {
+	spans := findEmbeddedJSON(message)
+	if len(spans) == 0 {
+		return message
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		b.WriteString(message[last:span[0]])
+		if root, err := parseJSONTree(message[span[0]:span[1]]); err == nil {
+			b.WriteString(indentJSON(root, config.JSONIndent, config))
+		} else {
+			b.WriteString(message[span[0]:span[1]])
+		}
+		last = span[1]
+	}
+	b.WriteString(message[last:])
+	return b.String()
+}
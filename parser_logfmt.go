@@ -0,0 +1,203 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+logfmt
+
+Lines of key=value pairs, as written by Go's slog, logrus and go-kit:
+
+	time=2024-10-16T12:00:00Z level=info msg="request served" path=/api dur=12ms
+
+Values may be quoted with backslash escapes; a key without "=" is a flag
+(true). A line counts as logfmt only when every word is a pair or flag, there
+are at least two pairs and pairs outnumber flags, so prose with a few "=" in
+it ("retrying id=5 attempt=2 after timeout") is left alone.
+
+Rendered with time, level and msg first, then the other pairs in their original
+order, keys and values in their own colors. Values are exposed as strings.
+*/
+
+const logfmtParserName = "logfmt"
+
+// logfmtMinPairs is the number of key=value pairs a logfmt line needs
+const logfmtMinPairs = 2
+
+// logfmtTimeKeys, logfmtLevelKeys and logfmtMessageKeys are promoted to fixed positions
+var (
+	logfmtTimeKeys    = []string{"time", "ts", "timestamp", "t"}
+	logfmtLevelKeys   = []string{"level", "lvl", "severity"}
+	logfmtMessageKeys = []string{"msg", "message"}
+)
+
+// logfmtPair is one key=value of a line; Flag marks a key without a value
+type logfmtPair struct {
+	Key   string
+	Value string
+	Flag  bool
+}
+
+// logfmtParser handles key=value lines
+type logfmtParser struct{}
+
+func (logfmtParser) Name() string { return logfmtParserName }
+
+func (logfmtParser) Detect(message string) bool {
+	eq := strings.IndexByte(message, '=')
+	return eq > 0 && message[0] != '{' && message[0] != '[' && !strings.ContainsAny(message[:eq], " \t\"")
+}
+
+func (logfmtParser) Parse(message string) (*parsedLog, bool) {
+	pairs, ok := tokenizeLogfmt(message)
+	if !ok {
+		return nil, false
+	}
+	count, flags := 0, 0
+	fields := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		if pair.Flag {
+			fields[pair.Key] = true
+			flags++
+			continue
+		}
+		fields[pair.Key] = pair.Value
+		count++
+	}
+	if count < logfmtMinPairs || count <= flags {
+		return nil, false
+	}
+	return &parsedLog{Fields: fields, Value: pairs}, true
+}
+
+func (logfmtParser) Render(parsed *parsedLog, config *UIConfig) string {
+	pairs := parsed.Value.([]logfmtPair)
+	colors := config.Colors
+	colorize := config.ColorizeFields
+
+	var promoted []string
+	used := make(map[int]bool)
+	take := func(keys []string) (string, bool) {
+		for _, key := range keys {
+			for i, pair := range pairs {
+				if pair.Key == key && !pair.Flag && !used[i] {
+					used[i] = true
+					return pair.Value, true
+				}
+			}
+		}
+		return "", false
+	}
+
+	if value, ok := take(logfmtTimeKeys); ok {
+		if colorize {
+			value = config.FieldStyle(colors.SizeColor).Render(value)
+		}
+		promoted = append(promoted, value)
+	}
+	if value, ok := take(logfmtLevelKeys); ok {
+		value = strings.ToUpper(value)
+		if colorize {
+			style, leveled := config.LevelStyle(parseLevelName(value))
+			if !leveled {
+				style = config.FieldStyle(colors.FieldColor)
+			}
+			value = style.Bold(true).Render(value)
+		}
+		promoted = append(promoted, value)
+	}
+	if value, ok := take(logfmtMessageKeys); ok {
+		if colorize {
+			value = config.FieldStyle(colors.FieldColor).Bold(true).Render(value)
+		}
+		promoted = append(promoted, value)
+	}
+
+	parts := promoted
+	for i, pair := range pairs {
+		if used[i] {
+			continue
+		}
+		parts = append(parts, formatLogfmtPair(pair, config))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatLogfmtPair renders one pair, quoting values that need it
+func formatLogfmtPair(pair logfmtPair, config *UIConfig) string {
+	key := pair.Key
+	if config.ColorizeFields {
+		key = config.FieldStyle(config.Colors.IPColor).Render(key)
+	}
+	if pair.Flag {
+		return key
+	}
+	value := pair.Value
+	if value == "" || strings.ContainsAny(value, " =\"\t") {
+		value = strconv.Quote(value)
+	}
+	if config.ColorizeFields {
+		value = config.FieldStyle(config.Colors.FieldColor).Render(value)
+	}
+	return key + "=" + value
+}
+
+// tokenizeLogfmt splits a line into pairs. It fails on words that are not
+// pairs or flags, and on unterminated quotes.
+func tokenizeLogfmt(line string) ([]logfmtPair, bool) {
+	var pairs []logfmtPair
+	i := 0
+	for i < len(line) {
+		if line[i] == ' ' || line[i] == '\t' {
+			i++
+			continue
+		}
+
+		// Key: up to "=" or whitespace
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '\t' {
+			if line[i] == '"' {
+				return nil, false
+			}
+			i++
+		}
+		key := line[start:i]
+		if i >= len(line) || line[i] != '=' {
+			pairs = append(pairs, logfmtPair{Key: key, Flag: true})
+			continue
+		}
+		if key == "" {
+			return nil, false
+		}
+		i++ // "="
+
+		// Value: quoted with escapes, or up to whitespace
+		if i < len(line) && line[i] == '"' {
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, false
+			}
+			value, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				value = line[i+1 : end]
+			}
+			pairs = append(pairs, logfmtPair{Key: key, Value: value})
+			i = end + 1
+			continue
+		}
+		start = i
+		for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			i++
+		}
+		pairs = append(pairs, logfmtPair{Key: key, Value: line[start:i]})
+	}
+	return pairs, true
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestLogfmtParser(t *testing.T) {
+	t.Run("Tokenize", func(t *testing.T) {
+		pairs, ok := tokenizeLogfmt(`a=1 msg="hello \"world\" x=y" empty="" debug path=/a=b`)
+		assertBoolEqual(t, ok, true, "tokenize")
+		want := []logfmtPair{
+			{Key: "a", Value: "1"},
+			{Key: "msg", Value: `hello "world" x=y`},
+			{Key: "empty", Value: ""},
+			{Key: "debug", Flag: true},
+			{Key: "path", Value: "/a=b"},
+		}
+		assertIntEqual(t, len(pairs), len(want), "pairs")
+		for i := range want {
+			if pairs[i] != want[i] {
+				t.Errorf("pair %d: got %+v, want %+v", i, pairs[i], want[i])
+			}
+		}
+
+		_, ok = tokenizeLogfmt(`msg="unterminated`)
+		assertBoolEqual(t, ok, false, "unterminated quote")
+	})
+
+	t.Run("PromotedFields", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+		line := `dur=12ms msg="request served" path=/api level=info time=2024-10-16T12:00:00Z`
+
+		// Act
+		entry := makeLogEntry(testTimestamp, line, config)
+
+		// Assert
+		assertStringEqual(t, entry.Format, logfmtParserName)
+		assertStringEqual(t, stripANSI(entry.Message), "2024-10-16T12:00:00Z INFO request served dur=12ms path=/api")
+		assertStringEqual(t, entry.Fields["dur"].(string), "12ms")
+		assertIntEqual(t, int(entry.Level), int(levelInfo), "level")
+	})
+
+	t.Run("QuotesKeptWhenNeeded", func(t *testing.T) {
+		entry := makeLogEntry(testTimestamp, `level=warn err="connection refused" retry`, createTestConfig())
+		assertStringEqual(t, stripANSI(entry.Message), `WARN err="connection refused" retry`)
+	})
+
+	t.Run("NotLogfmt", func(t *testing.T) {
+		config := createTestConfig()
+		for _, line := range []string{
+			"ERRORS_TOTAL=3",
+			"retrying with timeout=30s after failure",
+			`{"level":"info","a":"b=c"}`,
+			"user said a=b and c=d",
+			"retrying request id=5 attempt=2 after timeout",
+			"id=5 attempt=2 retrying the request after a timeout",
+		} {
+			if entry := makeLogEntry(testTimestamp, line, config); entry.Format == logfmtParserName {
+				t.Errorf("%q parsed as logfmt", line)
+			}
+		}
+	})
+}
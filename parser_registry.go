@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+Parser Registry
+
+Formatted mode runs every message through a chain of logParsers. Each parser
+has three steps:
+
+	Detect - a cheap check whether the message looks like its format
+	Parse  - extract structured fields (exposed on logEntry.Fields)
+	Render - turn the parsed fields into the display line
+
+The first parser whose Detect and Parse both succeed wins. Messages no parser
+claims are shown as-is, with embedded JSON objects pretty-printed.
+
+Parsers run in registration order unless the config file reorders or disables
+them (globally or per profile):
+
+	{
+	  "settings": {
+	    "parsers": { "order": ["json", "accessLog"], "enabled": { "accessLog": false } }
+	  }
+	}
+
+Parsers listed in "order" come first; the rest keep their default order.
+Parsers declared under "custom" (see parser_custom.go) precede the built-in ones.
+*/
+
+// logParser is one log format understood by formatted mode
+type logParser interface {
+	Name() string
+	Detect(message string) bool
+	Parse(message string) (*parsedLog, bool)
+	Render(parsed *parsedLog, config *UIConfig) string
+}
+
+// parsedLog is the result of a parser's Parse step
+type parsedLog struct {
+	Format string                 // Name of the parser that produced it
+	Fields map[string]interface{} // Named fields, JSON-like values
+	Value  interface{}            // Parser specific data used by Render
+
+	parser logParser
+}
+
+// largeMessageParser is implemented by parsers that handle messages longer
+// than maxMessageLength; other parsers never see them
+type largeMessageParser interface {
+	acceptsLargeMessages() bool
+}
+
+// nestedParser is implemented by parsers whose messages wrap another message,
+// which parseNested runs through the chain once Parse succeeded
+type nestedParser interface {
+	parseNested(parsed *parsedLog, config *UIConfig)
+}
+
+// acceptsLargeMessages reports whether a parser handles messages of any length
+func acceptsLargeMessages(p logParser) bool {
+	large, ok := p.(largeMessageParser)
+	return ok && large.acceptsLargeMessages()
+}
+
+// ParserSettings orders and enables parsers by name
+type ParserSettings struct {
+	Order   []string        `json:"order,omitempty"`   // Parsers tried first, in this order
+	Enabled map[string]bool `json:"enabled,omitempty"` // false disables a parser
+
+	FlowLogFormat string `json:"flowLogFormat,omitempty"` // VPC flow log field order, e.g. "${srcaddr} ${dstaddr} ${action}"
+
+	Custom []customParserDefinition `json:"custom,omitempty"` // Regex parsers from the config file
+}
+
+// builtinParsers returns every known parser in default detection order
+func builtinParsers() []logParser {
+	return []logParser{
+		accessLogParser{},
+		clfParser{},
+		albParser{},
+		cloudFrontParser{},
+		newFlowLogParser(nil),
+		containerParser{},
+		cloudTrailParser{},
+		emfParser{},
+		jsonParser{},
+		logfmtParser{},
+		lambdaParser{},
+	}
+}
+
+// defaultParserChain is used by configs that never had parser settings applied
+var defaultParserChain = builtinParsers()
+
+// parserNames lists the names of the built-in parsers in default order
+func parserNames() []string {
+	parsers := builtinParsers()
+	names := make([]string, len(parsers))
+	for i, p := range parsers {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// validate rejects unknown or repeated parser names and broken custom parsers,
+// and keeps the compiled custom parsers for buildParserChain
+func (s *ParserSettings) validate() error {
+	names := parserNames()
+	for i := range s.Custom {
+		def := &s.Custom[i]
+		parser, err := def.compile()
+		if err != nil {
+			return fmt.Errorf("parsers: %w", err)
+		}
+		def.parser = parser
+		if containsString(names, def.Name) {
+			return fmt.Errorf("parsers: custom parser %q defined twice", def.Name)
+		}
+		names = append(names, def.Name)
+	}
+
+	known := make(map[string]bool)
+	for _, name := range names {
+		known[name] = true
+	}
+	unknown := func(name string) error {
+		return fmt.Errorf("parsers: unknown parser %q (known: %s)", name, strings.Join(names, ", "))
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range s.Order {
+		if !known[name] {
+			return unknown(name)
+		}
+		if seen[name] {
+			return fmt.Errorf("parsers: %q listed twice in order", name)
+		}
+		seen[name] = true
+	}
+
+	enabled := make([]string, 0, len(s.Enabled))
+	for name := range s.Enabled {
+		enabled = append(enabled, name)
+	}
+	sort.Strings(enabled)
+	for _, name := range enabled {
+		if !known[name] {
+			return unknown(name)
+		}
+	}
+
+	if s.FlowLogFormat != "" {
+		if _, err := parseFlowLogFormat(s.FlowLogFormat); err != nil {
+			return fmt.Errorf("parsers: flowLogFormat: %w", err)
+		}
+	}
+	return nil
+}
+
+// merge returns s with other applied on top: a set order or flow log format replaces, enabled flags
+// and custom parsers merge
+func (s ParserSettings) merge(other ParserSettings) ParserSettings {
+	result := ParserSettings{
+		Order:         s.Order,
+		Enabled:       make(map[string]bool, len(s.Enabled)+len(other.Enabled)),
+		FlowLogFormat: s.FlowLogFormat,
+		Custom:        mergeCustomParsers(s.Custom, other.Custom),
+	}
+	if len(other.Order) > 0 {
+		result.Order = other.Order
+	}
+	if other.FlowLogFormat != "" {
+		result.FlowLogFormat = other.FlowLogFormat
+	}
+	for name, enabled := range s.Enabled {
+		result.Enabled[name] = enabled
+	}
+	for name, enabled := range other.Enabled {
+		result.Enabled[name] = enabled
+	}
+	return result
+}
+
+// buildParserChain returns the enabled parsers in detection order
+func buildParserChain(settings ParserSettings) []logParser {
+	byName := make(map[string]logParser)
+	var defaults []logParser
+	for _, def := range settings.Custom {
+		p := def.parser
+		if p == nil { // Settings built without validate
+			var err error
+			if p, err = def.compile(); err != nil {
+				continue
+			}
+		}
+		byName[p.Name()] = p
+		defaults = append(defaults, p)
+	}
+	for _, p := range builtinParsers() {
+		if p.Name() == flowLogParserName && settings.FlowLogFormat != "" {
+			fields, _ := parseFlowLogFormat(settings.FlowLogFormat) // Checked by validate
+			p = newFlowLogParser(fields)
+		}
+		byName[p.Name()] = p
+		defaults = append(defaults, p)
+	}
+
+	chain := []logParser{} // Non-nil even when everything is disabled
+	added := make(map[string]bool)
+	add := func(p logParser) {
+		name := p.Name()
+		if added[name] {
+			return
+		}
+		added[name] = true
+		if enabled, ok := settings.Enabled[name]; ok && !enabled {
+			return
+		}
+		chain = append(chain, p)
+	}
+
+	for _, name := range settings.Order {
+		if p, ok := byName[name]; ok {
+			add(p)
+		}
+	}
+	for _, p := range defaults {
+		add(p)
+	}
+	return chain
+}
+
+// parserChain returns the parsers the config enables, in detection order
+func (c *UIConfig) parserChain() []logParser {
+	if c.parsers == nil {
+		return defaultParserChain
+	}
+	return c.parsers
+}
+
+// setParserSettings replaces the parser settings and rebuilds the chain
+func (c *UIConfig) setParserSettings(settings ParserSettings) {
+	c.ParserSettings = settings
+	c.parsers = buildParserChain(settings)
+}
+
+// parseLogMessage runs the message through the parser chain.
+// It returns nil when no enabled parser understands the message.
+func parseLogMessage(message string, config *UIConfig) *parsedLog {
+	large := len(message) > maxMessageLength
+	for _, p := range config.parserChain() {
+		if large && !acceptsLargeMessages(p) {
+			continue
+		}
+		if !p.Detect(message) {
+			continue
+		}
+		if parsed, ok := p.Parse(message); ok {
+			parsed.Format = p.Name()
+			parsed.parser = p
+			if nested, ok := p.(nestedParser); ok {
+				nested.parseNested(parsed, config)
+			}
+			return parsed
+		}
+	}
+	return nil
+}
+
+// renderEnabled reports whether the display toggles allow a parser's rendering.
+// JSON follows PrettyPrintJSON; every other format follows ParseAccessLogs.
+func renderEnabled(format string, config *UIConfig) bool {
+	if format == jsonParserName {
+		return config.PrettyPrintJSON
+	}
+	return config.ParseAccessLogs
+}
+
+// parserEnabled reports whether a parser is part of the config's chain
+func parserEnabled(name string, config *UIConfig) bool {
+	for _, p := range config.parserChain() {
+		if p.Name() == name {
+			return true
+		}
+	}
+	return false
+}
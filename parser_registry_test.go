@@ -0,0 +1,169 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testTimestamp is a fixed time for entries built by parser tests
+var testTimestamp = time.Date(2025, 10, 20, 14, 30, 0, 0, time.UTC)
+
+const testAccessLogLine = `192.168.1.1 - - [20/Oct/2025:14:30:00 +0000] "GET /api/users HTTP/1.1" 200 1234 "-" "curl/8.0"`
+
+func chainNames(chain []logParser) []string {
+	names := make([]string, len(chain))
+	for i, p := range chain {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+func TestParserRegistry(t *testing.T) {
+	t.Run("DefaultOrder", func(t *testing.T) {
+		got := chainNames(NewUIConfig().parserChain())
+		if !reflect.DeepEqual(got, parserNames()) {
+			t.Errorf("chain: got %v, want %v", got, parserNames())
+		}
+		assertStringEqual(t, got[0], accessLogParserName)
+	})
+
+	t.Run("OrderAndEnabled", func(t *testing.T) {
+		// Reordered parsers come first, the rest keep the default order
+		got := chainNames(buildParserChain(ParserSettings{Order: []string{jsonParserName}}))
+		assertStringEqual(t, got[0], jsonParserName)
+		assertStringEqual(t, got[1], accessLogParserName)
+		assertIntEqual(t, len(got), len(parserNames()), "reordered chain length")
+
+		// Disabled parsers are dropped
+		got = chainNames(buildParserChain(ParserSettings{Enabled: map[string]bool{accessLogParserName: false}}))
+		for _, name := range got {
+			if name == accessLogParserName {
+				t.Errorf("disabled parser still in chain %v", got)
+			}
+		}
+		assertIntEqual(t, len(got), len(parserNames())-1, "chain length without accessLog")
+
+		// Explicitly enabled parsers keep their place
+		got = chainNames(buildParserChain(ParserSettings{Enabled: map[string]bool{jsonParserName: true}}))
+		if !reflect.DeepEqual(got, parserNames()) {
+			t.Errorf("chain: got %v, want %v", got, parserNames())
+		}
+
+		// Everything disabled is an empty chain, not the default one
+		all := ParserSettings{Enabled: map[string]bool{}}
+		for _, name := range parserNames() {
+			all.Enabled[name] = false
+		}
+		assertIntEqual(t, len(buildParserChain(all)), 0, "all disabled")
+	})
+
+	t.Run("DisabledParserLeavesMessageRaw", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+		config.setParserSettings(ParserSettings{Enabled: map[string]bool{jsonParserName: false}})
+		input := `{"b":1,"a":2}`
+
+		// Act
+		entry := makeLogEntry(testTimestamp, input, config)
+
+		// Assert
+		assertStringEqual(t, entry.Message, input)
+		assertStringEqual(t, entry.Format, "")
+	})
+
+	t.Run("FieldsExposedOnEntry", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+
+		// Act
+		access := makeLogEntry(testTimestamp, testAccessLogLine, config)
+		jsonEntry := makeLogEntry(testTimestamp, `{"level":"error","count":3}`, config)
+
+		// Assert
+		assertStringEqual(t, access.Format, accessLogParserName)
+		assertStringEqual(t, access.Fields["status"].(string), "200")
+		assertStringEqual(t, access.Fields["user_agent"].(string), "curl/8.0")
+		assertStringEqual(t, jsonEntry.Format, jsonParserName)
+		assertStringEqual(t, jsonEntry.Fields["level"].(string), "error")
+	})
+
+	t.Run("FieldsParsedInRawMode", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+		config.ParseAccessLogs, config.PrettyPrintJSON = false, false
+
+		// Act
+		entry := makeLogEntry(testTimestamp, `{"level":"warn"}`, config)
+
+		// Assert
+		assertStringEqual(t, entry.Message, `{"level":"warn"}`)
+		assertStringEqual(t, entry.Fields["level"].(string), "warn")
+	})
+
+	t.Run("RenderFollowsDisplayToggles", func(t *testing.T) {
+		// Arrange - access log rendering off, JSON on
+		config := createTestConfig()
+		config.ParseAccessLogs = false
+
+		// Act
+		result := formatLogMessage(testAccessLogLine, config)
+
+		// Assert
+		assertStringEqual(t, result, testAccessLogLine)
+	})
+
+	t.Run("ParsersInIsolation", func(t *testing.T) {
+		parser := accessLogParser{}
+		assertBoolEqual(t, parser.Detect("plain text"), false, "detect plain text")
+		assertBoolEqual(t, parser.Detect(testAccessLogLine), true, "detect access log")
+
+		parsed, ok := parser.Parse(testAccessLogLine)
+		assertBoolEqual(t, ok, true, "parse access log")
+		rendered := parser.Render(parsed, &UIConfig{})
+		assertStringEqual(t, rendered, "192.168.1.1 GET /api/users 200 1234")
+
+		_, ok = jsonParser{}.Parse(`{"broken"`)
+		assertBoolEqual(t, ok, false, "parse broken JSON")
+	})
+
+	t.Run("ConfigFile", func(t *testing.T) {
+		// Arrange
+		file, err := parseConfigFile([]byte(`{
+			"settings": {"parsers": {"order": ["json"]}},
+			"profiles": [{"name": "p", "match": "/raw/*", "settings": {"parsers": {"enabled": {"json": false}}}}]
+		}`))
+		assertNoError(t, err)
+		config := NewUIConfig()
+
+		// Act
+		file.apply(config)
+		profiled := config.ForLogGroup("/raw/app")
+
+		// Assert
+		base := chainNames(config.parserChain())
+		assertStringEqual(t, base[0], jsonParserName)
+		assertBoolEqual(t, parserEnabled(jsonParserName, profiled), false, "profile disables json")
+		assertBoolEqual(t, parserEnabled(accessLogParserName, profiled), true, "profile keeps accessLog")
+		assertBoolEqual(t, parserEnabled(jsonParserName, config), true, "base config keeps json")
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		tests := []struct {
+			name string
+			data string
+			want string
+		}{
+			{"unknown in order", `{"settings":{"parsers":{"order":["yaml"]}}}`, "unknown parser"},
+			{"unknown enabled", `{"settings":{"parsers":{"enabled":{"yaml":true}}}}`, "unknown parser"},
+			{"duplicate", `{"settings":{"parsers":{"order":["json","json"]}}}`, "listed twice"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := parseConfigFile([]byte(tt.data))
+				assertError(t, err, tt.want)
+			})
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -28,7 +29,8 @@ func TestParser(t *testing.T) {
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
 				// Act
-				got := isJSON(tt.input)
+				_, err := parseJSONTree(tt.input)
+				got := err == nil
 				
 				// Assert
 				assertBoolEqual(t, got, tt.want, "JSON detection")
@@ -42,7 +44,7 @@ func TestParser(t *testing.T) {
 			input := `{"key":"value","number":42}`
 			
 			// Act
-			result := formatJSON(input, "  ")
+			result := indentTestJSON(t, input)
 			
 			// Assert
 			assertStringContains(t, result, "\n")
@@ -55,7 +57,7 @@ func TestParser(t *testing.T) {
 			input := `{"outer":{"inner":"value"}}`
 			
 			// Act
-			result := formatJSON(input, "  ")
+			result := indentTestJSON(t, input)
 			
 			// Assert
 			assertStringContains(t, result, "\n")
@@ -68,7 +70,7 @@ func TestParser(t *testing.T) {
 			input := `[1,2,3]`
 			
 			// Act
-			result := formatJSON(input, "  ")
+			result := indentTestJSON(t, input)
 			
 			// Assert
 			assertStringContains(t, result, "\n")
@@ -202,6 +204,48 @@ func TestParser(t *testing.T) {
 		})
 	})
 	
+	t.Run("EmbeddedJSON", func(t *testing.T) {
+		config := &UIConfig{PrettyPrintJSON: true, JSONIndent: "  "}
+
+		t.Run("DeeplyNestedAfterPrefix", func(t *testing.T) {
+			// Arrange - deeper than one level and longer than 1000 characters
+			input := `INFO 2026-01-02T03:04:05Z {"a":{"b":{"c":[1,{"d":"` + strings.Repeat("x", 1200) + `"}]}}} done`
+
+			// Act
+			result := formatLogMessage(input, config)
+
+			// Assert
+			assertStringContains(t, result, "INFO 2026-01-02T03:04:05Z {\n  \"a\": {")
+			assertStringContains(t, result, "\n} done")
+		})
+
+		t.Run("BracesInStrings", func(t *testing.T) {
+			spans := findEmbeddedJSON(`start {"text":"} not the end {"} {broken end`)
+			assertIntEqual(t, len(spans), 1, "one object")
+			assertIntEqual(t, spans[0][1], len(`start {"text":"} not the end {"}`), "object end")
+		})
+
+		t.Run("JSONInStrings", func(t *testing.T) {
+			// Arrange - message holds escaped JSON, which itself holds escaped JSON
+			input := `{"level":"info","message":"{\"user\":\"bob\",\"body\":\"{\\\"id\\\":7}\"}"}`
+
+			// Act
+			entry := makeLogEntry(time.Now(), input, config)
+
+			// Assert
+			value, ok := fieldPath(entry.Fields, "message.body.id")
+			assertBoolEqual(t, ok, true, "nested field decoded")
+			assertStringEqual(t, formatFieldValue(value), "7")
+			assertStringContains(t, entry.Message, `"user": "bob"`)
+		})
+
+		t.Run("PlainStringsKept", func(t *testing.T) {
+			entry := makeLogEntry(time.Now(), `{"msg":"[not json","list":"[1,2]"}`, config)
+			assertStringEqual(t, entry.Fields["msg"].(string), "[not json")
+			assertIntEqual(t, len(entry.Fields["list"].([]interface{})), 2, "array decoded")
+		})
+	})
+
 	t.Run("LogEntryCreation", func(t *testing.T) {
 		t.Run("BasicEntry", func(t *testing.T) {
 			// Arrange
@@ -246,12 +290,12 @@ func TestParser(t *testing.T) {
 }
 
 // Benchmark tests for performance-critical parsing operations
-func BenchmarkParser_IsJSON(b *testing.B) {
+func BenchmarkParser_ParseJSONTree(b *testing.B) {
 	input := `{"key":"value","nested":{"array":[1,2,3]}}`
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		isJSON(input)
+		parseJSONTree(input)
 	}
 }
 
@@ -260,7 +304,8 @@ func BenchmarkParser_FormatJSON(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		formatJSON(input, "  ")
+		root, _ := parseJSONTree(input)
+		indentJSON(root, "  ", nil)
 	}
 }
 
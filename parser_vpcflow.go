@@ -0,0 +1,353 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+VPC Flow Logs
+
+Space-separated flow records in the default (version 2) format:
+
+	version account-id interface-id srcaddr dstaddr srcport dstport protocol
+	packets bytes start end action log-status
+
+Custom formats may pick any v2-v5 fields in any order. The order comes from
+the "flowLogFormat" parser setting (the log format as written in AWS, e.g.
+"${srcaddr} ${dstaddr} ${action}"), which profiles can set per log group,
+or from a header line naming the fields without "${}". Records that do not
+match the configured order are tried against the default one.
+
+A parser instance is shared by log groups, so it never learns from headers.
+The viewer remembers the last header of each log stream instead and re-parses
+the records that arrive after it in that order (followFlowLogHeader). The
+order is kept on the entry, so reformatting gives the same fields.
+
+Rendered as "src → dst:port PROTO bytes ACTION" with ACCEPT green and REJECT
+red, followed by the interface and flow direction. Fields are exposed with
+underscores instead of dashes (interface_id, log_status...).
+*/
+
+const flowLogParserName = "vpcFlow"
+
+// flowLogDefaultFields is the default version 2 record format
+var flowLogDefaultFields = []string{
+	"version", "account-id", "interface-id", "srcaddr", "dstaddr", "srcport", "dstport",
+	"protocol", "packets", "bytes", "start", "end", "action", "log-status",
+}
+
+// flowLogKnownFields lists every v2-v5 field; true marks numeric fields
+var flowLogKnownFields = map[string]bool{
+	// v2
+	"version": true, "account-id": false, "interface-id": false, "srcaddr": false, "dstaddr": false,
+	"srcport": true, "dstport": true, "protocol": true, "packets": true, "bytes": true,
+	"start": true, "end": true, "action": false, "log-status": false,
+	// v3
+	"vpc-id": false, "subnet-id": false, "instance-id": false, "tcp-flags": true, "type": false,
+	"pkt-srcaddr": false, "pkt-dstaddr": false,
+	// v4
+	"region": false, "az-id": false, "sublocation-type": false, "sublocation-id": false,
+	// v5
+	"pkt-src-aws-service": false, "pkt-dst-aws-service": false, "flow-direction": false, "traffic-path": true,
+}
+
+// ipProtocolNames maps IANA protocol numbers to names
+var ipProtocolNames = map[string]string{
+	"1": "ICMP", "6": "TCP", "17": "UDP", "47": "GRE", "50": "ESP", "51": "AH", "58": "ICMPv6", "132": "SCTP",
+}
+
+// flowLogParser handles VPC Flow Logs records in a configured field order
+type flowLogParser struct {
+	fields []string
+}
+
+// newFlowLogParser returns a parser for the given field order (nil for the default)
+func newFlowLogParser(fields []string) *flowLogParser {
+	if len(fields) == 0 {
+		fields = flowLogDefaultFields
+	}
+	return &flowLogParser{fields: fields}
+}
+
+// parseFlowLogFormat reads a field order such as "${version} ${srcaddr} ..."
+func parseFlowLogFormat(format string) ([]string, error) {
+	var fields []string
+	for _, token := range strings.Fields(format) {
+		name := strings.TrimSuffix(strings.TrimPrefix(token, "${"), "}")
+		if _, ok := flowLogKnownFields[name]; !ok {
+			return nil, fmt.Errorf("unknown flow log field %q", name)
+		}
+		fields = append(fields, name)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty flow log format")
+	}
+	return fields, nil
+}
+
+func (p *flowLogParser) Name() string { return flowLogParserName }
+
+// Detect accepts header lines and records whose shape matches a known order
+func (p *flowLogParser) Detect(message string) bool {
+	if strings.HasPrefix(message, "{") || strings.HasPrefix(message, "[") || !strings.Contains(message, " ") {
+		return false
+	}
+	if isFlowLogHeader(message) {
+		return true
+	}
+	values := strings.Fields(message)
+	return flowLogMatches(p.fields, values) || flowLogMatches(flowLogDefaultFields, values)
+}
+
+func (p *flowLogParser) Parse(message string) (*parsedLog, bool) {
+	values := strings.Fields(message)
+
+	if isFlowLogHeader(message) {
+		return &parsedLog{Fields: map[string]interface{}{"header": true}, Value: values}, true
+	}
+
+	order := p.fields
+	if !flowLogMatches(order, values) {
+		if !flowLogMatches(flowLogDefaultFields, values) {
+			return nil, false
+		}
+		order = flowLogDefaultFields
+	}
+
+	fields := make(map[string]interface{}, len(values))
+	for i, name := range order {
+		key := strings.ReplaceAll(name, "-", "_")
+		value := values[i]
+		if flowLogKnownFields[name] && value != "-" {
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				fields[key] = n
+				continue
+			}
+		}
+		fields[key] = value
+	}
+	if number, ok := flowLogValue(order, values, "protocol"); ok {
+		fields["protocol_name"] = flowLogProtocolName(number)
+	}
+	return &parsedLog{Fields: fields, Value: flowLogRecord(order, values)}, true
+}
+
+// flowLogRecord indexes the raw values by field name for rendering
+func flowLogRecord(order, values []string) map[string]string {
+	record := make(map[string]string, len(order))
+	for i, name := range order {
+		record[name] = values[i]
+	}
+	return record
+}
+
+func (p *flowLogParser) Render(parsed *parsedLog, config *UIConfig) string {
+	record, ok := parsed.Value.(map[string]string)
+	if !ok {
+		// Header lines are shown dimmed
+		header := "flow log fields: " + strings.Join(parsed.Value.([]string), " ")
+		if config.ColorizeFields {
+			header = config.FieldStyle(config.Colors.SizeColor).Render(header)
+		}
+		return header
+	}
+
+	get := func(name string) string {
+		if value, ok := record[name]; ok && value != "" {
+			return value
+		}
+		return "-"
+	}
+
+	// NODATA and SKIPDATA records have no addresses
+	status := get("log-status")
+	if get("srcaddr") == "-" && get("dstaddr") == "-" && status != "-" && status != "OK" {
+		return formatAccessLogDetails(status, config, get("interface-id"))
+	}
+
+	protocol := get("protocol")
+	protocolName := flowLogProtocolName(protocol)
+	destination := get("dstaddr")
+	if port := get("dstport"); port != "-" && protocolName != "ICMP" && protocolName != "ICMPv6" {
+		destination = net.JoinHostPort(destination, port)
+	}
+
+	source := get("srcaddr")
+	size := formatByteCount(get("bytes"))
+	action := get("action")
+
+	if config.ColorizeFields {
+		colors := config.Colors
+		ipStyle := config.FieldStyle(colors.IPColor)
+		source = ipStyle.Render(source)
+		destination = ipStyle.Render(destination)
+		protocolName = config.FieldStyle(colors.FieldColor).Render(protocolName)
+		size = config.FieldStyle(colors.SizeColor).Render(size)
+		action = flowLogActionStyle(action, config)
+	}
+
+	line := fmt.Sprintf("%s → %s %s %s %s", source, destination, protocolName, size, action)
+	return formatAccessLogDetails(line, config, get("interface-id"), get("flow-direction"))
+}
+
+// flowLogActionStyle colors ACCEPT green and REJECT red
+func flowLogActionStyle(action string, config *UIConfig) string {
+	colors := config.Colors
+	switch action {
+	case "ACCEPT":
+		return config.FieldStyle(colors.Status2xxColor).Bold(true).Render(action)
+	case "REJECT":
+		return config.FieldStyle(colors.Status5xxColor).Bold(true).Render(action)
+	}
+	return config.FieldStyle(colors.FieldColor).Render(action)
+}
+
+// followFlowLogHeader remembers the field order of a header line for its
+// stream, or re-parses a record of a stream whose header was seen
+func (m *logModel) followFlowLogHeader(entry *logEntry) {
+	if entry.Format == flowLogParserName && entry.Fields["header"] == true {
+		if m.flowLogOrders == nil {
+			m.flowLogOrders = make(map[string][]string)
+		}
+		m.flowLogOrders[entry.Stream] = strings.Fields(entry.OriginalMessage)
+		return
+	}
+	if order := m.flowLogOrders[entry.Stream]; order != nil {
+		*entry = withFlowLogOrder(*entry, order, m.config)
+	}
+}
+
+// withFlowLogOrder parses a record in the field order of a header. Entries
+// another parser claimed and records of a different shape are kept as they are.
+func withFlowLogOrder(entry logEntry, order []string, cfg *UIConfig) logEntry {
+	if entry.Format != "" && entry.Format != flowLogParserName || !parserEnabled(flowLogParserName, cfg) {
+		return entry
+	}
+	message := strings.TrimSpace(cfg.redact(entry.OriginalMessage))
+	if isFlowLogHeader(message) || !flowLogMatches(order, strings.Fields(message)) {
+		return entry
+	}
+	parser := newFlowLogParser(order)
+	parsed, ok := parser.Parse(message)
+	if !ok {
+		return entry
+	}
+	parsed.Format, parsed.parser = flowLogParserName, parser
+
+	entry.Format, entry.Fields = flowLogParserName, parsed.Fields
+	entry.Message = renderLogMessage(message, parsed, cfg)
+	entry.Level = detectLevel(entry.OriginalMessage, entry.Fields)
+	entry.flowOrder = order
+	entry.restamp(cfg, time.Now())
+	return entry
+}
+
+// isFlowLogHeader reports whether every word of a line is a flow log field name,
+// including an address, interface or action
+func isFlowLogHeader(line string) bool {
+	words := strings.Fields(line)
+	if len(words) < 3 {
+		return false
+	}
+	identified := false
+	for _, word := range words {
+		if _, ok := flowLogKnownFields[word]; !ok {
+			return false
+		}
+		switch word {
+		case "srcaddr", "dstaddr", "interface-id", "action":
+			identified = true
+		}
+	}
+	return identified
+}
+
+// flowLogMatches reports whether values look like a record in the given order.
+// Every value must fit its field, and the record must carry an address or action.
+func flowLogMatches(order, values []string) bool {
+	if len(order) != len(values) {
+		return false
+	}
+	identified := false
+	for i, name := range order {
+		value := values[i]
+		if value == "-" {
+			continue
+		}
+		switch {
+		case flowLogKnownFields[name]:
+			if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+				return false
+			}
+		case name == "srcaddr" || name == "dstaddr" || name == "pkt-srcaddr" || name == "pkt-dstaddr":
+			if net.ParseIP(value) == nil {
+				return false
+			}
+			identified = true
+		case name == "action":
+			if value != "ACCEPT" && value != "REJECT" {
+				return false
+			}
+			identified = true
+		case name == "log-status":
+			if value != "OK" && value != "NODATA" && value != "SKIPDATA" {
+				return false
+			}
+			identified = true
+		case name == "interface-id":
+			if !strings.HasPrefix(value, "eni-") {
+				return false
+			}
+		}
+	}
+	return identified
+}
+
+// flowLogValue returns the raw value of a named field
+func flowLogValue(order, values []string, name string) (string, bool) {
+	for i, field := range order {
+		if field == name && values[i] != "-" {
+			return values[i], true
+		}
+	}
+	return "", false
+}
+
+// flowLogProtocolName names an IANA protocol number
+func flowLogProtocolName(number string) string {
+	if name, ok := ipProtocolNames[number]; ok {
+		return name
+	}
+	if number == "-" {
+		return number
+	}
+	return "proto " + number
+}
+
+// formatByteCount renders a byte count as "512 B", "4.1 KB", "2.0 MB"
+func formatByteCount(value string) string {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	unit := 0
+	for n >= 1024 && unit < len(units)-1 {
+		n /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	return fmt.Sprintf("%.1f %s", n, units[unit])
+}
+
+// flowLogRejected reports whether an entry is a rejected flow record
+func flowLogRejected(entry *logEntry) bool {
+	action, _ := entry.Fields["action"].(string)
+	return entry.Format == flowLogParserName && action == "REJECT"
+}
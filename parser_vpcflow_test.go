@@ -0,0 +1,150 @@
+package main
+
+import (
+	"testing"
+)
+
+const (
+	testFlowAccept = "2 123456789010 eni-1235b8ca123456789 172.31.16.139 172.31.16.21 20641 22 6 20 4249 1418530010 1418530070 ACCEPT OK"
+	testFlowReject = "2 123456789010 eni-1235b8ca123456789 172.31.9.69 172.31.9.12 49761 3389 6 20 4249 1418530010 1418530070 REJECT OK"
+)
+
+func TestFlowLogParser(t *testing.T) {
+	t.Run("DefaultFormat", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+
+		// Act
+		entry := makeLogEntry(testTimestamp, testFlowAccept, config)
+
+		// Assert
+		assertStringEqual(t, entry.Format, flowLogParserName)
+		assertStringEqual(t, entry.Fields["interface_id"].(string), "eni-1235b8ca123456789")
+		assertStringEqual(t, entry.Fields["protocol_name"].(string), "TCP")
+		if port, _ := entry.Fields["dstport"].(float64); port != 22 {
+			t.Errorf("dstport: got %v, want 22", entry.Fields["dstport"])
+		}
+		assertStringEqual(t, stripANSI(entry.Message),
+			"172.31.16.139 → 172.31.16.21:22 TCP 4.1 KB ACCEPT eni-1235b8ca123456789")
+	})
+
+	t.Run("NoData", func(t *testing.T) {
+		line := "2 123456789010 eni-1235b8ca123456789 - - - - - - - 1431280876 1431280934 - NODATA"
+		entry := makeLogEntry(testTimestamp, line, createTestConfig())
+		assertStringEqual(t, stripANSI(entry.Message), "NODATA eni-1235b8ca123456789")
+	})
+
+	t.Run("ConfiguredFormat", func(t *testing.T) {
+		// Arrange - v5 fields in a custom order, IPv6 addresses
+		config := createTestConfig()
+		config.setParserSettings(ParserSettings{
+			FlowLogFormat: "${flow-direction} ${srcaddr} ${dstaddr} ${dstport} ${protocol} ${bytes} ${action}",
+		})
+
+		// Act
+		entry := makeLogEntry(testTimestamp, "ingress 2001:db8::1 2001:db8::2 443 17 512 REJECT", config)
+
+		// Assert
+		assertStringEqual(t, entry.Format, flowLogParserName)
+		assertStringEqual(t, stripANSI(entry.Message), "2001:db8::1 → [2001:db8::2]:443 UDP 512 B REJECT ingress")
+
+		// Default records still parse
+		assertStringEqual(t, makeLogEntry(testTimestamp, testFlowAccept, config).Format, flowLogParserName)
+	})
+
+	t.Run("HeaderLine", func(t *testing.T) {
+		config := createTestConfig()
+
+		header := makeLogEntry(testTimestamp, "srcaddr dstaddr protocol action", config)
+		assertStringEqual(t, header.Format, flowLogParserName)
+		assertStringEqual(t, stripANSI(header.Message), "flow log fields: srcaddr dstaddr protocol action")
+
+		// The shared parser does not learn the header's order
+		entry := makeLogEntry(testTimestamp, "10.0.0.1 10.0.0.2 1 ACCEPT", config)
+		if entry.Format == flowLogParserName {
+			t.Errorf("record parsed in the header's order: %q", entry.Message)
+		}
+		assertStringEqual(t, makeLogEntry(testTimestamp, testFlowAccept, createTestConfig()).Format, flowLogParserName)
+	})
+
+	t.Run("HeaderOrderPerStream", func(t *testing.T) {
+		// Arrange - a custom header on stream a only
+		model := createTestLogModel("vpc-flow-logs")
+		entry := func(stream, message string) logEntry {
+			e := makeLogEntry(testTimestamp, message, model.config)
+			e.Stream = stream
+			return e
+		}
+		record := "eni-1 10.0.0.9 10.0.0.1 443 6 REJECT"
+
+		// Act
+		model.Update(logsWithTokenMsg{logs: []logEntry{
+			entry("b", record),
+			entry("a", "interface-id srcaddr dstaddr dstport protocol action"),
+			entry("a", record),
+			entry("b", record),
+		}})
+
+		// Assert - only records after the header of their stream take its order
+		logs := model.safeLogs()
+		assertStringEqual(t, logs[2].Format, flowLogParserName)
+		assertStringEqual(t, logs[2].Fields["srcaddr"].(string), "10.0.0.9")
+		assertStringEqual(t, logs[2].Fields["action"].(string), "REJECT")
+		assertStringEqual(t, stripANSI(logs[2].Message), "10.0.0.9 → 10.0.0.1:443 TCP - REJECT eni-1")
+		assertBoolEqual(t, flowLogRejected(&logs[2]), true, "rejected")
+		for _, i := range []int{0, 3} {
+			if logs[i].Format == flowLogParserName {
+				t.Errorf("record %d of stream b parsed as a flow log: %q", i, logs[i].Message)
+			}
+		}
+
+		// Act - reformatting keeps the header's order
+		reformatted := reformatEntry(logs[2], model.config)
+
+		// Assert
+		assertStringEqual(t, reformatted.Fields["dstaddr"].(string), "10.0.0.1")
+	})
+
+	t.Run("NotFlowLogs", func(t *testing.T) {
+		config := createTestConfig()
+		for _, line := range []string{"GET /health 200", "2 apples and 3 oranges", "start end type"} {
+			if entry := makeLogEntry(testTimestamp, line, config); entry.Format == flowLogParserName {
+				t.Errorf("%q parsed as a flow log", line)
+			}
+		}
+	})
+
+	t.Run("FormatSetting", func(t *testing.T) {
+		_, err := parseConfigFile([]byte(`{"settings":{"parsers":{"flowLogFormat":"${srcaddr} ${color}"}}}`))
+		assertError(t, err, "color")
+
+		file, err := parseConfigFile([]byte(`{"settings":{"parsers":{"flowLogFormat":"${srcaddr} ${dstaddr} ${action}"}}}`))
+		assertNoError(t, err)
+		config := NewUIConfig()
+		file.apply(config)
+		assertStringEqual(t, makeLogEntry(testTimestamp, "10.0.0.1 10.0.0.2 REJECT", config).Fields["action"].(string), "REJECT")
+	})
+}
+
+func TestRejectFilterInViewer(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("vpc-flow-logs")
+	for _, msg := range []string{testFlowAccept, testFlowReject, "unrelated", testFlowAccept} {
+		model.store.Append(makeLogEntry(testTimestamp, msg, model.config))
+	}
+	model.followMode = false
+	simulateWindowResize(model, 160, 40)
+
+	// Act
+	simulateKeyPress(model, "X")
+
+	// Assert
+	assertBoolEqual(t, model.rejectsOnly, true, "filter on")
+	rows := model.visibleRows(model.safeLogs())
+	assertSliceLength(t, rows, 1, "rejected rows")
+	assertIntEqual(t, model.cursor, 1, "cursor on the REJECT row")
+	assertStringContains(t, model.View(), "not REJECT hidden")
+
+	simulateKeyPress(model, "X")
+	assertSliceLength(t, model.visibleRows(model.safeLogs()), 4, "all rows")
+}
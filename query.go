@@ -0,0 +1,445 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+/*
+Field Queries
+
+A search or filter that starts with ":" is a query over the fields the parsers
+extract (JSON keys, access log and ALB fields, logfmt pairs, ...) instead of
+text:
+
+	:status>=500 and path~"/api/" and not user_agent~bot
+	:level>=warn and not message~healthcheck
+	:duration>1.5s and (method=POST or method=PUT)
+	:kubernetes.namespace_name=shop and !trace_id
+
+Expressions:
+
+	field                 the field exists
+	field = value         equal (case-insensitive); also ==, and != for not equal
+	field > value         also >=, < and <=: numbers, durations, levels or text
+	field ~ pattern       regular expression match (case-insensitive); !~ does not match
+	a and b, a or b       also && and ||; and binds tighter than or
+	not a                 also !a
+	( ... )               grouping
+
+Nested fields use dots, as in columns. Values are bare words or "quoted
+strings". A value like 250ms, 1.5s or 2m is a duration: durations in fields are
+read from text like "120ms", and plain numbers count as seconds, the unit AWS
+access logs use. level compares by severity (trace < debug < info < warn <
+error < fatal) and uses the detected level of every line. message, stream and
+format are available on every line. Comparisons with a missing field are
+false.
+*/
+
+// fieldQueryPrefix marks a search or filter as a field query
+const fieldQueryPrefix = ":"
+
+// isFieldQuery reports whether a prompt text is a field query
+func isFieldQuery(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), fieldQueryPrefix)
+}
+
+// queryNode is a node of a parsed field query
+type queryNode interface {
+	eval(entry *logEntry) bool
+}
+
+type queryAnd struct{ left, right queryNode }
+type queryOr struct{ left, right queryNode }
+type queryNot struct{ operand queryNode }
+
+// queryExists matches entries that have the field
+type queryExists struct{ field string }
+
+// queryCompare compares a field with a literal; the literal is converted once
+type queryCompare struct {
+	field, op, value string
+
+	number   float64
+	isNumber bool
+	duration time.Duration
+	isDur    bool
+	level    logLevel // levelNone when the value is no level name
+	re       *regexp.Regexp
+}
+
+func (q queryAnd) eval(e *logEntry) bool    { return q.left.eval(e) && q.right.eval(e) }
+func (q queryOr) eval(e *logEntry) bool     { return q.left.eval(e) || q.right.eval(e) }
+func (q queryNot) eval(e *logEntry) bool    { return !q.operand.eval(e) }
+func (q queryExists) eval(e *logEntry) bool { _, ok := queryField(e, q.field); return ok }
+
+func (q *queryCompare) eval(e *logEntry) bool {
+	if q.level != levelNone && strings.EqualFold(q.field, "level") {
+		return e.Level != levelNone && compareOrdered(q.op, int(e.Level), int(q.level))
+	}
+	value, ok := queryField(e, q.field)
+	if !ok {
+		return false
+	}
+	text := formatFieldValue(value)
+
+	switch q.op {
+	case "~":
+		return q.re.MatchString(text)
+	case "!~":
+		return !q.re.MatchString(text)
+	}
+	if q.isDur {
+		if d, ok := durationValue(value); ok {
+			return compareOrdered(q.op, d, q.duration)
+		}
+		return false
+	}
+	if q.isNumber {
+		if n, ok := numberValue(value); ok {
+			return compareOrdered(q.op, n, q.number)
+		}
+		if q.op != "=" && q.op != "!=" {
+			return false
+		}
+	}
+	switch q.op {
+	case "=":
+		return strings.EqualFold(text, q.value)
+	case "!=":
+		return !strings.EqualFold(text, q.value)
+	}
+	return compareOrdered(q.op, text, q.value)
+}
+
+// compareOrdered applies a comparison operator
+func compareOrdered[T int | float64 | time.Duration | string](op string, a, b T) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return false
+}
+
+// queryField looks up a field, falling back to the attributes every entry has
+func queryField(e *logEntry, path string) (interface{}, bool) {
+	if strings.EqualFold(path, "level") && e.Level != levelNone {
+		return e.Level.String(), true
+	}
+	if value, ok := fieldPath(e.Fields, path); ok {
+		return value, true
+	}
+	switch path {
+	case "message":
+		return stripANSI(e.Message), true
+	case "stream":
+		return e.Stream, e.Stream != ""
+	case "format":
+		return e.Format, e.Format != ""
+	}
+	return nil, false
+}
+
+// numberValue reads a field value as a number
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// durationValue reads a field value as a duration; plain numbers are seconds
+func durationValue(value interface{}) (time.Duration, bool) {
+	if n, ok := numberValue(value); ok {
+		return time.Duration(n * float64(time.Second)), true
+	}
+	if s, ok := value.(string); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		return d, err == nil
+	}
+	return 0, false
+}
+
+// fieldQuery is a parsed query
+type fieldQuery struct {
+	root queryNode
+	text string
+}
+
+// matches reports whether an entry satisfies the query
+func (q *fieldQuery) matches(entry *logEntry) bool {
+	return q.root.eval(entry)
+}
+
+// queryParser is a recursive-descent parser over the query text
+type queryParser struct {
+	text   string
+	pos    int
+	offset int // Columns before text in the prompt, so errors point at what was typed
+}
+
+// queryError is a parse error with the column it happened at
+type queryError struct {
+	column int
+	msg    string
+}
+
+func (e *queryError) Error() string { return fmt.Sprintf("col %d: %s", e.column, e.msg) }
+
+// parseFieldQuery parses a query; the ":" prefix is optional
+func parseFieldQuery(text string) (*fieldQuery, error) {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	body := strings.TrimRightFunc(strings.TrimPrefix(trimmed, fieldQueryPrefix), unicode.IsSpace)
+	p := &queryParser{text: body, offset: len(text) - len(strings.TrimPrefix(trimmed, fieldQueryPrefix))}
+	p.skipSpace()
+	if p.pos == len(p.text) {
+		return nil, p.errorf("empty query")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return nil, p.errorf("unexpected %q; expected and, or or the end", p.text[p.pos:p.pos+1])
+	}
+	return &fieldQuery{root: root, text: body}, nil
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return &queryError{column: p.offset + p.pos + 1, msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) skipSpace() {
+	for p.pos < len(p.text) && unicode.IsSpace(rune(p.text[p.pos])) {
+		p.pos++
+	}
+}
+
+// keyword consumes a word or symbol operator at the cursor
+func (p *queryParser) keyword(word, symbol string) bool {
+	p.skipSpace()
+	rest := p.text[p.pos:]
+	if symbol != "" && strings.HasPrefix(rest, symbol) {
+		p.pos += len(symbol)
+		return true
+	}
+	if len(rest) >= len(word) && strings.EqualFold(rest[:len(word)], word) &&
+		(len(rest) == len(word) || !isFieldChar(rest[len(word)])) {
+		p.pos += len(word)
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or", "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and", "&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	p.skipSpace()
+	if strings.HasPrefix(p.text[p.pos:], "!") {
+		p.pos++
+	} else if !p.keyword("not", "") {
+		return p.parsePrimary()
+	}
+	operand, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return queryNot{operand}, nil
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	p.skipSpace()
+	if p.pos == len(p.text) {
+		return nil, p.errorf("expected a field")
+	}
+	if p.text[p.pos] == '(' {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos == len(p.text) || p.text[p.pos] != ')' {
+			return nil, p.errorf("missing closing )")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && isFieldChar(p.text[p.pos]) {
+		p.pos++
+	}
+	field := p.text[start:p.pos]
+	if field == "" {
+		return nil, p.errorf("expected a field, got %q", p.text[p.pos:p.pos+1])
+	}
+
+	p.skipSpace()
+	op := p.operator()
+	if op == "" {
+		return queryExists{field}, nil
+	}
+	opColumn := p.pos
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	return newQueryCompare(field, op, value, p.offset+opColumn)
+}
+
+// operator consumes a comparison operator, longest first
+func (p *queryParser) operator() string {
+	for _, op := range []string{"==", "!=", ">=", "<=", "!~", "=", ">", "<", "~"} {
+		if strings.HasPrefix(p.text[p.pos:], op) {
+			p.pos += len(op)
+			if op == "==" {
+				return "="
+			}
+			return op
+		}
+	}
+	return ""
+}
+
+// value consumes a quoted string or a bare word
+func (p *queryParser) value() (string, error) {
+	p.skipSpace()
+	if p.pos == len(p.text) {
+		return "", p.errorf("expected a value")
+	}
+	if p.text[p.pos] == '"' {
+		start := p.pos
+		for i := p.pos + 1; i < len(p.text); i++ {
+			switch p.text[i] {
+			case '\\':
+				i++
+			case '"':
+				value, err := strconv.Unquote(p.text[start : i+1])
+				if err != nil {
+					return "", p.errorf("invalid string: %v", err)
+				}
+				p.pos = i + 1
+				return value, nil
+			}
+		}
+		return "", p.errorf("missing closing quote")
+	}
+	start := p.pos
+	for p.pos < len(p.text) && !unicode.IsSpace(rune(p.text[p.pos])) && p.text[p.pos] != ')' {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a value")
+	}
+	return p.text[start:p.pos], nil
+}
+
+// newQueryCompare converts the literal of a comparison once
+func newQueryCompare(field, op, value string, column int) (queryNode, error) {
+	q := &queryCompare{field: field, op: op, value: value}
+	switch op {
+	case "~", "!~":
+		if _, err := regexp.Compile(value); err != nil { // Reported without the case flag
+			return nil, &queryError{column: column + 1, msg: "invalid pattern: " + searchErrorText(err)}
+		}
+		q.re = regexp.MustCompile("(?i)" + value)
+		return q, nil
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		q.number, q.isNumber = n, true
+	} else if d, err := time.ParseDuration(value); err == nil {
+		q.duration, q.isDur = d, true
+	}
+	if strings.EqualFold(field, "level") {
+		q.level = parseLevelName(value)
+	}
+	return q, nil
+}
+
+// isFieldChar reports whether c can appear in a field path
+func isFieldChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c == '@' || c == '$' ||
+		c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// highlightPattern matches the literal values of the query, so matching lines
+// show where the query looked; the pattern matches nothing when there are none
+func (q *fieldQuery) highlightPattern() *regexp.Regexp {
+	var parts []string
+	var walk func(node queryNode, negated bool)
+	walk = func(node queryNode, negated bool) {
+		switch n := node.(type) {
+		case queryAnd:
+			walk(n.left, negated)
+			walk(n.right, negated)
+		case queryOr:
+			walk(n.left, negated)
+			walk(n.right, negated)
+		case queryNot:
+			walk(n.operand, !negated)
+		case *queryCompare:
+			if negated || n.value == "" {
+				return
+			}
+			switch n.op {
+			case "~":
+				parts = append(parts, n.value)
+			case "=":
+				parts = append(parts, regexp.QuoteMeta(n.value))
+			}
+		}
+	}
+	walk(q.root, false)
+	if len(parts) == 0 {
+		return regexp.MustCompile(`\b\B`)
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(parts, "|"))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseFieldQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{":", "col 2: empty query"},
+		{":status>=", "col 10: expected a value"},
+		{":status=500 and", "col 16: expected a field"},
+		{":(status=500", "col 13: missing closing )"},
+		{`:path="/api`, "col 7: missing closing quote"},
+		{":path~(", "col 7: invalid pattern: missing closing ): `(`"},
+		{":status=500 path=/", `col 13: unexpected "p"`},
+		{":=500", `col 2: expected a field, got "="`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := parseFieldQuery(tt.query)
+			assertError(t, err, tt.want)
+		})
+	}
+}
+
+func TestFieldQueryMatches(t *testing.T) {
+	config := createTestConfig()
+	access := makeLogEntry(testTimestamp, testAccessLogLine, config)
+	alb := makeLogEntry(testTimestamp, testALBLine, config)
+	logfmt := makeLogEntry(testTimestamp, `level=warn msg="slow request" duration=120ms user=bob`, config)
+	json := makeLogEntry(testTimestamp, `{"level":"error","http":{"status":503},"trace_id":"abc"}`, config)
+	plain := makeLogEntry(testTimestamp, "just some text", config)
+
+	tests := []struct {
+		name  string
+		query string
+		entry logEntry
+		want  bool
+	}{
+		{"number", ":status>=200", access, true},
+		{"number fails", ":status>=500", access, false},
+		{"equal", ":method=get", access, true},
+		{"not equal", ":method!=GET", access, false},
+		{"regex", `:path~"^/api/"`, access, true},
+		{"negated regex", ":user_agent!~curl", access, false},
+		{"not", ":not user_agent~bot", access, true},
+		{"bang", ":!user_agent~curl", access, false},
+		{"seconds as duration", ":response_time<1ms", alb, true},
+		{"duration text", ":duration>100ms and duration<1s", logfmt, true},
+		{"duration text fails", ":duration>=1s", logfmt, false},
+		{"level severity", ":level>=warn", logfmt, true},
+		{"level severity fails", ":level>=error", logfmt, false},
+		{"nested field", ":http.status>500", json, true},
+		{"exists", ":trace_id", json, true},
+		{"not exists", ":!trace_id", access, true},
+		{"missing field", ":status>0", plain, false},
+		{"message", ":message~some", plain, true},
+		{"and binds tighter", ":method=POST and status=200 or trace_id", json, true},
+		{"parentheses", ":method=POST and (status=200 or trace_id)", access, false},
+		{"or", ":method=POST || method=GET", access, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			q, err := parseFieldQuery(tt.query)
+			assertNoError(t, err)
+
+			// Act & Assert
+			assertBoolEqual(t, q.matches(&tt.entry), tt.want, tt.query)
+		})
+	}
+}
+
+func TestFieldQueryPrompts(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	messages := []string{
+		`{"level":"info","status":200,"path":"/ok"}`,
+		`{"level":"error","status":502,"path":"/api/pay"}`,
+		`{"level":"error","status":500,"path":"/health"}`,
+		"plain text mentioning status 500",
+	}
+	for _, message := range messages {
+		model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+	}
+	simulateWindowResize(model, 120, 30)
+	typeInto := func(open, query string) {
+		simulateKeyPress(model, open)
+		for _, r := range query {
+			simulateKeyPress(model, string(r))
+		}
+	}
+
+	// Act - a broken query keeps the prompt open with the error
+	typeInto("/", ":status>=")
+
+	// Assert
+	assertStringContains(t, stripANSI(model.View()), "Search [field query]")
+	assertStringContains(t, stripANSI(model.View()), "col 10: expected a value")
+
+	// Act
+	typeInto("", "500 and path~api")
+	simulateKeyPress(model, "enter")
+
+	// Assert - only the parsed field matches, not the plain text
+	assertIntEqual(t, len(model.matches), 1, "search matches")
+	assertStringContains(t, stripANSI(model.View()), "1/1")
+
+	// Act - the same query as a filter
+	typeInto("&", ":status>=500")
+	simulateKeyPress(model, "enter")
+
+	// Assert
+	assertIntEqual(t, len(model.visibleRows(model.safeLogs())), 2, "filtered rows")
+	assertStringContains(t, stripANSI(model.View()), "showing 2 of 4")
+}
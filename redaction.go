@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+/*
+Redaction
+
+Secrets and personal data are masked before a message is parsed, rendered,
+searched or copied, so they never reach the screen of a shared session:
+
+	{"user":"bob@example.com","password":"hunter2"}
+	→ {"user":"[REDACTED]","password":"[REDACTED]"}
+
+Three kinds of rules apply, in this order:
+
+  - keys: the values of these JSON and logfmt keys (case-insensitive), also
+    inside JSON-in-string fields such as a container envelope's "log" line
+  - detectors: built-in recognizers for AWS access key IDs (awsKey), bearer
+    and JWT tokens (token), email addresses (email) and card-like numbers
+    that pass the Luhn check (card)
+  - patterns: regular expressions from the config file; the first group is
+    masked, or the whole match when the pattern has no group
+
+	{
+	  "settings": {
+	    "redaction": {
+	      "keys": ["ssn"],
+	      "patterns": ["session=(\\w+)"],
+	      "detectors": { "email": false }
+	    }
+	  }
+	}
+
+Redaction is on by default ("enabled": false turns it off). U reveals the
+original values in this session only; the header shows whether values are
+masked or revealed.
+*/
+
+// redactedText replaces every masked value
+const redactedText = "[REDACTED]"
+
+// Built-in detector names
+const (
+	detectorAWSKey = "awsKey"
+	detectorToken  = "token"
+	detectorEmail  = "email"
+	detectorCard   = "card"
+)
+
+// redactionDetector is a built-in recognizer of one kind of secret
+type redactionDetector struct {
+	name  string
+	re    *regexp.Regexp    // The first group is masked when it matched, else the whole match
+	valid func(string) bool // Rejects false positives; nil accepts every match
+}
+
+// redactionDetectors lists the built-in detectors in the order they run
+var redactionDetectors = []redactionDetector{
+	{name: detectorAWSKey, re: regexp.MustCompile(`\b(?:AKIA|ASIA|AIDA|AROA)[A-Z0-9]{16}\b`)},
+	{name: detectorToken, re: regexp.MustCompile(`(?i:\bbearer\s+([A-Za-z0-9\-._~+/]+=*))|\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{name: detectorEmail, re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	{name: detectorCard, re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: cardNumber},
+}
+
+// defaultRedactedKeys are always masked; the "keys" setting adds to them
+var defaultRedactedKeys = []string{
+	"password", "passwd", "secret", "client_secret", "clientSecret",
+	"token", "access_token", "accessToken", "refresh_token", "refreshToken",
+	"api_key", "apiKey", "authorization", "cookie",
+	"aws_secret_access_key", "secretAccessKey",
+}
+
+// RedactionSettings configures what is masked
+type RedactionSettings struct {
+	Enabled   *bool           `json:"enabled,omitempty"`   // Default true
+	Keys      []string        `json:"keys,omitempty"`      // JSON and logfmt keys whose values are masked, added to the defaults
+	Patterns  []string        `json:"patterns,omitempty"`  // Regular expressions; the first group or the whole match is masked
+	Detectors map[string]bool `json:"detectors,omitempty"` // false disables a built-in detector
+}
+
+// redactor is compiled from RedactionSettings
+type redactor struct {
+	enabled    bool
+	jsonKeys   *regexp.Regexp // `"key":` with the backslashes of the enclosing strings
+	logfmtKeys *regexp.Regexp // key= at the start of a logfmt pair
+	detectors  []redactionDetector
+	patterns   []*regexp.Regexp
+}
+
+// detectorNames lists the built-in detectors
+func detectorNames() []string {
+	names := make([]string, len(redactionDetectors))
+	for i, d := range redactionDetectors {
+		names[i] = d.name
+	}
+	return names
+}
+
+// validate rejects unknown detectors, empty keys and broken patterns
+func (s *RedactionSettings) validate() error {
+	for _, key := range s.Keys {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("redaction: empty key")
+		}
+	}
+	for _, pattern := range s.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("redaction: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	names := make([]string, 0, len(s.Detectors))
+	for name := range s.Detectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !containsString(detectorNames(), name) {
+			return fmt.Errorf("redaction: unknown detector %q (known: %s)", name, strings.Join(detectorNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// merge returns s with other applied on top: a set enabled flag replaces, keys and patterns add up,
+// detector flags merge
+func (s RedactionSettings) merge(other RedactionSettings) RedactionSettings {
+	result := RedactionSettings{
+		Enabled:   s.Enabled,
+		Keys:      append(append([]string(nil), s.Keys...), other.Keys...),
+		Patterns:  append(append([]string(nil), s.Patterns...), other.Patterns...),
+		Detectors: make(map[string]bool, len(s.Detectors)+len(other.Detectors)),
+	}
+	if other.Enabled != nil {
+		result.Enabled = other.Enabled
+	}
+	for name, enabled := range s.Detectors {
+		result.Detectors[name] = enabled
+	}
+	for name, enabled := range other.Detectors {
+		result.Detectors[name] = enabled
+	}
+	return result
+}
+
+// newRedactor compiles the settings; they must have passed validate
+func newRedactor(settings RedactionSettings) *redactor {
+	r := &redactor{enabled: settings.Enabled == nil || *settings.Enabled}
+
+	keys := make([]string, 0, len(defaultRedactedKeys)+len(settings.Keys))
+	for _, key := range append(append([]string(nil), defaultRedactedKeys...), settings.Keys...) {
+		keys = append(keys, regexp.QuoteMeta(strings.TrimSpace(key)))
+	}
+	alternatives := strings.Join(keys, "|")
+	r.jsonKeys = regexp.MustCompile(`(\\*)"(?i:` + alternatives + `)\\*"\s*:\s*`)
+	r.logfmtKeys = regexp.MustCompile(`(?:^|[\s,;?&])(?i:` + alternatives + `)=`)
+
+	for _, d := range redactionDetectors {
+		if enabled, ok := settings.Detectors[d.name]; !ok || enabled {
+			r.detectors = append(r.detectors, d)
+		}
+	}
+	for _, pattern := range settings.Patterns {
+		if re, err := regexp.Compile(pattern); err == nil { // Checked by validate
+			r.patterns = append(r.patterns, re)
+		}
+	}
+	return r
+}
+
+// defaultRedactor is used by configs that never had redaction settings applied
+var defaultRedactor = newRedactor(RedactionSettings{})
+
+// redaction returns the compiled redaction settings
+func (c *UIConfig) redaction() *redactor {
+	if c.redactor == nil {
+		return defaultRedactor
+	}
+	return c.redactor
+}
+
+// setRedaction replaces the redaction settings and recompiles them
+func (c *UIConfig) setRedaction(settings RedactionSettings) {
+	c.Redaction = settings
+	c.redactor = newRedactor(settings)
+}
+
+// redacting reports whether values are currently masked
+func (c *UIConfig) redacting() bool {
+	return c.redaction().enabled && !c.RevealSecrets
+}
+
+// redact masks the secrets of text unless redaction is off or revealed
+func (c *UIConfig) redact(text string) string {
+	if !c.redacting() {
+		return text
+	}
+	return c.redaction().apply(text)
+}
+
+// apply masks every key value, detector match and pattern match of text
+func (r *redactor) apply(text string) string {
+	text = maskKeyValues(text, r.jsonKeys, true)
+	text = maskKeyValues(text, r.logfmtKeys, false)
+	for _, d := range r.detectors {
+		text = maskMatches(text, d.re, d.valid)
+	}
+	for _, re := range r.patterns {
+		text = maskMatches(text, re, nil)
+	}
+	return text
+}
+
+// maskMatches replaces the first group of every match, or the whole match without one
+func maskMatches(text string, re *regexp.Regexp, valid func(string) bool) string {
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) > 2 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		if start == end || (valid != nil && !valid(text[start:end])) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(redactedText)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// maskKeyValues masks the value after every match of keys. In JSON the
+// matched key's leading backslashes tell how deeply the object is nested in
+// strings, so the value ends at the quote escaped the same way.
+func maskKeyValues(text string, keys *regexp.Regexp, json bool) string {
+	matches := keys.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m[0] < last {
+			continue // Inside a value masked already
+		}
+		escapes := 0
+		if json {
+			escapes = m[3] - m[2]
+		}
+		start, end, quoted := keyValueSpan(text, m[1], escapes, json)
+		if start == end {
+			continue
+		}
+		b.WriteString(text[last:start])
+		if json && !quoted {
+			quote := strings.Repeat(`\`, escapes) + `"`
+			b.WriteString(quote + redactedText + quote)
+		} else {
+			b.WriteString(redactedText)
+		}
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// keyValueSpan finds the value starting at pos: the contents of a string
+// quoted with escapes backslashes, or a bare word up to the next JSON
+// delimiter or, in logfmt, the next space
+func keyValueSpan(text string, pos, escapes int, json bool) (start, end int, quoted bool) {
+	opening := strings.Repeat(`\`, escapes) + `"`
+	if !strings.HasPrefix(text[pos:], opening) {
+		stop := " \t\r\n"
+		if json {
+			stop += `,}]\"`
+		}
+		end = pos
+		for end < len(text) && !strings.ContainsRune(stop, rune(text[end])) {
+			end++
+		}
+		return pos, end, false
+	}
+
+	// A quote closes the string when the backslashes before it, taken as
+	// nested escapes, leave exactly the ones of the opening quote
+	start = pos + len(opening)
+	period, want := 2<<escapes, (1<<escapes)-1
+	run := 0
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			run++
+			continue
+		case '"':
+			if run%period == want {
+				return start, i - escapes, true
+			}
+		}
+		run = 0
+	}
+	return start, len(text), true
+}
+
+// cardNumber reports whether digits look like a payment card number: 13 to 19
+// digits starting with 3 to 6 that pass the Luhn check
+func cardNumber(match string) bool {
+	var digits []int
+	for _, r := range match {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 || digits[0] < 3 || digits[0] > 6 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// toggleReveal shows or masks the redacted values of the whole buffer
+func (m *logModel) toggleReveal() tea.Cmd {
+	if !m.config.redaction().enabled {
+		m.formatStatusMsg = "Redaction is disabled in the config file"
+		return clearFormatStatusCmd()
+	}
+	m.config.RevealSecrets = !m.config.RevealSecrets
+
+	// Every entry changes: the visible ones now, the rest as they come into
+	// view or are scanned by the search, which runs again on the new text
+	m.reprocessVisibleLogs()
+	m.needsLazyReprocess = true
+
+	if m.config.RevealSecrets {
+		m.formatStatusMsg = "Secrets revealed locally"
+	} else {
+		m.formatStatusMsg = "Secrets redacted"
+	}
+	return tea.Batch(clearFormatStatusCmd(), m.research())
+}
+
+// redactionIndicator is shown in the header while redaction is configured
+func (c *UIConfig) redactionIndicator() string {
+	switch {
+	case !c.redaction().enabled:
+		return ""
+	case c.RevealSecrets:
+		return c.WarningStyle().Render(" [secrets revealed]")
+	}
+	return c.MutedStyle().Render(" [redacted]")
+}
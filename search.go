@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+Search Modes
+
+The search prompt matches the query as literal text, ignoring case, unless a
+mode is toggled while typing:
+
+	ctrl+r  regex           the query is a Go regular expression
+	ctrl+t  case-sensitive  upper and lower case differ
+	ctrl+e  whole word      matches must start and end at word boundaries
+
+ctrl+e keeps ctrl+w free for deleting the last word, as in a shell. The modes
+stay set for later searches and are shown in the status bar next to
+the prompt and the match count. A query that starts with ":" matches parsed
+fields instead of text (see query.go). In regex mode the query is compiled on every
+keystroke, and an invalid pattern is reported inline instead of being run.
+Valid queries are searched while typing (see searchjob.go).
+*/
+
+// searchOptions are the modes of the search prompt
+type searchOptions struct {
+	regex         bool // The query is a regular expression rather than literal text
+	caseSensitive bool
+	wholeWord     bool
+}
+
+// compile builds the regular expression that matches the query
+func (o searchOptions) compile(query string) (*regexp.Regexp, error) {
+	pattern := query
+	if !o.regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if o.wholeWord {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if !o.caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// label describes the active modes: "literal", "regex, case-sensitive, whole word"
+func (o searchOptions) label() string {
+	parts := []string{"literal"}
+	if o.regex {
+		parts[0] = "regex"
+	}
+	if o.caseSensitive {
+		parts = append(parts, "case-sensitive")
+	}
+	if o.wholeWord {
+		parts = append(parts, "whole word")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// searchMatcher matches entries by display text or, for field queries, by fields
+type searchMatcher struct {
+	text   func(string) bool
+	fields *fieldQuery
+}
+
+// matches reports whether an entry matches the search
+func (s searchMatcher) matches(entry *logEntry) bool {
+	if s.fields != nil {
+		return s.fields.matches(entry)
+	}
+	return s.text(entry.plainText())
+}
+
+// compileSearch builds the matcher of a search query and the pattern that
+// highlights it: text search in the display line, or a field query (see query.go)
+func (m *logModel) compileSearch(query string) (searchMatcher, *regexp.Regexp, error) {
+	if isFieldQuery(query) {
+		q, err := parseFieldQuery(query)
+		if err != nil {
+			return searchMatcher{}, nil, err
+		}
+		return searchMatcher{fields: q}, q.highlightPattern(), nil
+	}
+	re, err := m.searchOptions.compile(query)
+	if err != nil {
+		return searchMatcher{}, nil, err
+	}
+	// Always search in the display text (what user sees) to ensure highlighting works
+	if m.searchOptions == (searchOptions{}) && isASCII(query) {
+		return searchMatcher{text: func(text string) bool { return containsFold(text, query) }}, re, nil
+	}
+	return searchMatcher{text: re.MatchString}, re, nil
+}
+
+// containsFold reports whether s contains the ASCII text substr, ignoring case.
+// It is the default search mode and much faster than a case-insensitive regexp.
+func containsFold(s, substr string) bool {
+	if substr == "" {
+		return true
+	}
+	lower, upper := asciiLower(substr[0]), asciiUpper(substr[0])
+	nextLower, nextUpper := -1, -1 // Next position of each case of the first byte, from i on
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if nextLower < i {
+			nextLower = indexByteFrom(s, lower, i)
+		}
+		if nextUpper < i {
+			nextUpper = indexByteFrom(s, upper, i)
+		}
+		i = min(nextLower, nextUpper)
+		if i+len(substr) > len(s) {
+			return false
+		}
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexByteFrom returns the position of c in s at or after from, or len(s)
+func indexByteFrom(s string, c byte, from int) int {
+	if k := strings.IndexByte(s[from:], c); k >= 0 {
+		return from + k
+	}
+	return len(s)
+}
+
+// isASCII reports whether s has no multi-byte characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func asciiLower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func asciiUpper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
+
+// searchModeLabel describes how a prompt text is matched
+func (m *logModel) searchModeLabel(text string) string {
+	if isFieldQuery(text) {
+		return "field query"
+	}
+	return m.searchOptions.label()
+}
+
+// searchErrorText shortens a regexp compile error for the status bar
+func searchErrorText(err error) string {
+	return strings.TrimPrefix(err.Error(), "error parsing regexp: ")
+}
+
+// toggleSearchOption flips one search mode from a prompt
+func (m *logModel) toggleSearchOption(action string) {
+	switch action {
+	case actionSearchRegex:
+		m.searchOptions.regex = !m.searchOptions.regex
+	case actionSearchCase:
+		m.searchOptions.caseSensitive = !m.searchOptions.caseSensitive
+	case actionSearchWord:
+		m.searchOptions.wholeWord = !m.searchOptions.wholeWord
+	}
+	m.lastSearchQuery = "" // The same query now matches differently
+}
+
+// checkSearchQuery compiles the query being typed so errors show before it is run
+func (m *logModel) checkSearchQuery() {
+	m.searchError = ""
+	if m.searchQuery == "" {
+		return
+	}
+	if _, _, err := m.compileSearch(m.searchQuery); err != nil {
+		m.searchError = searchErrorText(err)
+	}
+}
+
+// searchPromptStatus renders the prompt line of the status bar
+func (m *logModel) searchPromptStatus() string {
+	keys := m.config.Keys
+	prompt := m.config.SearchStyle().Render("Search [" + m.searchModeLabel(m.searchQuery) + "]: " + m.searchQuery + "_")
+	if m.searchError != "" {
+		return prompt + "  " + m.config.ErrorStyle().Render("✗ "+m.searchError)
+	}
+	hints := ""
+	switch {
+	case m.searchPending != "":
+		hints = "searching… · "
+	case m.searchQuery != "" && m.searchQuery == m.lastSearchQuery:
+		hints = fmt.Sprintf("%d matches · ", len(m.matches))
+	}
+	hints += keys.hint(keyContextPrompt, actionSearchRegex) + " regex · " +
+		keys.hint(keyContextPrompt, actionSearchCase) + " case · " +
+		keys.hint(keyContextPrompt, actionSearchWord) + " word"
+	return prompt + "  " + m.config.MutedStyle().Render(hints)
+}
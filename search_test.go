@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSearchOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options searchOptions
+		query   string
+		text    string
+		want    bool
+	}{
+		{"literal ignores case", searchOptions{}, "ERROR", "an error here", true},
+		{"literal is not a pattern", searchOptions{}, "a.c", "abc", false},
+		{"regex", searchOptions{regex: true}, `user=\d+`, "user=42 ok", true},
+		{"case-sensitive", searchOptions{caseSensitive: true}, "Error", "an error here", false},
+		{"whole word", searchOptions{wholeWord: true}, "err", "an error here", false},
+		{"whole word match", searchOptions{wholeWord: true}, "error", "an error here", true},
+		{"regex alternation as a word", searchOptions{regex: true, wholeWord: true}, "warn|fail", "failed warn", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			re, err := tt.options.compile(tt.query)
+
+			// Assert
+			assertNoError(t, err)
+			assertBoolEqual(t, re.MatchString(tt.text), tt.want, tt.query)
+		})
+	}
+
+	t.Run("Label", func(t *testing.T) {
+		assertStringEqual(t, searchOptions{}.label(), "literal")
+		assertStringEqual(t, searchOptions{regex: true, caseSensitive: true, wholeWord: true}.label(), "regex, case-sensitive, whole word")
+	})
+}
+
+func TestSearchPromptModes(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	for _, message := range []string{"user=1 login", "user=22 logout", "user=x"} {
+		model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+	}
+	simulateWindowResize(model, 120, 30)
+	typeQuery := func(query string) {
+		for _, r := range query {
+			simulateKeyPress(model, string(r))
+		}
+	}
+
+	// Act - regex mode, then an unfinished pattern
+	simulateKeyPress(model, "/")
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	typeQuery(`user=(\d+`)
+
+	// Assert - the error is shown inline and enter keeps the prompt open
+	assertStringContains(t, model.View(), "Search [regex]: ")
+	assertStringContains(t, model.View(), "missing closing )")
+	simulateKeyPress(model, "enter")
+	assertBoolEqual(t, model.searchMode, true, "prompt stays open on an invalid pattern")
+
+	// Act - finish the pattern and run it
+	typeQuery(")")
+	simulateKeyPress(model, "enter")
+
+	// Assert
+	assertBoolEqual(t, model.searchMode, false, "search ran")
+	assertIntEqual(t, len(model.matches), 2, "regex matches")
+	assertStringContains(t, model.View(), "Matches: 2/2 [regex]")
+
+	// Act - the same query in literal mode matches nothing
+	simulateKeyPress(model, "/")
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	typeQuery(`user=(\d+)`)
+	simulateKeyPress(model, "enter")
+
+	// Assert
+	assertIntEqual(t, len(model.matches), 0, "literal matches")
+
+	// Act - ctrl+w deletes a word, ctrl+e toggles whole words
+	simulateKeyPress(model, "/")
+	typeQuery("user=2 login")
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	assertStringEqual(t, model.searchQuery, "user=2 ")
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	typeQuery("user=2")
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	simulateKeyPress(model, "enter")
+
+	// Assert - "user=2" is not a whole word of "user=22"
+	assertBoolEqual(t, model.searchOptions.wholeWord, true, "whole-word mode")
+	assertIntEqual(t, len(model.matches), 0, "whole-word matches")
+}
+
+func TestContainsFold(t *testing.T) {
+	tests := []struct {
+		s, substr string
+		want      bool
+	}{
+		{"Request TIMEOUT after 5s", "timeout", true},
+		{"request timeout", "TimeOut", true},
+		{"time out", "timeout", false},
+		{"tttimeout", "timeout", true},
+		{"abc", "abcd", false},
+		{"Xx", "xx", true},
+		{"anything", "", true},
+	}
+	for _, tt := range tests {
+		assertBoolEqual(t, containsFold(tt.s, tt.substr), tt.want, tt.s+" ~ "+tt.substr)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+/*
+Incremental Search
+
+Matches and highlights follow the search prompt as the query is typed. A scan
+works on a snapshot of the shown rows and returns the matches together with
+their highlighted lines, so nothing but the snapshot is touched on the UI
+goroutine:
+
+	buffer <= syncSearchLimit   scanned inline on every keystroke
+	larger buffers              scanned by a background command once typing
+	                            pauses for searchDebounce
+
+Every scan carries a generation number. A keystroke, a new filter or anything
+that clears the search bumps the generation and cancels the running scan, and
+results of an older generation are dropped when they arrive. Entries that a
+format or theme change left stale are re-rendered inside the scan instead of
+reprocessing the whole buffer first.
+*/
+
+// syncSearchLimit is the largest buffer scanned on the UI goroutine
+var syncSearchLimit = 5000
+
+// searchDebounce is how long typing must pause before a background scan starts
+const searchDebounce = 120 * time.Millisecond
+
+// searchCheckInterval is how many rows a scan matches between cancellation checks
+const searchCheckInterval = 1024
+
+// searchScan is one search over a snapshot of the shown rows
+type searchScan struct {
+	gen     int
+	query   string
+	regex   *regexp.Regexp
+	match   searchMatcher
+	layers  []highlightLayer // Rules, then the search pattern in the regular match style
+	rows    []int
+	texts   []string   // Display text of row rows[k], when the text alone is matched
+	entries []logEntry // Copy of row rows[k] otherwise: field queries and stale entries
+	stale   *UIConfig  // Copy of the settings to re-render entries with, nil when they are current
+}
+
+// searchResultMsg carries the outcome of a scan
+type searchResultMsg struct {
+	gen         int
+	query       string
+	regex       *regexp.Regexp
+	matches     []int
+	highlighted map[int]string
+}
+
+// searchDebounceMsg starts the background scan of a generation when typing paused
+type searchDebounceMsg struct {
+	gen int
+}
+
+// newSearchScan compiles the query and snapshots the rows to scan
+func (m *logModel) newSearchScan() (*searchScan, error) {
+	match, regex, err := m.compileSearch(m.searchQuery)
+	if err != nil {
+		return nil, err
+	}
+	logs := m.safeLogs()
+	rows, _ := m.shownRows(logs) // Hidden entries cannot be navigated to
+	scan := &searchScan{
+		gen:    m.searchGen,
+		query:  m.searchQuery,
+		regex:  regex,
+		match:  match,
+		layers: append(m.ruleLayers(), highlightLayer{re: regex, style: m.config.HighlightStyle()}),
+		rows:   rows,
+	}
+	if m.needsLazyReprocess {
+		settings := *m.config
+		scan.stale = &settings
+	}
+
+	// Copying strings instead of whole entries keeps the snapshot cheap
+	if match.fields == nil && scan.stale == nil {
+		scan.texts = make([]string, len(rows))
+		for k, i := range rows {
+			scan.texts[k] = logs[i].plainText()
+		}
+		return scan, nil
+	}
+	scan.entries = make([]logEntry, len(rows))
+	for k, i := range rows {
+		scan.entries[k] = logs[i]
+	}
+	return scan, nil
+}
+
+// run matches every row of the snapshot; it gives up when ctx is cancelled
+func (s *searchScan) run(ctx context.Context) (searchResultMsg, bool) {
+	result := searchResultMsg{
+		gen:         s.gen,
+		query:       s.query,
+		regex:       s.regex,
+		matches:     []int{},
+		highlighted: make(map[int]string),
+	}
+	for k, i := range s.rows {
+		if k%searchCheckInterval == 0 && ctx.Err() != nil {
+			return result, false
+		}
+		var text string
+		if s.texts != nil {
+			text = s.texts[k]
+			if !s.match.text(text) {
+				continue
+			}
+		} else {
+			entry := &s.entries[k]
+			if s.stale != nil {
+				*entry = reformatEntry(*entry, s.stale)
+			}
+			if !s.match.matches(entry) {
+				continue
+			}
+			text = entry.plainText()
+		}
+		result.matches = append(result.matches, i)
+		result.highlighted[i], _ = paintMatches(text, s.layers)
+	}
+	return result, true
+}
+
+// searchAsync reports whether the buffer is too large to scan on the UI goroutine
+func (m *logModel) searchAsync() bool {
+	return m.store != nil && m.store.Len() > syncSearchLimit
+}
+
+// cancelSearchScan drops the running scan and any result still on its way
+func (m *logModel) cancelSearchScan() {
+	if m.searchCancel != nil {
+		m.searchCancel()
+		m.searchCancel = nil
+	}
+	m.searchGen++
+	m.searchPending = ""
+}
+
+// searchCmd runs the current query: inline on small buffers, else in the
+// background, after a pause in typing when debounce is set
+func (m *logModel) searchCmd(debounce bool) tea.Cmd {
+	if m.searchQuery == "" {
+		m.clearSearchState()
+		return nil
+	}
+	if !m.searchAsync() {
+		m.cancelSearchScan()
+		m.performSearch()
+		return nil
+	}
+	if m.searchQuery == m.searchPending {
+		return nil // Already being scanned
+	}
+	if m.searchQuery == m.lastSearchQuery && !m.needsLazyReprocess {
+		m.cancelSearchScan() // Typed back to the query whose results are shown
+		return nil
+	}
+
+	m.cancelSearchScan()
+	if debounce {
+		gen := m.searchGen
+		return tea.Tick(searchDebounce, func(time.Time) tea.Msg { return searchDebounceMsg{gen} })
+	}
+	return m.startSearchScan()
+}
+
+// startSearchScan snapshots the rows and scans them in a background command
+func (m *logModel) startSearchScan() tea.Cmd {
+	scan, err := m.newSearchScan()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Invalid search pattern: %s", searchErrorText(err))
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.searchCancel = cancel
+	m.searchPending = scan.query
+	m.statusMessage = fmt.Sprintf("Searching %d lines...", len(scan.rows))
+	return func() tea.Msg {
+		result, ok := scan.run(ctx)
+		if !ok {
+			return nil
+		}
+		return result
+	}
+}
+
+// applySearchResult takes over the matches of a finished scan
+func (m *logModel) applySearchResult(result searchResultMsg) {
+	if m.searchCancel != nil {
+		m.searchCancel()
+		m.searchCancel = nil
+	}
+	m.searchPending = ""
+	m.lastSearchQuery = result.query
+	m.searchRegex = result.regex
+	m.matches = result.matches
+	m.highlighted = result.highlighted
+
+	if len(m.matches) == 0 {
+		m.statusMessage = fmt.Sprintf("No matches found for '%s'", result.query)
+		return
+	}
+	// Start at the LAST match (most recent/latest log)
+	m.currentMatch = len(m.matches) - 1
+	m.cursor = m.matches[m.currentMatch]
+	m.followMode = false // Prevent tick from overwriting cursor
+	m.centerOnCursor()
+	m.statusMessage = fmt.Sprintf("Found %d matches", len(m.matches))
+	m.repaintMatch(m.cursor, true)
+}
+
+// repaintMatch re-renders the highlight of one match in the current or regular match style
+func (m *logModel) repaintMatch(index int, current bool) {
+	hl, ok := m.highlighted[index]
+	if !ok || m.searchRegex == nil {
+		return
+	}
+	style := m.config.HighlightStyle()
+	if current {
+		style = m.config.CurrentMatchStyle()
+	}
+	layers := append(m.ruleLayers(), highlightLayer{re: m.searchRegex, style: style})
+	m.highlighted[index], _ = paintMatches(stripANSI(hl), layers)
+}
+
+// research runs the active search again after the shown rows changed
+func (m *logModel) research() tea.Cmd {
+	if m.searchQuery == "" || m.searchRegex == nil {
+		return nil
+	}
+	m.lastSearchQuery = ""
+	return m.searchCmd(false)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestIncrementalSearch(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	for _, message := range []string{"error one", "ok", "error two", "server warning"} {
+		model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+	}
+	simulateWindowResize(model, 120, 30)
+
+	// Act - matches follow the prompt before enter
+	simulateKeyPress(model, "/")
+	simulateKeyPress(model, "e")
+	simulateKeyPress(model, "r")
+
+	// Assert
+	assertIntEqual(t, len(model.matches), 3, "matches for er")
+	assertStringContains(t, stripANSI(model.View()), "3 matches")
+	simulateKeyPress(model, "r")
+	simulateKeyPress(model, "o")
+	assertIntEqual(t, len(model.matches), 2, "matches for erro")
+	assertIntEqual(t, len(model.highlighted), 2, "highlighted while typing")
+
+	// Act - cancelling the prompt drops them
+	simulateKeyPress(model, "esc")
+
+	// Assert
+	assertIntEqual(t, len(model.matches), 0, "matches after esc")
+	assertIntEqual(t, len(model.highlighted), 0, "highlights after esc")
+}
+
+func TestBackgroundSearch(t *testing.T) {
+	// Arrange - a buffer above the inline limit
+	defer func(limit int) { syncSearchLimit = limit }(syncSearchLimit)
+	syncSearchLimit = 10
+	model := createTestLogModel("/app")
+	for i := 0; i < 50; i++ {
+		model.store.Append(makeLogEntry(testTimestamp, fmt.Sprintf("request %d status %d", i, 200+i%3*100), model.config))
+	}
+	simulateWindowResize(model, 120, 30)
+
+	// Act - typing only schedules a scan
+	simulateKeyPress(model, "/")
+	for _, r := range "status 4" {
+		simulateKeyPress(model, string(r))
+	}
+
+	// Assert
+	assertIntEqual(t, len(model.matches), 0, "no inline scan")
+	_, cmd := model.Update(searchDebounceMsg{gen: model.searchGen - 1})
+	if cmd != nil {
+		t.Fatal("an outdated pause must not start a scan")
+	}
+
+	// Act - the pause starts the scan; its result arrives as a message
+	_, cmd = model.Update(searchDebounceMsg{gen: model.searchGen})
+	if cmd == nil {
+		t.Fatal("the pause should start a scan")
+	}
+	assertStringContains(t, stripANSI(model.View()), "searching…")
+	result := cmd()
+	simulateKeyPress(model, "0") // "status 40" makes the running scan outdated
+	model.Update(result)
+
+	// Assert - the outdated result is dropped
+	assertIntEqual(t, len(model.matches), 0, "outdated result applied")
+
+	// Act - enter scans the final query right away
+	_, cmd = simulateKeyPress(model, "enter")
+	if cmd == nil {
+		t.Fatal("enter should start a scan")
+	}
+	model.Update(cmd())
+
+	// Assert
+	assertIntEqual(t, len(model.matches), 16, "matches for status 40")
+	assertIntEqual(t, model.cursor, 47, "cursor on the latest match")
+	assertStringContains(t, model.statusMessage, "Found 16 matches")
+	if _, ok := model.highlighted[47]; !ok {
+		t.Fatal("highlights should come with the matches")
+	}
+}
+
+func TestSearchScanCancel(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	model.store.Append(makeLogEntry(testTimestamp, "error", model.config))
+	model.searchQuery = "error"
+	scan, err := model.newSearchScan()
+	assertNoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, ok := scan.run(ctx)
+
+	// Assert
+	assertBoolEqual(t, ok, false, "cancelled scan finished")
+}
+
+func BenchmarkSearchScan(b *testing.B) {
+	model := createTestLogModel("/app")
+	model.store = newLogStore(100000)
+	for i := 0; i < 100000; i++ {
+		message := fmt.Sprintf(`{"level":"info","request":%d,"path":"/api/items/%d","status":200}`, i, i%97)
+		model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+	}
+	model.searchQuery = "items/42"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scan, _ := model.newSearchScan()
+		scan.run(context.Background())
+	}
+}
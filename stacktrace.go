@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+Stack Trace Folding
+
+Stack traces arrive either as one multi-line event or as one event per line.
+Both are folded into a single row showing the exception line:
+
+	[12:00:00] ▸ java.lang.IllegalStateException: boom (+14 stack lines)
+
+A fold starts at any event and takes in the stack lines of its own message and
+the continuation events right after it from the same stream (frames, "Caused
+by:", "... 3 more", indented source lines, the exception line closing a Python
+traceback). Only traces with a frame and at least minTraceLines lines are
+folded. Folds start collapsed; z toggles the fold at the cursor and Z expands or
+collapses every fold.
+
+Stack lines are counted when an entry is created, so folding only walks the
+visible rows.
+*/
+
+// minTraceLines is the number of stack lines a fold needs
+const minTraceLines = 3
+
+var (
+	// stackLineRegex matches one frame or trace marker line of Java, Node.js, Python or Go
+	stackLineRegex = regexp.MustCompile(`^(?:\s+at\s|at [\w$.<>/]+\(|\s*Caused by:|\s*Suppressed:|\s*\.\.\. \d+ (?:more|common frames omitted)|` +
+		`\s+File ".*", line \d+|Traceback \(most recent call last\):|During handling of the above exception|` +
+		`goroutine \d+ \[|\s+\S+\.go:\d+|(?:created by )?[\w./*()-]+\.[\w*$]+\(.*\)(?: in goroutine \d+)?$)`)
+
+	// exceptionLineRegex matches the line naming the exception ("ValueError: boom", "panic: ...")
+	exceptionLineRegex = regexp.MustCompile(`^(?:panic: |Exception in thread |[\w.$]*(?:Error|Exception|Exit|Interrupt)\b)`)
+)
+
+// countStackLines returns the number of stack lines of a message
+func countStackLines(message string) int {
+	count := 0
+	for _, line := range strings.Split(strings.TrimRight(message, "\n"), "\n") {
+		if stackLineRegex.MatchString(line) {
+			count++
+		}
+	}
+	return count
+}
+
+// stackContinuation reports whether an entry only continues a trace started above
+// it: all of its lines are stack lines, or it is a single indented line (the
+// source line Python prints under a frame)
+func stackContinuation(entry *logEntry) bool {
+	message := strings.TrimRight(entry.OriginalMessage, "\n")
+	lines := strings.Count(message, "\n") + 1
+	if entry.StackLines > 0 && entry.StackLines == lines {
+		return true
+	}
+	return lines == 1 && strings.TrimSpace(message) != "" && (message[0] == ' ' || message[0] == '\t')
+}
+
+// traceFold is a stack trace folded into the row of its first event
+type traceFold struct {
+	head    int   // Store index of the row that shows the fold
+	members []int // Continuation events after the head, in display order
+	lines   int   // Stack lines folded away
+}
+
+// foldTraces finds the stack traces among rows, keyed by head row
+func foldTraces(logs []logEntry, rows []int) map[int]*traceFold {
+	var folds map[int]*traceFold
+	for pos := 0; pos < len(rows); pos++ {
+		head := &logs[rows[pos]]
+		fold := &traceFold{head: rows[pos], lines: head.StackLines}
+		frames := head.StackLines
+
+		// Python closes a traceback with the exception line, which is not a frame
+		for pos+1 < len(rows) {
+			next := &logs[rows[pos+1]]
+			if next.Stream != head.Stream {
+				break
+			}
+			if stackContinuation(next) {
+				fold.lines += max(next.StackLines, 1)
+				frames += next.StackLines
+			} else if frames > 0 && exceptionLineRegex.MatchString(strings.TrimSpace(next.OriginalMessage)) &&
+				!strings.Contains(next.OriginalMessage, "\n") {
+				fold.lines++
+			} else {
+				break
+			}
+			fold.members = append(fold.members, rows[pos+1])
+			pos++
+		}
+
+		if frames > 0 && fold.lines >= minTraceLines {
+			if folds == nil {
+				folds = make(map[int]*traceFold)
+			}
+			folds[fold.head] = fold
+		}
+	}
+	return folds
+}
+
+// exception picks the line that names the exception: the last exception line
+// of a Python traceback, otherwise the first line of the trace
+func (f *traceFold) exception(logs []logEntry) string {
+	lines := strings.Split(strings.TrimSpace(logs[f.head].OriginalMessage), "\n")
+	for _, member := range f.members {
+		lines = append(lines, strings.TrimSpace(logs[member].OriginalMessage))
+	}
+
+	if strings.HasPrefix(lines[0], "Traceback") {
+		for i := len(lines) - 1; i > 0; i-- {
+			if exceptionLineRegex.MatchString(lines[i]) {
+				return lines[i]
+			}
+		}
+	}
+	return lines[0]
+}
+
+// render returns the display line of a collapsed fold
+func (f *traceFold) render(logs []logEntry, config *UIConfig) string {
+	entry := &logs[f.head]
+	count := fmt.Sprintf("(+%d stack lines)", f.lines)
+	if config.ColorizeFields {
+		count = config.MutedStyle().Render(count)
+	}
+	return fmt.Sprintf("%s ▸ %s %s", timestampPrefix(entry, config, time.Now()), config.redact(f.exception(logs)), count)
+}
+
+// traceExpanded reports whether the fold headed by a row is expanded
+func (m *logModel) traceExpanded(head int) bool {
+	return m.tracesExpanded != m.toggledTraces[m.traceKey(head)]
+}
+
+// traceKey identifies the fold headed by a row. Store indices shift when the
+// buffer wraps, so toggles are kept by the entry's position in the whole
+// stream of appended entries.
+func (m *logModel) traceKey(head int) int {
+	if m.store == nil {
+		return head
+	}
+	return m.store.Dropped() + head
+}
+
+// foldAt returns the fold containing a row, if any
+func foldAt(folds map[int]*traceFold, index int) *traceFold {
+	if fold := folds[index]; fold != nil {
+		return fold
+	}
+	for _, fold := range folds {
+		for _, member := range fold.members {
+			if member == index {
+				return fold
+			}
+		}
+	}
+	return nil
+}
+
+// toggleTraceAtCursor expands or collapses the fold under the cursor
+func (m *logModel) toggleTraceAtCursor() {
+	fold := foldAt(m.layoutRows(m.safeLogs()).folds, m.cursor)
+	if fold == nil {
+		m.formatStatusMsg = "No stack trace at cursor"
+		return
+	}
+	if m.toggledTraces == nil {
+		m.toggledTraces = make(map[int]bool)
+	}
+	key := m.traceKey(fold.head)
+	m.toggledTraces[key] = !m.toggledTraces[key]
+	m.traceToggles++
+	m.cursor = fold.head
+	m.followMode = false
+	m.fixCursor()
+}
+
+// toggleAllTraces expands or collapses every fold
+func (m *logModel) toggleAllTraces() {
+	m.tracesExpanded = !m.tracesExpanded
+	m.toggledTraces = nil
+	m.traceToggles++
+	if fold := foldAt(m.layoutRows(m.safeLogs()).folds, m.cursor); fold != nil {
+		m.cursor = fold.head
+	}
+	m.fixCursor()
+	if m.tracesExpanded {
+		m.formatStatusMsg = "Stack traces expanded"
+	} else {
+		m.formatStatusMsg = "Stack traces folded"
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testJavaTrace = "java.lang.IllegalStateException: boom\n" +
+	"\tat com.example.Service.run(Service.java:42)\n" +
+	"\tat com.example.Main.main(Main.java:10)\n" +
+	"Caused by: java.io.IOException: disk\n" +
+	"\t... 2 more"
+
+func TestStackTraceDetection(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want bool
+	}{
+		{"java frame", "\tat com.example.Main.main(Main.java:10)", true},
+		{"node frame", "    at processTicksAndRejections (node:internal/process/task_queues:95:5)", true},
+		{"caused by", "Caused by: java.io.IOException: disk", true},
+		{"more", "\t... 12 more", true},
+		{"python frame", `  File "/var/task/app.py", line 12, in handler`, true},
+		{"go goroutine", "goroutine 1 [running]:", true},
+		{"go function", "main.handler(0xc000010000)", true},
+		{"go file", "\t/app/main.go:42 +0x1d", true},
+		{"prose", "at the moment nothing is wrong", false},
+		{"log line", "[INFO] request served", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertBoolEqual(t, countStackLines(tt.line) == 1, tt.want, tt.line)
+		})
+	}
+}
+
+func TestStackTraceFolding(t *testing.T) {
+	t.Run("WithinEvent", func(t *testing.T) {
+		config := createTestConfig()
+		logs := []logEntry{
+			makeLogEntry(testTimestamp, "[INFO] before", config),
+			makeLogEntry(testTimestamp, testJavaTrace, config),
+		}
+
+		folds := foldTraces(logs, []int{0, 1})
+
+		assertIntEqual(t, len(folds), 1, "folds")
+		assertIntEqual(t, folds[1].lines, 4, "stack lines")
+		assertStringEqual(t, folds[1].exception(logs), "java.lang.IllegalStateException: boom")
+	})
+
+	t.Run("AcrossEvents", func(t *testing.T) {
+		// Arrange - a Python traceback logged line by line, another stream interleaved at the end
+		config := createTestConfig()
+		var logs []logEntry
+		for _, line := range []string{
+			"Traceback (most recent call last):",
+			`  File "/var/task/app.py", line 12, in handler`,
+			"    do_work()",
+			`  File "/var/task/work.py", line 3, in do_work`,
+			"ValueError: bad input",
+			"next request",
+		} {
+			logs = append(logs, lambdaEntry("s1", line, config))
+		}
+		logs = append(logs, lambdaEntry("s2", "    at other (x.js:1:1)", config))
+
+		// Act
+		folds := foldTraces(logs, []int{0, 1, 2, 3, 4, 5, 6})
+
+		// Assert
+		fold := folds[0]
+		if fold == nil {
+			t.Fatal("traceback should be folded")
+		}
+		assertIntEqual(t, len(fold.members), 4, "members")
+		assertStringEqual(t, fold.exception(logs), "ValueError: bad input")
+		assertIntEqual(t, len(folds), 1, "other stream is not joined")
+	})
+
+	t.Run("ShortTracesStay", func(t *testing.T) {
+		config := createTestConfig()
+		logs := []logEntry{makeLogEntry(testTimestamp, "error\n\tat a.b(C.java:1)", config)}
+		assertIntEqual(t, len(foldTraces(logs, []int{0})), 0, "folds")
+	})
+}
+
+func TestStackTraceFoldingInViewer(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("test")
+	for _, msg := range []string{"[INFO] start", "panic: boom", "", "goroutine 1 [running]:", "main.main()", "\t/app/main.go:9 +0x1d", "[INFO] after"} {
+		if msg == "" {
+			continue
+		}
+		model.store.Append(lambdaEntry("s", msg, model.config))
+	}
+	model.followMode = false
+	model.cursor = 1
+	simulateWindowResize(model, 160, 40)
+
+	// Assert - folded by default
+	rows := model.visibleRows(model.safeLogs())
+	assertSliceLength(t, rows, 3, "folded rows")
+	view := model.View()
+	assertStringContains(t, view, "▸ panic: boom (+3 stack lines)")
+	if strings.Contains(view, "goroutine 1") {
+		t.Error("frames should be folded")
+	}
+
+	// Act - expand at the cursor
+	simulateKeyPress(model, "z")
+	assertSliceLength(t, model.visibleRows(model.safeLogs()), 6, "expanded rows")
+	assertStringContains(t, model.View(), "goroutine 1")
+
+	// Collapsing from a member row moves the cursor to the head
+	model.cursor = 3
+	simulateKeyPress(model, "z")
+	assertIntEqual(t, model.cursor, 1, "cursor on head")
+	assertSliceLength(t, model.visibleRows(model.safeLogs()), 3, "folded again")
+
+	simulateKeyPress(model, "Z")
+	assertSliceLength(t, model.visibleRows(model.safeLogs()), 6, "all expanded")
+}
+
+func TestStackTraceToggleAfterWrap(t *testing.T) {
+	// Arrange - two traces in a buffer about to wrap
+	model := createTestLogModel("test")
+	model.store = newLogStore(10)
+	for _, msg := range []string{"[INFO] start", "panic: first", "goroutine 1 [running]:", "main.main()", "\t/app/main.go:9 +0x1d",
+		"[INFO] between", "panic: second", "goroutine 1 [running]:", "main.run()", "\t/app/run.go:3 +0x10"} {
+		model.store.Append(lambdaEntry("s", msg, model.config))
+	}
+	model.followMode = false
+	model.cursor = 1
+	simulateWindowResize(model, 160, 40)
+	simulateKeyPress(model, "z") // Expand the first trace
+
+	// Act - one new line drops "[INFO] start" and shifts every index
+	model.Update(logsWithTokenMsg{logs: []logEntry{lambdaEntry("s", "[INFO] after", model.config)}})
+
+	// Assert - the first trace stays expanded, the second stays folded
+	view := stripANSI(model.View())
+	assertStringContains(t, view, "main.main()")
+	assertStringContains(t, view, "▸ panic: second")
+	if strings.Contains(view, "main.run()") {
+		t.Error("the second trace should stay folded")
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+/*
+View State
+
+Choices made in the viewer that should survive a restart are kept in a state
+file next to the config file, by default ~/.config/cwlogs/state.json (or
+$CWLOGS_STATE). Unlike the config file it is written by cwlogs:
+
+	{
+	  "columns": { "/aws/lambda/api": ["level", "msg", "http.status"] },
+	  "highlights": [{ "name": "timeout", "pattern": "(?i)timeout", "color": "red" }],
+	  "highlightToggles": { "customer": false }
+	}
+
+Saves run as commands, which Bubble Tea may run concurrently, so every
+read-modify-write of the file holds stateMu. An empty path disables
+persistence; tests rely on that.
+*/
+
+// stateFileEnv names the environment variable that overrides the state path
+const stateFileEnv = "CWLOGS_STATE"
+
+// viewState mirrors the on-disk state file
+type viewState struct {
+	Columns          map[string][]string `json:"columns,omitempty"`          // Column paths by log group
+	Highlights       []HighlightRule     `json:"highlights,omitempty"`       // Rules added at the prompt (see highlightrules.go)
+	HighlightToggles map[string]bool     `json:"highlightToggles,omitempty"` // On/off state of config file rules, by name
+}
+
+// stateMu serializes updates of the state file
+var stateMu sync.Mutex
+
+// defaultStatePath returns the state file location honoring $CWLOGS_STATE
+func defaultStatePath() string {
+	if path := os.Getenv(stateFileEnv); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cwlogs", "state.json")
+}
+
+// loadViewState reads the state file. A missing file or empty path yields an empty state.
+func loadViewState(path string) (*viewState, error) {
+	state := &viewState{}
+	if path == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return &viewState{}, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// save writes the state file, replacing it atomically
+func (s *viewState) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// updateViewState loads the state file, applies change and writes it back
+func updateViewState(path string, change func(*viewState)) error {
+	if path == "" {
+		return nil
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	state, err := loadViewState(path)
+	if err != nil {
+		return err
+	}
+	change(state)
+	return state.save(path)
+}
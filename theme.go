@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+/*
+Themes
+
+A theme is a ColorScheme. Four are built in:
+
+	dark          - the default, tuned for dark terminal backgrounds
+	light         - darker foregrounds for light terminal backgrounds
+	high-contrast - bright 16-color palette with strong cursor/match contrast
+	monochrome    - no colors, only bold/reverse/underline attributes
+
+Custom themes are declared in the config file and start from a built-in base:
+
+	{
+	  "theme": "solarized",
+	  "themes": {
+	    "solarized": { "base": "light", "colors": { "headerColor": "33", "cursorBgColor": "254" } }
+	  }
+	}
+
+NO_COLOR (https://no-color.org) or TERM=dumb always selects monochrome at startup.
+Press T in the viewer to cycle themes.
+*/
+
+const (
+	defaultThemeName    = "dark"
+	monochromeThemeName = "monochrome"
+)
+
+// builtinThemeOrder is the cycling order for built-in themes
+var builtinThemeOrder = []string{"dark", "light", "high-contrast", monochromeThemeName}
+
+// themeDefinition is a custom theme as written in the config file
+type themeDefinition struct {
+	Base   string          `json:"base,omitempty"` // Built-in theme to start from (default "dark")
+	Colors json.RawMessage `json:"colors"`         // Partial ColorScheme overriding the base
+}
+
+// darkColorScheme is the default theme, tuned for dark terminals
+func darkColorScheme() ColorScheme {
+	return ColorScheme{
+		// Header & UI colors
+		HeaderColor:      "12", // Blue - stands out for log group identification
+		MutedColor:       "8",  // Dark gray - help text stays out of the way
+		BorderColor:      "8",  // Dark gray - frames logs without distracting
+		InfoColor:        "10", // Green - confirmations
+		WarningColor:     "11", // Yellow - status and loading messages
+		ErrorColor:       "9",  // Red - errors
+		SelectionBgColor: "12", // Blue background for selected menu items
+		SelectionFgColor: "0",  // Black text on blue
+
+		// Search interface colors
+		SearchColor: "11", // Yellow - bright and attention-grabbing for search mode
+		MatchColor:  "10", // Green - positive color for successful matches
+
+		// Log line display colors (zebra striping for readability)
+		EvenRowColor:  "245", // Light gray - subtle for alternating rows
+		OddRowColor:   "15",  // White - high contrast with even rows
+		CursorBgColor: "8",   // Dark gray - clear selection indicator
+		CursorFgColor: "15",  // White - high contrast text on cursor
+
+		// Search match highlighting
+		MatchBgColor:        "10",  // Green background - makes matches pop out
+		MatchFgColor:        "0",   // Black text - ensures readability on green background
+		CurrentMatchBgColor: "196", // Bright red - the match under the cursor
+		CurrentMatchFgColor: "15",  // White text on red
+
+		// Access log fields
+		Status2xxColor:    "10", // Green
+		Status3xxColor:    "11", // Yellow
+		Status4xxColor:    "9",  // Red
+		Status5xxColor:    "1",  // Dark red
+		MethodGetColor:    "12", // Blue
+		MethodPostColor:   "13", // Magenta
+		MethodPutColor:    "14", // Cyan
+		MethodDeleteColor: "9",  // Red
+		FieldColor:        "15", // White
+		IPColor:           "6",  // Cyan
+		SizeColor:         "8",  // Gray
+
+		// Log levels
+		LevelTraceColor: "240", // Dark gray - noise
+		LevelDebugColor: "244", // Gray - secondary
+		LevelWarnColor:  "11",  // Yellow
+		LevelErrorColor: "9",   // Red
+		LevelFatalColor: "196", // Bright red, bold
+
+		// JSON
+		JSONKeyColor:    "12", // Blue
+		JSONStringColor: "10", // Green
+		JSONNumberColor: "14", // Cyan
+		JSONBoolColor:   "11", // Yellow
+		JSONNullColor:   "8",  // Gray
+
+		JSONPunctuationColor: "244", // Gray - structure stays in the background
+	}
+}
+
+// lightColorScheme keeps foregrounds dark enough to read on white backgrounds
+func lightColorScheme() ColorScheme {
+	return ColorScheme{
+		HeaderColor:      "25",
+		MutedColor:       "244",
+		BorderColor:      "248",
+		InfoColor:        "28",
+		WarningColor:     "130",
+		ErrorColor:       "160",
+		SelectionBgColor: "25",
+		SelectionFgColor: "15",
+
+		SearchColor: "130",
+		MatchColor:  "28",
+
+		EvenRowColor:  "240",
+		OddRowColor:   "0",
+		CursorBgColor: "253",
+		CursorFgColor: "0",
+
+		MatchBgColor:        "192",
+		MatchFgColor:        "0",
+		CurrentMatchBgColor: "160",
+		CurrentMatchFgColor: "15",
+
+		Status2xxColor:    "28",
+		Status3xxColor:    "130",
+		Status4xxColor:    "160",
+		Status5xxColor:    "88",
+		MethodGetColor:    "25",
+		MethodPostColor:   "90",
+		MethodPutColor:    "30",
+		MethodDeleteColor: "160",
+		FieldColor:        "0",
+		IPColor:           "30",
+		SizeColor:         "244",
+
+		LevelTraceColor: "250",
+		LevelDebugColor: "244",
+		LevelWarnColor:  "130",
+		LevelErrorColor: "160",
+		LevelFatalColor: "124",
+
+		JSONKeyColor:    "25",
+		JSONStringColor: "28",
+		JSONNumberColor: "30",
+		JSONBoolColor:   "130",
+		JSONNullColor:   "244",
+
+		JSONPunctuationColor: "240",
+	}
+}
+
+// highContrastColorScheme uses only the 16 base colors at full intensity
+func highContrastColorScheme() ColorScheme {
+	return ColorScheme{
+		HeaderColor:      "15",
+		MutedColor:       "7",
+		BorderColor:      "15",
+		InfoColor:        "10",
+		WarningColor:     "11",
+		ErrorColor:       "9",
+		SelectionBgColor: "15",
+		SelectionFgColor: "0",
+
+		SearchColor: "11",
+		MatchColor:  "10",
+
+		EvenRowColor:  "15",
+		OddRowColor:   "15",
+		CursorBgColor: "15",
+		CursorFgColor: "0",
+
+		MatchBgColor:        "11",
+		MatchFgColor:        "0",
+		CurrentMatchBgColor: "9",
+		CurrentMatchFgColor: "15",
+
+		Status2xxColor:    "10",
+		Status3xxColor:    "11",
+		Status4xxColor:    "9",
+		Status5xxColor:    "9",
+		MethodGetColor:    "14",
+		MethodPostColor:   "13",
+		MethodPutColor:    "11",
+		MethodDeleteColor: "9",
+		FieldColor:        "15",
+		IPColor:           "14",
+		SizeColor:         "7",
+
+		LevelTraceColor: "7",
+		LevelDebugColor: "7",
+		LevelWarnColor:  "11",
+		LevelErrorColor: "9",
+		LevelFatalColor: "9",
+
+		JSONKeyColor:    "14",
+		JSONStringColor: "10",
+		JSONNumberColor: "11",
+		JSONBoolColor:   "13",
+		JSONNullColor:   "7",
+
+		JSONPunctuationColor: "15",
+	}
+}
+
+// builtinThemes returns a fresh copy of every built-in theme
+func builtinThemes() map[string]ColorScheme {
+	return map[string]ColorScheme{
+		"dark":              darkColorScheme(),
+		"light":             lightColorScheme(),
+		"high-contrast":     highContrastColorScheme(),
+		monochromeThemeName: {}, // Every color empty: attributes only
+	}
+}
+
+// colorDisabled reports whether the environment asks for no color output
+func colorDisabled() bool {
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// compileThemes builds custom themes from their config file definitions
+func compileThemes(defs map[string]themeDefinition) (map[string]ColorScheme, error) {
+	builtins := builtinThemes()
+	themes := make(map[string]ColorScheme, len(defs))
+
+	for name, def := range defs {
+		if _, exists := builtins[name]; exists {
+			return nil, fmt.Errorf("theme %q redefines a built-in theme", name)
+		}
+
+		baseName := def.Base
+		if baseName == "" {
+			baseName = defaultThemeName
+		}
+		scheme, ok := builtins[baseName]
+		if !ok {
+			return nil, fmt.Errorf("theme %q: unknown base theme %q", name, baseName)
+		}
+
+		// Decoding onto a copy of the base only replaces the colors that are set
+		if len(def.Colors) > 0 {
+			if err := json.Unmarshal(def.Colors, &scheme); err != nil {
+				return nil, fmt.Errorf("theme %q: %w", name, err)
+			}
+		}
+		themes[name] = scheme
+	}
+	return themes, nil
+}
+
+// ThemeNames lists built-in themes followed by custom themes in name order
+func (c *UIConfig) ThemeNames() []string {
+	names := append([]string(nil), builtinThemeOrder...)
+	var custom []string
+	for name := range c.Themes {
+		custom = append(custom, name)
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// disableColor forces the monochrome theme for the rest of the session
+func (c *UIConfig) disableColor() {
+	c.NoColor = true
+	_ = c.SetTheme(monochromeThemeName)
+}
+
+// SetTheme activates a built-in or custom theme by name
+func (c *UIConfig) SetTheme(name string) error {
+	if scheme, ok := c.Themes[name]; ok {
+		c.Theme = name
+		c.Colors = scheme
+		return nil
+	}
+	if scheme, ok := builtinThemes()[name]; ok {
+		c.Theme = name
+		c.Colors = scheme
+		return nil
+	}
+	return fmt.Errorf("unknown theme %q", name)
+}
+
+// NextTheme switches to the theme after the active one and returns its name.
+// With color disabled the monochrome theme is kept.
+func (c *UIConfig) NextTheme() string {
+	if c.NoColor {
+		return c.Theme
+	}
+	names := c.ThemeNames()
+	next := names[0]
+	for i, name := range names {
+		if name == c.Theme {
+			next = names[(i+1)%len(names)]
+			break
+		}
+	}
+	_ = c.SetTheme(next) // Every listed name is known
+	return next
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThemes(t *testing.T) {
+	t.Run("BuiltinThemesDefineEveryColor", func(t *testing.T) {
+		for name, scheme := range builtinThemes() {
+			if name == monochromeThemeName {
+				continue
+			}
+			v := reflect.ValueOf(scheme)
+			for i := 0; i < v.NumField(); i++ {
+				if v.Field(i).String() == "" {
+					t.Errorf("theme %q: %s is empty", name, v.Type().Field(i).Name)
+				}
+			}
+		}
+	})
+
+	t.Run("DefaultIsDark", func(t *testing.T) {
+		// Arrange & Act
+		config := NewUIConfig()
+
+		// Assert
+		assertStringEqual(t, config.Theme, defaultThemeName)
+		if config.Colors != darkColorScheme() {
+			t.Error("default colors should match the dark theme")
+		}
+	})
+
+	t.Run("CustomThemeStartsFromBase", func(t *testing.T) {
+		// Arrange
+		file, err := parseConfigFile([]byte(`{
+			"theme": "mine",
+			"themes": {"mine": {"base": "light", "colors": {"headerColor": "33"}}}
+		}`))
+		assertNoError(t, err)
+		config := NewUIConfig()
+
+		// Act
+		file.apply(config)
+
+		// Assert
+		assertStringEqual(t, config.Theme, "mine")
+		assertStringEqual(t, config.Colors.HeaderColor, "33")
+		assertStringEqual(t, config.Colors.OddRowColor, lightColorScheme().OddRowColor)
+	})
+
+	t.Run("InvalidThemeConfig", func(t *testing.T) {
+		tests := []struct {
+			name string
+			data string
+			want string
+		}{
+			{"unknown theme", `{"theme":"nope"}`, "unknown theme"},
+			{"unknown base", `{"themes":{"x":{"base":"nope"}}}`, "unknown base theme"},
+			{"redefines builtin", `{"themes":{"dark":{}}}`, "redefines a built-in"},
+			{"bad colors", `{"themes":{"x":{"colors":{"headerColor":12}}}}`, "theme \"x\""},
+			{"unknown profile theme", `{"profiles":[{"name":"p","match":"*","settings":{"theme":"nope"}}]}`, "profile \"p\""},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := parseConfigFile([]byte(tt.data))
+				assertError(t, err, tt.want)
+			})
+		}
+	})
+
+	t.Run("ProfileTheme", func(t *testing.T) {
+		// Arrange
+		file, err := parseConfigFile([]byte(`{"profiles":[{"name":"p","match":"/prod/*","settings":{"theme":"high-contrast"}}]}`))
+		assertNoError(t, err)
+		config := NewUIConfig()
+		file.apply(config)
+
+		// Act
+		prod := config.ForLogGroup("/prod/api")
+
+		// Assert
+		assertStringEqual(t, prod.Theme, "high-contrast")
+		assertStringEqual(t, config.Theme, defaultThemeName)
+	})
+
+	t.Run("NextThemeCycles", func(t *testing.T) {
+		// Arrange
+		config := NewUIConfig()
+		config.Themes = map[string]ColorScheme{"custom": lightColorScheme()}
+
+		// Act
+		var seen []string
+		for range config.ThemeNames() {
+			seen = append(seen, config.NextTheme())
+		}
+
+		// Assert
+		want := []string{"light", "high-contrast", "monochrome", "custom", "dark"}
+		if !reflect.DeepEqual(seen, want) {
+			t.Errorf("theme cycle: got %v, want %v", seen, want)
+		}
+	})
+
+	t.Run("NoColorStaysMonochrome", func(t *testing.T) {
+		// Arrange
+		config := NewUIConfig()
+		config.disableColor()
+		file, err := parseConfigFile([]byte(`{"theme":"light"}`))
+		assertNoError(t, err)
+
+		// Act
+		file.apply(config)
+		next := config.NextTheme()
+
+		// Assert
+		assertStringEqual(t, config.Theme, monochromeThemeName)
+		assertStringEqual(t, next, monochromeThemeName)
+		assertStringEqual(t, config.Colors.HeaderColor, "")
+	})
+
+	t.Run("ColorDisabledFromEnvironment", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		assertBoolEqual(t, colorDisabled(), true, "NO_COLOR")
+
+		t.Setenv("NO_COLOR", "")
+		t.Setenv("TERM", "dumb")
+		assertBoolEqual(t, colorDisabled(), true, "dumb terminal")
+
+		t.Setenv("TERM", "xterm-256color")
+		assertBoolEqual(t, colorDisabled(), false, "color terminal")
+	})
+
+	t.Run("MonochromeUsesReverseVideo", func(t *testing.T) {
+		// Arrange
+		config := NewUIConfig()
+		assertNoError(t, config.SetTheme(monochromeThemeName))
+
+		// Act & Assert
+		assertBoolEqual(t, config.CursorStyle().GetReverse(), true, "cursor reverse")
+		assertBoolEqual(t, config.HighlightStyle().GetReverse(), true, "highlight reverse")
+		assertBoolEqual(t, config.SelectionStyle().GetReverse(), true, "selection reverse")
+	})
+
+	t.Run("CycleThemeInViewer", func(t *testing.T) {
+		// Arrange
+		model := createTestLogModel("test")
+
+		// Act
+		simulateKeyPress(model, "T")
+
+		// Assert
+		assertStringEqual(t, model.config.Theme, "light")
+		assertStringContains(t, model.formatStatusMsg, "light")
+	})
+}
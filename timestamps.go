@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+/*
+Timestamps
+
+Every line starts with the event time in one of these formats:
+
+	local     [14:30:00]                       (default)
+	utc       [12:30:00Z]
+	zone      [08:30:00 EDT]                   the "timeZone" setting, e.g. "America/New_York"
+	datetime  [2026-01-02 14:30:00]
+	rfc3339   [2026-01-02T14:30:00.123+02:00]
+	relative  [  3m ago]
+
+t cycles through them (zone only when a time zone is configured), the
+"timestamps" setting picks the initial one. i adds the time CloudWatch
+ingested the event and the delay since the event: [14:30:00 → 14:30:02 +2.1s].
+
+The prefix is part of each entry's Raw line, so changing the format restamps
+the buffer; relative times are restamped every relativeRefresh.
+*/
+
+// Timestamp formats
+const (
+	timeFormatLocal    = "local"
+	timeFormatUTC      = "utc"
+	timeFormatZone     = "zone"
+	timeFormatDateTime = "datetime"
+	timeFormatRFC3339  = "rfc3339"
+	timeFormatRelative = "relative"
+)
+
+// timeFormats lists the formats in the order t cycles through them
+var timeFormats = []string{timeFormatLocal, timeFormatUTC, timeFormatZone, timeFormatDateTime, timeFormatRFC3339, timeFormatRelative}
+
+// relativeRefresh is how often relative timestamps are brought up to date
+const relativeRefresh = 15 * time.Second
+
+// relativeTickMsg restamps relative timestamps
+type relativeTickMsg time.Time
+
+// parseTimeFormat validates a "timestamps" setting
+func parseTimeFormat(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, format := range timeFormats {
+		if name == format {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown timestamp format %q (want one of %s)", name, strings.Join(timeFormats, ", "))
+}
+
+// formatTimestamp renders a time in the configured format
+func formatTimestamp(ts time.Time, config *UIConfig, now time.Time) string {
+	switch config.TimeFormat {
+	case timeFormatUTC:
+		return ts.UTC().Format("15:04:05Z")
+	case timeFormatZone:
+		if config.TimeZone != nil {
+			return ts.In(config.TimeZone).Format("15:04:05 MST")
+		}
+	case timeFormatDateTime:
+		return ts.Format("2006-01-02 15:04:05")
+	case timeFormatRFC3339:
+		return ts.Format("2006-01-02T15:04:05.000Z07:00")
+	case timeFormatRelative:
+		return fmt.Sprintf("%8s", formatAge(now.Sub(ts)))
+	}
+	return ts.Format("15:04:05")
+}
+
+// formatAge renders how long ago something happened: "12s ago", "3m ago", "2d ago"
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Second:
+		return "now"
+	case age < time.Minute:
+		return fmt.Sprintf("%ds ago", int(age/time.Second))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age/time.Minute))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age/time.Hour))
+	}
+	return fmt.Sprintf("%dd ago", int(age/(24*time.Hour)))
+}
+
+// formatDelay renders the ingestion delay: "+350ms", "+2.1s", "+1m5s"
+func formatDelay(delay time.Duration) string {
+	switch {
+	case delay < time.Second:
+		return fmt.Sprintf("+%dms", delay.Milliseconds())
+	case delay < time.Minute:
+		return fmt.Sprintf("+%.1fs", delay.Seconds())
+	}
+	return "+" + delay.Round(time.Second).String()
+}
+
+// timestampPrefix renders the "[...]" that starts the line of an entry
+func timestampPrefix(entry *logEntry, config *UIConfig, now time.Time) string {
+	stamp := formatTimestamp(entry.Timestamp, config, now)
+	if config.ShowIngestion && !entry.IngestionTime.IsZero() {
+		delay := formatDelay(entry.IngestionTime.Sub(entry.Timestamp))
+		if config.TimeFormat == timeFormatRelative {
+			stamp += " " + delay
+		} else {
+			stamp += " → " + formatTimestamp(entry.IngestionTime, config, now) + " " + delay
+		}
+	}
+	return "[" + stamp + "]"
+}
+
+// restamp rebuilds the display line from the formatted message and the timestamp settings
+func (e *logEntry) restamp(config *UIConfig, now time.Time) {
+	e.Raw = timestampPrefix(e, config, now) + " " + e.Message
+	e.plain = stripANSI(e.Raw)
+}
+
+// restampEntries rebuilds the display line of every entry after a timestamp setting changed
+func (m *logModel) restampEntries() {
+	now := time.Now()
+	for i, entry := range m.safeLogs() {
+		entry.restamp(m.config, now)
+		m.store.UpdateEntry(i, entry)
+	}
+	if m.searchRegex != nil {
+		m.applyHighlights()
+	}
+}
+
+// cycleTimeFormat switches to the next timestamp format
+func (m *logModel) cycleTimeFormat() tea.Cmd {
+	current := indexOfString(timeFormats, m.config.TimeFormat)
+	next := timeFormats[(current+1)%len(timeFormats)]
+	if next == timeFormatZone && m.config.TimeZone == nil {
+		next = timeFormats[(current+2)%len(timeFormats)]
+	}
+	m.config.TimeFormat = next
+	m.restampEntries()
+
+	m.formatStatusMsg = "Timestamps: " + next
+	if next == timeFormatZone {
+		m.formatStatusMsg += " (" + m.config.TimeZone.String() + ")"
+	}
+	if next == timeFormatRelative {
+		return tea.Batch(clearFormatStatusCmd(), relativeTickCmd())
+	}
+	return clearFormatStatusCmd()
+}
+
+// toggleIngestion shows or hides the ingestion time and delay
+func (m *logModel) toggleIngestion() tea.Cmd {
+	m.config.ShowIngestion = !m.config.ShowIngestion
+	m.restampEntries()
+	if m.config.ShowIngestion {
+		m.formatStatusMsg = "Showing ingestion time and delay"
+	} else {
+		m.formatStatusMsg = "Ingestion time hidden"
+	}
+	return clearFormatStatusCmd()
+}
+
+// relativeTickCmd schedules the next restamp of relative timestamps
+func relativeTickCmd() tea.Cmd {
+	return tea.Tick(relativeRefresh, func(t time.Time) tea.Msg {
+		return relativeTickMsg(t)
+	})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestamps(t *testing.T) {
+	t.Run("Formats", func(t *testing.T) {
+		// Arrange
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		assertNoError(t, err)
+		ts := time.Date(2026, 1, 2, 14, 30, 0, 123e6, time.UTC)
+		now := ts.Add(3*time.Minute + 10*time.Second)
+		tests := map[string]string{
+			timeFormatLocal:    "14:30:00",
+			timeFormatUTC:      "14:30:00Z",
+			timeFormatZone:     "15:30:00 CET",
+			timeFormatDateTime: "2026-01-02 14:30:00",
+			timeFormatRFC3339:  "2026-01-02T14:30:00.123Z",
+			timeFormatRelative: "  3m ago",
+		}
+
+		for format, want := range tests {
+			// Act
+			config := createTestConfig()
+			config.TimeFormat = format
+			config.TimeZone = berlin
+
+			// Assert
+			assertStringEqual(t, formatTimestamp(ts, config, now), want)
+		}
+	})
+
+	t.Run("AgesAndDelays", func(t *testing.T) {
+		assertStringEqual(t, formatAge(400*time.Millisecond), "now")
+		assertStringEqual(t, formatAge(45*time.Second), "45s ago")
+		assertStringEqual(t, formatAge(5*time.Hour), "5h ago")
+		assertStringEqual(t, formatAge(50*time.Hour), "2d ago")
+		assertStringEqual(t, formatDelay(350*time.Millisecond), "+350ms")
+		assertStringEqual(t, formatDelay(2100*time.Millisecond), "+2.1s")
+		assertStringEqual(t, formatDelay(65*time.Second), "+1m5s")
+	})
+
+	t.Run("IngestionPrefix", func(t *testing.T) {
+		// Arrange
+		config := createTestConfig()
+		entry := makeLogEntry(testTimestamp, "hello", config)
+		entry.IngestionTime = testTimestamp.Add(2100 * time.Millisecond)
+
+		// Act
+		config.ShowIngestion = true
+		entry.restamp(config, time.Now())
+
+		// Assert
+		assertStringEqual(t, entry.Raw, "[14:30:00 → 14:30:02 +2.1s] hello")
+	})
+}
+
+func TestTimestampKeys(t *testing.T) {
+	// Arrange
+	model := createTestLogModel("/app")
+	model.store.Append(makeLogEntry(testTimestamp, "hello", model.config))
+	model.followMode = false
+	simulateWindowResize(model, 160, 40)
+
+	// Act - local → utc (zone is skipped without a time zone) → datetime
+	simulateKeyPress(model, "t")
+	utc := model.safeLogs()[0].Raw
+	simulateKeyPress(model, "t")
+
+	// Assert
+	assertStringEqual(t, utc, "[14:30:00Z] hello")
+	assertStringEqual(t, model.config.TimeFormat, timeFormatDateTime)
+	assertStringContains(t, model.View(), "[2025-10-20 14:30:00] hello")
+
+	simulateKeyPress(model, "t")
+	_, cmd := simulateKeyPress(model, "t")
+	assertStringEqual(t, model.config.TimeFormat, timeFormatRelative)
+	if cmd == nil {
+		t.Error("relative timestamps should schedule a refresh")
+	}
+	if !strings.HasSuffix(model.safeLogs()[0].Raw, "d ago] hello") {
+		t.Errorf("relative timestamp: got %q", model.safeLogs()[0].Raw)
+	}
+}
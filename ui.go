@@ -19,7 +19,7 @@ func printStyled(text, color string, bold bool) {
 // selectLogGroup displays log group selection and returns the chosen group
 func selectLogGroup(logGroups []string, uiConfig *UIConfig) (string, error) {
 	// Display log group selection title
-	printStyled("📋 CloudWatch Log Group Selection", "12", true)
+	printStyled("📋 CloudWatch Log Group Selection", uiConfig.Colors.HeaderColor, true)
 	fmt.Println()
 
 	// Let user select a log group
@@ -34,17 +34,25 @@ func selectLogGroup(logGroups []string, uiConfig *UIConfig) (string, error) {
 }
 
 // displayWelcome shows the application welcome message
-func displayWelcome() {
-	printStyled("☁️  AWS CloudWatch Logs Viewer", "10", true)
+func displayWelcome(uiConfig *UIConfig) {
+	printStyled("☁️  AWS CloudWatch Logs Viewer", uiConfig.Colors.InfoColor, true)
 }
 
 // displayConnectionSuccess shows successful connection message and controls
-func displayConnectionSuccess(profile, logGroup string) {
-	printStyled(fmt.Sprintf("✅ Connected to: %s → %s", profile, logGroup), "10", false)
+func displayConnectionSuccess(profile, logGroup string, uiConfig *UIConfig) {
+	keys := uiConfig.Keys
+	all := func(action string) string { return keys.allKeys(keyContextViewer, action) }
+
+	printStyled(fmt.Sprintf("✅ Connected to: %s → %s", profile, logGroup), uiConfig.Colors.InfoColor, false)
 	fmt.Println()
-	printStyled("Controls: ↑↓/j/k=scroll, PgUp/PgDn=fast scroll, g=top, G/End=latest\n"+
-		"          /=search, Esc=clear search, n/N=next/prev match\n"+
-		"          J=format toggle, F=follow toggle, H=load history, b=back, q=quit\n\n"+
+	printStyled(fmt.Sprintf("Controls: %s %s=scroll, %s %s=fast scroll, %s=top, %s=latest\n"+
+		"          %s=search, %s=clear search, %s/%s=next/prev match\n"+
+		"          %s=format toggle, %s=follow toggle, %s=theme, %s=load history, %s=back, %s=quit\n"+
+		"          %s=all key bindings\n\n"+
 		"💡 Auto-follow turns OFF when you scroll up, ON when you reach bottom\n"+
-		"💡 Use mouse to select text for copy/paste (Ctrl+C in most terminals)", "15", false)
+		"💡 Use mouse to select text for copy/paste (Ctrl+C in most terminals)",
+		all(actionUp), all(actionDown), all(actionPageUp), all(actionPageDown), all(actionTop), all(actionBottom),
+		all(actionSearch), all(actionClearSearch), all(actionNextMatch), all(actionPrevMatch),
+		all(actionFormat), all(actionFollow), all(actionTheme), all(actionHistory), all(actionBack), all(actionQuit),
+		all(actionHelp)), uiConfig.Colors.FieldColor, false)
 }
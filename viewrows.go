@@ -0,0 +1,262 @@
+package main
+
+import "strings"
+
+/*
+Visible Rows
+
+The viewer shows a subset of the store: entries hidden by the level threshold
+or the REJECT filter are skipped, invocation grouping reorders Lambda lines and
+collapsed stack traces hide their continuation events. The cursor is always
+a store index; navigation moves through the visible rows, which are store
+indices in display order.
+
+The layout is cached between frames and keystrokes and rebuilt only when its
+rowCacheKey changes. Entries appended in follow mode extend the rows passing
+the level settings, and the filter keeps its result per entry (see
+filter.go), so a rebuild after an append matches no old entry again.
+*/
+
+// rowLayout is the display order of the store and the decorations of its rows
+type rowLayout struct {
+	rows    []int               // Store indices in display order
+	headers map[int]*invocation // Invocation header shown above a row (grouping mode)
+	grouped map[int]bool        // Rows that belong to an invocation group
+	folds   map[int]*traceFold  // Stack traces by head row; collapsed ones hide their members
+	breaks  map[int]bool        // Rows after a gap in the filter's context groups (see filter.go)
+}
+
+// rowCacheKey is everything a row layout depends on
+type rowCacheKey struct {
+	store            *logStore
+	entries          int // store.Len()
+	dropped          int // store.Dropped()
+	edits            int // store.Edits()
+	minLevel         logLevel
+	rejectsOnly      bool
+	filter           *logFilter
+	filterContext    int
+	groupInvocations bool
+	tracesExpanded   bool
+	traceToggles     int
+}
+
+// rowCache is the row layout of the last frame
+type rowCache struct {
+	key     rowCacheKey
+	valid   bool
+	visible []int        // Rows the level and REJECT settings let through, in store order
+	shown   []int        // Visible rows the filter lets through
+	breaks  map[int]bool // See filterRows
+	layout  rowLayout
+}
+
+// rowCacheKey returns the key of the current view settings and store contents
+func (m *logModel) rowCacheKey(logs []logEntry) rowCacheKey {
+	key := rowCacheKey{
+		store:            m.store,
+		entries:          len(logs),
+		minLevel:         m.minLevel,
+		rejectsOnly:      m.rejectsOnly,
+		filter:           m.filter,
+		filterContext:    m.filterContext,
+		groupInvocations: m.groupInvocations,
+		tracesExpanded:   m.tracesExpanded,
+		traceToggles:     m.traceToggles,
+	}
+	if m.store != nil {
+		key.dropped, key.edits = m.store.Dropped(), m.store.Edits()
+	}
+	return key
+}
+
+// rows returns the cached layout, rebuilding it when the key changed
+func (m *logModel) rows(logs []logEntry) *rowCache {
+	c := &m.rowCache
+	key := m.rowCacheKey(logs)
+	if c.valid && c.key == key {
+		return c
+	}
+	c.visible = m.visibleSince(c, key, logs)
+	c.shown, c.breaks = m.filterRows(logs, c.visible)
+	c.layout = m.arrangeRows(logs, c.shown, c.breaks)
+	c.key, c.valid = key, true
+	return c
+}
+
+// visibleSince updates the visible rows of c for key. When only entries were
+// appended, the rows of overwritten entries are dropped and the new entries
+// checked; otherwise every entry is.
+func (m *logModel) visibleSince(c *rowCache, key rowCacheKey, logs []logEntry) []int {
+	old := c.key
+	shift := key.dropped - old.dropped
+	first := old.entries - shift // Index of the first new entry
+	if !c.valid || old.store != key.store || old.edits != key.edits || old.minLevel != key.minLevel ||
+		old.rejectsOnly != key.rejectsOnly || shift < 0 || first < 0 || first > len(logs) {
+		first = 0
+		c.visible = nil
+	}
+
+	rows := c.visible
+	if shift > 0 {
+		rows = make([]int, 0, len(c.visible)+len(logs)-first)
+		for _, index := range c.visible {
+			if index >= shift {
+				rows = append(rows, index-shift)
+			}
+		}
+	}
+	if rows == nil {
+		rows = make([]int, 0, len(logs))
+	}
+	for i := first; i < len(logs); i++ {
+		if m.rowVisible(&logs[i]) {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// rowVisible reports whether a store entry is shown under the current view settings
+func (m *logModel) rowVisible(entry *logEntry) bool {
+	if m.minLevel != levelNone && entry.Level != levelNone && entry.Level < m.minLevel {
+		return false
+	}
+	if m.rejectsOnly && !flowLogRejected(entry) {
+		return false
+	}
+	return true
+}
+
+// hiddenReason describes why rows are hidden, e.g. "below WARN"
+func (m *logModel) hiddenReason() string {
+	var reasons []string
+	if m.minLevel != levelNone {
+		reasons = append(reasons, "below "+m.minLevel.String())
+	}
+	if m.rejectsOnly {
+		reasons = append(reasons, "not REJECT")
+	}
+	if m.filter != nil {
+		reasons = append(reasons, "filtered out")
+	}
+	return strings.Join(reasons, " or ")
+}
+
+// shownRows returns the rows the view settings and the filter let through, in
+// store order, and the rows that start a filter context group after a gap.
+// The slice is shared with the cache and must not be modified.
+func (m *logModel) shownRows(logs []logEntry) ([]int, map[int]bool) {
+	c := m.rows(logs)
+	return c.shown, c.breaks
+}
+
+// layoutRows returns the visible rows in display order
+func (m *logModel) layoutRows(logs []logEntry) rowLayout {
+	return m.rows(logs).layout
+}
+
+// arrangeRows groups invocations and folds stack traces among the shown rows
+func (m *logModel) arrangeRows(logs []logEntry, rows []int, breaks map[int]bool) rowLayout {
+	layout := rowLayout{rows: rows, breaks: breaks}
+	if m.groupInvocations {
+		layout.rows, layout.headers, layout.grouped = groupInvocations(logs, rows)
+	}
+
+	// Collapsed stack traces show only their head row
+	layout.folds = foldTraces(logs, layout.rows)
+	if len(layout.folds) > 0 {
+		shown := layout.rows[:0:0]
+		for pos := 0; pos < len(layout.rows); pos++ {
+			index := layout.rows[pos]
+			shown = append(shown, index)
+			if fold := layout.folds[index]; fold != nil && !m.traceExpanded(index) {
+				pos += len(fold.members)
+			}
+		}
+		layout.rows = shown
+	}
+	return layout
+}
+
+// visibleRows returns the store indices shown in the viewport, in display order
+func (m *logModel) visibleRows(logs []logEntry) []int {
+	return m.layoutRows(logs).rows
+}
+
+// rowPosition returns the position of the cursor in rows. A hidden cursor maps
+// to the row of the next entry after it, or of the latest entry. Rows need not
+// be in store order: grouping moves lines next to their invocation.
+func (m *logModel) rowPosition(rows []int) int {
+	next, latest := -1, -1
+	for pos, index := range rows {
+		if index == m.cursor {
+			return pos
+		}
+		if index > m.cursor && (next < 0 || index < rows[next]) {
+			next = pos
+		}
+		if latest < 0 || index > rows[latest] {
+			latest = pos
+		}
+	}
+	if next >= 0 {
+		return next
+	}
+	return latest
+}
+
+// decorations counts the lines drawn above a row: the filter's "--" separator
+// and the invocation header
+func (l *rowLayout) decorations(index int) int {
+	lines := 0
+	if l.breaks[index] {
+		lines++
+	}
+	if l.headers[index] != nil {
+		lines++
+	}
+	return lines
+}
+
+// fitWindow shrinks the rows start..end around the cursor row until they and
+// their decorations fit in height lines
+func (l *rowLayout) fitWindow(start, end, cursorRow, height int) (int, int) {
+	used := 0
+	for pos := start; pos < end; pos++ {
+		used += 1 + l.decorations(l.rows[pos])
+	}
+	for used > height && end-start > 1 {
+		// Trim the side farther from the cursor so it stays near the middle
+		if end-1 > cursorRow && (end-1-cursorRow >= cursorRow-start || start == cursorRow) {
+			end--
+			used -= 1 + l.decorations(l.rows[end])
+		} else {
+			used -= 1 + l.decorations(l.rows[start])
+			start++
+		}
+	}
+	return start, end
+}
+
+// moveCursor moves the cursor by delta visible rows
+func (m *logModel) moveCursor(delta int) {
+	rows := m.visibleRows(m.safeLogs())
+	if len(rows) == 0 {
+		return
+	}
+	pos := m.rowPosition(rows) + delta
+	pos = max(0, min(len(rows)-1, pos))
+	m.cursor = rows[pos]
+}
+
+// rowWindow returns the rows within radius rows of the cursor
+func (m *logModel) rowWindow(rows []int, radius int) []int {
+	if len(rows) == 0 {
+		return nil
+	}
+	pos := m.rowPosition(rows)
+	start := max(0, pos-radius)
+	end := min(len(rows), pos+radius)
+	return rows[start:end]
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRowCache(t *testing.T) {
+	// Arrange - a wrapping buffer with levels and a filter with context
+	model := createTestLogModel("/app")
+	model.store = newLogStore(20)
+	appendLines := func(from, to int) {
+		for i := from; i < to; i++ {
+			level := "info"
+			if i%4 == 0 {
+				level = "error"
+			}
+			message := fmt.Sprintf(`{"level":"%s","msg":"request %d"}`, level, i)
+			model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+		}
+	}
+	appendLines(0, 15)
+	model.minLevel = levelInfo
+	model.filter, _ = parseFilter("request 1", searchOptions{})
+	model.filterContext = 1
+
+	// fresh lays the rows out without the cache
+	fresh := func() []int {
+		saved := model.rowCache
+		model.rowCache = rowCache{}
+		rows := append([]int(nil), model.visibleRows(model.safeLogs())...)
+		model.rowCache = saved
+		return rows
+	}
+	assertRows := func(got, want []int, msg string) {
+		t.Helper()
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: got %v, want %v", msg, got, want)
+		}
+	}
+
+	// Act - the same frame twice
+	first := model.visibleRows(model.safeLogs())
+	second := model.visibleRows(model.safeLogs())
+
+	// Assert - the layout is reused
+	if &first[0] != &second[0] {
+		t.Error("an unchanged view should reuse the cached rows")
+	}
+
+	// Act & Assert - appends, then appends that overwrite old entries
+	appendLines(15, 18)
+	assertRows(model.visibleRows(model.safeLogs()), fresh(), "rows after append")
+	appendLines(18, 31)
+	assertRows(model.visibleRows(model.safeLogs()), fresh(), "rows after wrap")
+	assertIntEqual(t, model.store.Dropped(), 11, "overwritten entries")
+
+	// Act & Assert - settings changes rebuild the layout
+	model.minLevel = levelError
+	assertRows(model.visibleRows(model.safeLogs()), fresh(), "rows at ERROR")
+	model.filter = nil
+	assertIntEqual(t, len(model.visibleRows(model.safeLogs())), 5, "errors without filter")
+}
+
+func BenchmarkLayoutAppend(b *testing.B) {
+	model := createTestLogModel("/app")
+	model.store = newLogStore(100000)
+	for i := 0; i < 100000; i++ {
+		message := fmt.Sprintf(`{"level":"info","request":%d,"path":"/api/items/%d","status":200}`, i, i%97)
+		model.store.Append(makeLogEntry(testTimestamp, message, model.config))
+	}
+	model.filter, _ = parseFilter("items/42", searchOptions{})
+	model.minLevel = levelInfo
+	entry := makeLogEntry(testTimestamp, `{"level":"warn","path":"/api/items/42"}`, model.config)
+	model.visibleRows(model.safeLogs())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		model.store.Append(entry) // Wraps: every row index shifts
+		model.visibleRows(model.safeLogs())
+	}
+}